@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/stones-hub/taurus-pro-http/pkg/auth"
 	"github.com/stones-hub/taurus-pro-http/pkg/httpx"
 	"github.com/stones-hub/taurus-pro-http/pkg/middleware"
 	"github.com/stones-hub/taurus-pro-http/pkg/router"
@@ -48,10 +49,18 @@ func (h *UserHandler) GetUsers(w http.ResponseWriter, r *http.Request) {
 
 // GetUser 获取单个用户
 func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
-	// 从请求中获取用户ID（实际应该从URL参数获取）
-	userID := uint(1)
+	id, err := httpx.GetPathParam(r, "id")
+	if err != nil {
+		httpx.SendResponse(w, httpx.StatusInvalidParams, nil, nil)
+		return
+	}
+	userID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		httpx.SendResponse(w, httpx.StatusInvalidParams, nil, nil)
+		return
+	}
 
-	user, ok := h.users[userID]
+	user, ok := h.users[uint(userID)]
 	if !ok {
 		httpx.SendResponse(w, httpx.StatusInvalidRequest, nil, nil)
 		return
@@ -67,6 +76,7 @@ func main() {
 		server.WithReadTimeout(15*time.Second),
 		server.WithWriteTimeout(15*time.Second),
 		server.WithIdleTimeout(30*time.Second),
+		server.WithShutdownTimeout(10*time.Second),
 	)
 
 	// 创建处理器
@@ -75,20 +85,40 @@ func main() {
 	// 创建中间件
 	corsMiddleware := middleware.CorsMiddleware(nil) // 使用默认配置
 
+	// 认证：演示用 API Key，每个用户一个 key，Principal 携带用户角色
+	keys := make(map[string]*auth.Principal, len(userHandler.users))
+	for id, user := range userHandler.users {
+		keys["demo-key-"+user.Username] = &auth.Principal{ID: strconv.FormatUint(uint64(id), 10), Roles: []string{user.Role}}
+	}
+	apiKeys, err := auth.NewAPIKeyAuthenticator(auth.APIKeyConfig{Keys: keys})
+	if err != nil {
+		log.Fatalf("Failed to create api key authenticator: %v", err)
+	}
+
+	// 授权：admin 继承 user 的全部权限，普通用户只能查看自己的信息
+	authorizer := auth.NewAuthorizer()
+	authorizer.InheritRoles("admin", "user")
+	authorizer.RegisterPolicy("self", auth.OwnerPolicy("id", "admin"))
+	srv.SetAccessEnforcer(authorizer.Enforce)
+
 	// 受保护的路由组
 	srv.AddRouterGroup(router.RouteGroup{
 		Prefix: "/api/v1",
 		Middleware: []router.MiddlewareFunc{
 			corsMiddleware,
+			auth.Authenticate(apiKeys),
 		},
+		Access: &router.AccessRule{Roles: []string{"user"}},
 		Routes: []router.Router{
 			{
 				Path:    "/users",
 				Handler: http.HandlerFunc(userHandler.GetUsers),
+				Access:  &router.AccessRule{Roles: []string{"admin"}},
 			},
 			{
-				Path:    "/users/" + strconv.Itoa(1),
+				Path:    "/users/{id}",
 				Handler: http.HandlerFunc(userHandler.GetUser),
+				Access:  &router.AccessRule{Policies: []string{"self"}},
 			},
 		},
 	})
@@ -100,15 +130,13 @@ func main() {
 		}),
 	})
 
-	// 启动服务器
-	errChan := make(chan error, 1)
-	srv.Start(errChan)
+	srv.OnReady(func(ctx context.Context) error {
+		log.Printf("Server is ready on %s \n", srv.GetConfig().Addr)
+		return nil
+	})
 
-	// wait for server start failed or timeout
-	if err := <-errChan; err != nil {
-		log.Printf("Server start failed (%s) on %s \n", err.Error(), srv.GetConfig().Addr)
+	// 阻塞运行，收到 SIGINT / SIGTERM 后等待处理中的请求完成再退出
+	if err := srv.Run(context.Background()); err != nil {
+		log.Fatalf("Server exited with error: %v", err)
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-	defer cancel()
-	srv.Shutdown(ctx)
 }
@@ -0,0 +1,250 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+// Package admin 提供独立监听的管理和调试服务: pprof、expvar、路由表、运行时信息和日志级别切换
+//
+// 注意: 导入该包会同时导入 net/http/pprof 和 expvar，它们会在 http.DefaultServeMux 上注册 /debug/* 路由，
+// 不要使用 DefaultServeMux 对外提供服务
+package admin
+
+import (
+	"expvar"
+	"fmt"
+	"log"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"runtime/metrics"
+	"strings"
+	"time"
+
+	"github.com/stones-hub/taurus-pro-http/pkg/httpx"
+	"github.com/stones-hub/taurus-pro-http/pkg/router"
+	"github.com/stones-hub/taurus-pro-http/pkg/server"
+)
+
+// DefaultAddr 默认监听地址，只允许本机访问
+const DefaultAddr = "127.0.0.1:6060"
+
+// Config 管理服务配置
+type Config struct {
+	// Addr 监听地址，默认 "127.0.0.1:6060"
+	// 监听非本机地址（如 ":6060"）时必须设置 Auth，否则 New 返回错误
+	Addr string
+	// Auth 认证中间件，作用于所有管理接口，如 auth.Authenticate(auth.NewBasicAuthenticator(...))
+	Auth router.MiddlewareFunc
+	// Server 被管理的服务，用于展示路由表和服务启动时间，可以为 nil
+	Server *server.Server
+	// LogLevel 可以在运行时切换的日志级别，为 nil 时不注册 /debug/loglevel
+	// 示例: slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: levelVar}))
+	LogLevel *slog.LevelVar
+	// WriteTimeout 写入超时，默认 2 分钟；需要大于 CPU profile 和 trace 的采集时间（默认 30 秒）
+	WriteTimeout time.Duration
+}
+
+// New 创建管理服务，返回的服务与业务服务一样可以交给 server.Lifecycle 管理
+//
+// 接口列表（均在 /debug 下）:
+//   - /debug/pprof/: pprof 性能分析，如 go tool pprof http://127.0.0.1:6060/debug/pprof/profile
+//   - /debug/vars: expvar 变量
+//   - /debug/routes: 被管理服务的路由表
+//   - /debug/runtime: goroutine 数量、GOMAXPROCS、GOGC、内存和 GC 信息
+//   - /debug/loglevel: GET 查询日志级别，PUT/POST ?level=debug 切换日志级别
+func New(config Config) (*server.Server, error) {
+	if config.Addr == "" {
+		config.Addr = DefaultAddr
+	}
+	if config.WriteTimeout <= 0 {
+		config.WriteTimeout = 2 * time.Minute
+	}
+	if config.Auth == nil && !isLoopback(config.Addr) {
+		return nil, fmt.Errorf("admin server on %s is not bound to localhost, Auth is required", config.Addr)
+	}
+
+	srv := server.New(server.Config{
+		Addr:         config.Addr,
+		WriteTimeout: config.WriteTimeout,
+	})
+	var middleware []router.MiddlewareFunc
+	if config.Auth != nil {
+		middleware = append(middleware, config.Auth)
+	}
+	routes := []router.Router{
+		// pprof.Index 根据 /debug/pprof/ 之后的名称返回对应的 profile（heap、goroutine、block 等）
+		{Path: "/pprof/", Handler: http.HandlerFunc(pprof.Index)},
+		{Path: "/pprof/cmdline", Handler: http.HandlerFunc(pprof.Cmdline)},
+		{Path: "/pprof/profile", Handler: http.HandlerFunc(pprof.Profile)},
+		{Path: "/pprof/symbol", Handler: http.HandlerFunc(pprof.Symbol)},
+		{Path: "/pprof/trace", Handler: http.HandlerFunc(pprof.Trace)},
+		{Path: "/vars", Handler: expvar.Handler()},
+		{Path: "/routes", Handler: routesHandler(config.Server)},
+		{Path: "/runtime", Handler: runtimeHandler(config.Server)},
+	}
+	if config.LogLevel != nil {
+		routes = append(routes, router.Router{Path: "/loglevel", Handler: logLevelHandler(config.LogLevel)})
+	}
+	srv.AddRouterGroup(router.RouteGroup{
+		Prefix:     "/debug",
+		Middleware: middleware,
+		Routes:     routes,
+	})
+	return srv, nil
+}
+
+// isLoopback 监听地址是否只允许本机访问
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// routesHandler 返回被管理服务的路由表
+func routesHandler(srv *server.Server) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		routes := []router.RouteInfo{}
+		if srv != nil {
+			routes = srv.Routes()
+		}
+		httpx.SendResponse(w, http.StatusOK, routes, nil)
+	})
+}
+
+// RuntimeInfo 运行时信息
+type RuntimeInfo struct {
+	GoVersion   string  `json:"go_version"`
+	Goroutines  int     `json:"goroutines"`
+	GOMAXPROCS  int     `json:"gomaxprocs"`
+	NumCPU      int     `json:"num_cpu"`
+	GOGC        int64   `json:"gogc"`         // GC 触发百分比，-1 表示关闭
+	MemoryLimit int64   `json:"memory_limit"` // GOMEMLIMIT，未设置时为 math.MaxInt64
+	StartTime   string  `json:"start_time,omitempty"`
+	Uptime      string  `json:"uptime,omitempty"`
+	Memory      Memory  `json:"memory"`
+	GC          GCStats `json:"gc"`
+}
+
+// Memory 内存信息（字节）
+type Memory struct {
+	HeapAlloc   uint64 `json:"heap_alloc"`
+	HeapInuse   uint64 `json:"heap_inuse"`
+	HeapObjects uint64 `json:"heap_objects"`
+	StackInuse  uint64 `json:"stack_inuse"`
+	Sys         uint64 `json:"sys"`
+}
+
+// GCStats GC 信息
+type GCStats struct {
+	NumGC      uint32  `json:"num_gc"`
+	NextGC     uint64  `json:"next_gc"` // 下次 GC 的堆大小目标
+	PauseTotal string  `json:"pause_total"`
+	LastPause  string  `json:"last_pause"`
+	LastGC     string  `json:"last_gc,omitempty"`
+	CPUPercent float64 `json:"cpu_percent"` // 启动以来 GC 占用的 CPU 百分比
+}
+
+// runtimeHandler 返回运行时信息
+func runtimeHandler(srv *server.Server) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		httpx.SendResponse(w, http.StatusOK, readRuntimeInfo(srv), nil)
+	})
+}
+
+// readRuntimeInfo 读取运行时信息，ReadMemStats 会短暂暂停所有 goroutine
+func readRuntimeInfo(srv *server.Server) RuntimeInfo {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+
+	// GOGC 和 GOMEMLIMIT 只能通过 runtime/metrics 读取，debug.SetGCPercent 会修改设置
+	samples := []metrics.Sample{{Name: "/gc/gogc:percent"}, {Name: "/gc/gomemlimit:bytes"}}
+	metrics.Read(samples)
+
+	info := RuntimeInfo{
+		GoVersion:   runtime.Version(),
+		Goroutines:  runtime.NumGoroutine(),
+		GOMAXPROCS:  runtime.GOMAXPROCS(0),
+		NumCPU:      runtime.NumCPU(),
+		GOGC:        sampleInt(samples[0]),
+		MemoryLimit: sampleInt(samples[1]),
+		Memory: Memory{
+			HeapAlloc:   stats.HeapAlloc,
+			HeapInuse:   stats.HeapInuse,
+			HeapObjects: stats.HeapObjects,
+			StackInuse:  stats.StackInuse,
+			Sys:         stats.Sys,
+		},
+		GC: GCStats{
+			NumGC:      stats.NumGC,
+			NextGC:     stats.NextGC,
+			PauseTotal: time.Duration(stats.PauseTotalNs).String(),
+			LastPause:  time.Duration(stats.PauseNs[(stats.NumGC+255)%256]).String(),
+			CPUPercent: stats.GCCPUFraction * 100,
+		},
+	}
+	if stats.LastGC > 0 {
+		info.GC.LastGC = time.Unix(0, int64(stats.LastGC)).Format(time.RFC3339)
+	}
+	if srv != nil && !srv.StartTime().IsZero() {
+		info.StartTime = srv.StartTime().Format(time.RFC3339)
+		info.Uptime = time.Since(srv.StartTime()).Round(time.Second).String()
+	}
+	return info
+}
+
+// sampleInt 读取整数类型的指标，指标不存在时返回 0
+func sampleInt(sample metrics.Sample) int64 {
+	if sample.Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return int64(sample.Value.Uint64())
+}
+
+// logLevelHandler 查询和切换日志级别
+func logLevelHandler(level *slog.LevelVar) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+		case http.MethodPut, http.MethodPost:
+			value := r.FormValue("level")
+			if value == "" {
+				httpx.SendResponseWithStatus(w, http.StatusBadRequest, "level is required, e.g. ?level=debug", nil)
+				return
+			}
+			var newLevel slog.Level
+			if err := newLevel.UnmarshalText([]byte(strings.ToUpper(value))); err != nil {
+				httpx.SendResponseWithStatus(w, http.StatusBadRequest, err.Error(), nil)
+				return
+			}
+			old := level.Level()
+			level.Set(newLevel)
+			log.Printf("[Admin] log level changed from %s to %s by %s \n", old, newLevel, httpx.ClientIP(r))
+		default:
+			w.Header().Set("Allow", "GET, PUT, POST")
+			httpx.SendResponseWithStatus(w, http.StatusMethodNotAllowed, nil, nil)
+			return
+		}
+		httpx.SendResponse(w, http.StatusOK, map[string]string{"level": level.Level().String()}, nil)
+	})
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+)
+
+// APIKeyConfig API Key 认证配置
+type APIKeyConfig struct {
+	// Header 读取 API Key 的请求头，默认 X-Api-Key
+	Header string
+	// QueryParam 读取 API Key 的查询参数，为空时不从查询参数读取
+	// 查询参数容易出现在访问日志和浏览器历史中，建议仅在无法设置请求头时使用
+	QueryParam string
+	// Keys 固定的 API Key 到 Principal 的映射
+	Keys map[string]*Principal
+	// Lookup 自定义查找，如从数据库查询，优先于 Keys；key 无效时返回 nil 或 ErrInvalidCredentials
+	Lookup func(ctx context.Context, key string) (*Principal, error)
+}
+
+// APIKeyAuthenticator API Key 认证器
+type APIKeyAuthenticator struct {
+	config APIKeyConfig
+	keys   map[[sha256.Size]byte]*Principal
+}
+
+// NewAPIKeyAuthenticator 创建 API Key 认证器
+func NewAPIKeyAuthenticator(config APIKeyConfig) (*APIKeyAuthenticator, error) {
+	if config.Lookup == nil && len(config.Keys) == 0 {
+		return nil, fmt.Errorf("api key authenticator requires Keys or Lookup")
+	}
+	if config.Header == "" {
+		config.Header = "X-Api-Key"
+	}
+	// 使用摘要作为 map key，避免按原始 key 查找带来的时序差异
+	keys := make(map[[sha256.Size]byte]*Principal, len(config.Keys))
+	for key, principal := range config.Keys {
+		keys[sha256.Sum256([]byte(key))] = principal
+	}
+	return &APIKeyAuthenticator{config: config, keys: keys}, nil
+}
+
+// Authenticate 实现 Authenticator
+func (a *APIKeyAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	key := r.Header.Get(a.config.Header)
+	if key == "" && a.config.QueryParam != "" {
+		key = r.URL.Query().Get(a.config.QueryParam)
+	}
+	if key == "" {
+		return nil, ErrNoCredentials
+	}
+
+	var principal *Principal
+	if a.config.Lookup != nil {
+		var err error
+		if principal, err = a.config.Lookup(r.Context(), key); err != nil {
+			return nil, err
+		}
+	} else {
+		principal = a.keys[sha256.Sum256([]byte(key))]
+	}
+	if principal == nil {
+		return nil, ErrInvalidCredentials
+	}
+	return withScheme(principal, SchemeAPIKey), nil
+}
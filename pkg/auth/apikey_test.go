@@ -0,0 +1,108 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stones-hub/taurus-pro-http/pkg/auth"
+)
+
+func TestAPIKeyAuthenticator(t *testing.T) {
+	a, err := auth.NewAPIKeyAuthenticator(auth.APIKeyConfig{
+		QueryParam: "api_key",
+		Keys:       map[string]*auth.Principal{"k1": {ID: "service-a"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		header  string
+		target  string
+		wantID  string
+		wantErr error
+	}{
+		{"header", "k1", "/", "service-a", nil},
+		{"query parameter", "", "/?api_key=k1", "service-a", nil},
+		{"unknown key", "k2", "/", "", auth.ErrInvalidCredentials},
+		{"no key", "", "/", "", auth.ErrNoCredentials},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.header != "" {
+				r.Header.Set("X-Api-Key", tt.header)
+			}
+			principal, err := a.Authenticate(r)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (principal.ID != tt.wantID || principal.Scheme != auth.SchemeAPIKey) {
+				t.Errorf("principal = %+v, want %s via api_key", principal, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestAPIKeyAuthenticatorLookup(t *testing.T) {
+	lookupErr := errors.New("database unavailable")
+	a, err := auth.NewAPIKeyAuthenticator(auth.APIKeyConfig{
+		Header: "Authorization-Key",
+		Keys:   map[string]*auth.Principal{"static": {ID: "ignored"}},
+		Lookup: func(ctx context.Context, key string) (*auth.Principal, error) {
+			switch key {
+			case "db-key":
+				return &auth.Principal{ID: "from-db"}, nil
+			case "broken":
+				return nil, lookupErr
+			}
+			return nil, nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for key, want := range map[string]struct {
+		id  string
+		err error
+	}{
+		"db-key": {id: "from-db"},
+		// Lookup 优先于 Keys
+		"static": {err: auth.ErrInvalidCredentials},
+		"broken": {err: lookupErr},
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Authorization-Key", key)
+		principal, err := a.Authenticate(r)
+		if !errors.Is(err, want.err) {
+			t.Errorf("key %s: err = %v, want %v", key, err, want.err)
+			continue
+		}
+		if want.err == nil && principal.ID != want.id {
+			t.Errorf("key %s: principal = %q, want %q", key, principal.ID, want.id)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+// Package auth 提供 HTTP 认证中间件
+// JWT 验证只依赖标准库，支持 HS256/RS256/ES256/EdDSA 和 JWKS 密钥集
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/stones-hub/taurus-pro-http/pkg/httpx"
+)
+
+var (
+	ErrMissingToken         = errors.New("missing token")
+	ErrMalformedToken       = errors.New("malformed token")
+	ErrUnsupportedAlgorithm = errors.New("unsupported signing algorithm")
+	ErrKeyNotFound          = errors.New("signing key not found")
+	ErrInvalidSignature     = errors.New("invalid signature")
+	ErrTokenExpired         = errors.New("token is expired")
+	ErrTokenNotYetValid     = errors.New("token is not valid yet")
+	ErrInvalidIssuer        = errors.New("invalid token issuer")
+	ErrInvalidAudience      = errors.New("invalid token audience")
+	ErrInvalidCredentials   = errors.New("invalid credentials")
+)
+
+// contextKey 请求上下文中使用的键类型，避免与其他包冲突
+type contextKey int
+
+const (
+	claimsKey contextKey = iota
+	principalKey
+)
+
+// WithClaims 将 JWT claims 写入上下文
+func WithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsKey, claims)
+}
+
+// ClaimsFromContext 从上下文获取 JWT claims
+func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsKey).(*Claims)
+	return claims, ok && claims != nil
+}
+
+// GetClaims 获取请求的 JWT claims，未通过 JWT 认证时返回 nil
+func GetClaims(r *http.Request) *Claims {
+	claims, _ := ClaimsFromContext(r.Context())
+	return claims
+}
+
+// sendUnauthorized 返回 401，challenge 为 WWW-Authenticate 头的值
+func sendUnauthorized(w http.ResponseWriter, challenge string, err error) {
+	headers := map[string]string{}
+	if challenge != "" {
+		headers["WWW-Authenticate"] = challenge
+	}
+	httpx.SendResponseWithStatus(w, http.StatusUnauthorized, err.Error(), headers)
+}
@@ -0,0 +1,171 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package auth
+
+import (
+	"log"
+	"net/http"
+	"slices"
+	"sync"
+
+	"github.com/stones-hub/taurus-pro-http/pkg/httpx"
+	"github.com/stones-hub/taurus-pro-http/pkg/router"
+)
+
+// Policy 命名策略，用于资源级检查，可以通过 r.PathValue 读取路径参数
+type Policy func(r *http.Request, principal *Principal) bool
+
+// Authorizer 授权策略引擎
+// 根据 router.AccessRule 检查已认证的 Principal：
+//   - Roles: 拥有其中任意一个角色
+//   - Permissions: 拥有全部权限
+//   - Scopes: 拥有全部 scope
+//   - Policies: 通过全部命名策略
+//
+// 未认证返回 401，拒绝访问返回 403
+type Authorizer struct {
+	mu       sync.RWMutex
+	policies map[string]Policy
+	inherits map[string][]string
+	onDenied func(r *http.Request, principal *Principal, rule router.AccessRule)
+}
+
+// NewAuthorizer 创建授权策略引擎
+func NewAuthorizer() *Authorizer {
+	return &Authorizer{
+		policies: make(map[string]Policy),
+		inherits: make(map[string][]string),
+	}
+}
+
+// RegisterPolicy 注册命名策略
+func (a *Authorizer) RegisterPolicy(name string, policy Policy) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.policies[name] = policy
+}
+
+// InheritRoles 设置角色继承，如 InheritRoles("admin", "editor", "user") 表示 admin 同时拥有 editor 和 user 角色
+func (a *Authorizer) InheritRoles(role string, inherited ...string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.inherits[role] = append(a.inherits[role], inherited...)
+}
+
+// OnDenied 设置拒绝访问回调，可用于审计日志
+func (a *Authorizer) OnDenied(fn func(r *http.Request, principal *Principal, rule router.AccessRule)) {
+	a.onDenied = fn
+}
+
+// Enforce 根据规则生成授权中间件，签名与 router.AccessEnforcer 一致
+// 示例: srv.SetAccessEnforcer(authorizer.Enforce)
+func (a *Authorizer) Enforce(rule router.AccessRule) router.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal := GetPrincipal(r)
+			if principal == nil {
+				sendUnauthorized(w, "", ErrNoCredentials)
+				return
+			}
+			if !a.Allowed(r, principal, rule) {
+				log.Printf("[Authz] access denied, principal: %s, path: %s", principal.ID, r.URL.Path)
+				if a.onDenied != nil {
+					a.onDenied(r, principal, rule)
+				}
+				httpx.SendResponseWithStatus(w, http.StatusForbidden, "access denied", nil)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Allowed 检查 principal 是否满足规则，未注册的命名策略视为拒绝
+func (a *Authorizer) Allowed(r *http.Request, principal *Principal, rule router.AccessRule) bool {
+	if principal == nil {
+		return false
+	}
+	if len(rule.Roles) > 0 && !slices.ContainsFunc(rule.Roles, func(role string) bool {
+		return a.hasRole(principal, role)
+	}) {
+		return false
+	}
+	for _, permission := range rule.Permissions {
+		if !principal.HasPermission(permission) {
+			return false
+		}
+	}
+	for _, scope := range rule.Scopes {
+		if !principal.HasScope(scope) {
+			return false
+		}
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	for _, name := range rule.Policies {
+		policy, ok := a.policies[name]
+		if !ok {
+			log.Printf("[Authz] policy %q is not registered", name)
+			return false
+		}
+		if !policy(r, principal) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasRole 检查角色，包括继承的角色
+func (a *Authorizer) hasRole(principal *Principal, role string) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	visited := make(map[string]bool)
+	var walk func(roles []string) bool
+	walk = func(roles []string) bool {
+		for _, r := range roles {
+			if r == role {
+				return true
+			}
+			if visited[r] {
+				continue
+			}
+			visited[r] = true
+			if walk(a.inherits[r]) {
+				return true
+			}
+		}
+		return false
+	}
+	return walk(principal.Roles)
+}
+
+// OwnerPolicy 资源属主策略：路径参数 param 等于 Principal.ID 时允许访问，拥有 bypassRoles 中任意角色时也允许
+// 示例: authorizer.RegisterPolicy("owner", auth.OwnerPolicy("id", "admin")) 配合路由 /users/{id}
+func OwnerPolicy(param string, bypassRoles ...string) Policy {
+	return func(r *http.Request, principal *Principal) bool {
+		for _, role := range bypassRoles {
+			if principal.HasRole(role) {
+				return true
+			}
+		}
+		value := r.PathValue(param)
+		return value != "" && value == principal.ID
+	}
+}
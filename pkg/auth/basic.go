@@ -0,0 +1,86 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+)
+
+// BasicConfig Basic 认证配置
+type BasicConfig struct {
+	// Realm WWW-Authenticate 中的 realm，默认 "Restricted"
+	Realm string
+	// Users 固定的用户名和密码
+	Users map[string]string
+	// Validate 自定义校验，如查询数据库并比较密码哈希，优先于 Users；校验失败时返回 nil 或 ErrInvalidCredentials
+	Validate func(ctx context.Context, username, password string) (*Principal, error)
+}
+
+// BasicAuthenticator Basic 认证器，适合内部工具使用，必须配合 HTTPS
+type BasicAuthenticator struct {
+	config BasicConfig
+}
+
+// NewBasicAuthenticator 创建 Basic 认证器
+func NewBasicAuthenticator(config BasicConfig) (*BasicAuthenticator, error) {
+	if config.Validate == nil && len(config.Users) == 0 {
+		return nil, fmt.Errorf("basic authenticator requires Users or Validate")
+	}
+	if config.Realm == "" {
+		config.Realm = "Restricted"
+	}
+	return &BasicAuthenticator{config: config}, nil
+}
+
+// Authenticate 实现 Authenticator
+func (a *BasicAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+
+	if a.config.Validate != nil {
+		principal, err := a.config.Validate(r.Context(), username, password)
+		if err != nil {
+			return nil, err
+		}
+		if principal == nil {
+			return nil, ErrInvalidCredentials
+		}
+		return withScheme(principal, SchemeBasic), nil
+	}
+
+	expected, found := a.config.Users[username]
+	// 用户不存在时也进行一次比较，避免通过响应时间判断用户是否存在
+	passwordHash := sha256.Sum256([]byte(password))
+	expectedHash := sha256.Sum256([]byte(expected))
+	if subtle.ConstantTimeCompare(passwordHash[:], expectedHash[:]) != 1 || !found {
+		return nil, ErrInvalidCredentials
+	}
+	return &Principal{ID: username, Scheme: SchemeBasic}, nil
+}
+
+// Challenge 实现 Challenger
+func (a *BasicAuthenticator) Challenge() string {
+	return fmt.Sprintf("Basic realm=%q, charset=\"UTF-8\"", a.config.Realm)
+}
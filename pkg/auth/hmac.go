@@ -0,0 +1,334 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// HMACAlgorithm Authorization 头中的签名算法标识
+	HMACAlgorithm = "HMAC-SHA256"
+	// HeaderTimestamp 签名时间戳（Unix 秒）请求头
+	HeaderTimestamp = "X-Timestamp"
+	// HeaderNonce 签名随机数请求头
+	HeaderNonce = "X-Nonce"
+)
+
+var (
+	ErrSignatureExpired = errors.New("request timestamp is outside the allowed window")
+	ErrReplayedRequest  = errors.New("request nonce has already been used")
+)
+
+// HMACConfig HMAC 请求签名认证配置
+//
+// 请求格式:
+//
+//	Authorization: HMAC-SHA256 KeyId=<key id>, SignedHeaders=content-type;host, Signature=<hex>
+//	X-Timestamp: <unix 秒>
+//	X-Nonce: <随机字符串>
+//
+// 签名: hex(HMAC-SHA256(secret, 规范请求))，规范请求按行拼接:
+//
+//	METHOD
+//	/escaped/path
+//	排序后的查询参数（a=1&b=2，键和值均做 URL 编码）
+//	签名头（小写名称:去除首尾空格的值，按名称排序，每行一个）
+//	签名头名称列表（content-type;host）
+//	时间戳
+//	nonce
+//	hex(SHA256(请求体))
+//
+// 客户端可以直接使用 SignRequest 生成签名
+type HMACConfig struct {
+	// Secrets 固定的 KeyId 到密钥的映射，Principal.ID 为 KeyId
+	Secrets map[string][]byte
+	// Lookup 自定义查找密钥和 Principal，优先于 Secrets；KeyId 不存在时返回 nil 密钥或 ErrInvalidCredentials
+	Lookup func(ctx context.Context, keyID string) (secret []byte, principal *Principal, err error)
+	// TimestampWindow 允许的时间戳偏差，默认 5 分钟
+	TimestampWindow time.Duration
+	// Nonces nonce 存储，默认内存存储；多实例部署时应使用 RedisNonceStore
+	Nonces NonceStore
+	// RequiredHeaders 必须参与签名的请求头，默认 host
+	RequiredHeaders []string
+	// MaxBodySize 计算签名时读取请求体的最大字节数，默认 10MB
+	MaxBodySize int64
+}
+
+// HMACAuthenticator HMAC 请求签名认证器
+type HMACAuthenticator struct {
+	config HMACConfig
+}
+
+// NewHMACAuthenticator 创建 HMAC 请求签名认证器
+func NewHMACAuthenticator(config HMACConfig) (*HMACAuthenticator, error) {
+	if config.Lookup == nil && len(config.Secrets) == 0 {
+		return nil, fmt.Errorf("hmac authenticator requires Secrets or Lookup")
+	}
+	if config.TimestampWindow <= 0 {
+		config.TimestampWindow = 5 * time.Minute
+	}
+	if config.Nonces == nil {
+		config.Nonces = NewMemoryNonceStore(0)
+	}
+	// 复制一份再转为小写，不修改调用方的切片
+	required := []string{"host"}
+	if len(config.RequiredHeaders) > 0 {
+		required = make([]string, len(config.RequiredHeaders))
+		for i, header := range config.RequiredHeaders {
+			required[i] = strings.ToLower(header)
+		}
+	}
+	config.RequiredHeaders = required
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = 10 << 20
+	}
+	return &HMACAuthenticator{config: config}, nil
+}
+
+// Authenticate 实现 Authenticator
+func (a *HMACAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	authorization := r.Header.Get("Authorization")
+	scheme, params, ok := strings.Cut(authorization, " ")
+	if !ok || !strings.EqualFold(scheme, HMACAlgorithm) {
+		return nil, ErrNoCredentials
+	}
+
+	keyID, signedHeaders, signature, err := parseHMACAuthorization(params)
+	if err != nil {
+		return nil, err
+	}
+	for _, header := range a.config.RequiredHeaders {
+		if !slices.Contains(signedHeaders, header) {
+			return nil, fmt.Errorf("%w: header %q must be signed", ErrInvalidCredentials, header)
+		}
+	}
+
+	timestamp := r.Header.Get(HeaderTimestamp)
+	nonce := r.Header.Get(HeaderNonce)
+	if timestamp == "" || nonce == "" {
+		return nil, fmt.Errorf("%w: missing %s or %s", ErrInvalidCredentials, HeaderTimestamp, HeaderNonce)
+	}
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid %s", ErrInvalidCredentials, HeaderTimestamp)
+	}
+	if skew := time.Since(time.Unix(unix, 0)); skew > a.config.TimestampWindow || skew < -a.config.TimestampWindow {
+		return nil, ErrSignatureExpired
+	}
+
+	secret, principal, err := a.lookup(r.Context(), keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	bodyHash, err := hashBody(r, a.config.MaxBodySize)
+	if err != nil {
+		return nil, err
+	}
+	expected := signCanonicalRequest(secret, canonicalRequest(r, signedHeaders, timestamp, nonce, bodyHash))
+	if !hmac.Equal(signature, expected) {
+		return nil, ErrInvalidSignature
+	}
+
+	// 签名验证通过后再记录 nonce，避免伪造请求占用 nonce
+	// 时间戳允许前后偏差 TimestampWindow，nonce 至少需要保留两倍窗口
+	fresh, err := a.config.Nonces.Remember(r.Context(), keyID+":"+nonce, 2*a.config.TimestampWindow)
+	if err != nil {
+		return nil, err
+	}
+	if !fresh {
+		return nil, ErrReplayedRequest
+	}
+	return withScheme(principal, SchemeHMAC), nil
+}
+
+// Challenge 实现 Challenger
+func (a *HMACAuthenticator) Challenge() string {
+	return HMACAlgorithm
+}
+
+// lookup 查找密钥
+func (a *HMACAuthenticator) lookup(ctx context.Context, keyID string) ([]byte, *Principal, error) {
+	if a.config.Lookup != nil {
+		secret, principal, err := a.config.Lookup(ctx, keyID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(secret) == 0 {
+			return nil, nil, ErrInvalidCredentials
+		}
+		if principal == nil {
+			principal = &Principal{ID: keyID}
+		}
+		return secret, principal, nil
+	}
+	secret, ok := a.config.Secrets[keyID]
+	if !ok {
+		return nil, nil, ErrInvalidCredentials
+	}
+	return secret, &Principal{ID: keyID}, nil
+}
+
+// parseHMACAuthorization 解析 KeyId=..., SignedHeaders=..., Signature=...
+func parseHMACAuthorization(params string) (keyID string, signedHeaders []string, signature []byte, err error) {
+	for _, param := range strings.Split(params, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+		switch name {
+		case "KeyId":
+			keyID = value
+		case "SignedHeaders":
+			for _, header := range strings.Split(value, ";") {
+				if header = strings.ToLower(strings.TrimSpace(header)); header != "" {
+					signedHeaders = append(signedHeaders, header)
+				}
+			}
+		case "Signature":
+			if signature, err = hex.DecodeString(value); err != nil {
+				return "", nil, nil, fmt.Errorf("%w: invalid signature encoding", ErrInvalidCredentials)
+			}
+		}
+	}
+	if keyID == "" || len(signature) == 0 {
+		return "", nil, nil, fmt.Errorf("%w: malformed authorization header", ErrInvalidCredentials)
+	}
+	return keyID, signedHeaders, signature, nil
+}
+
+// hashBody 计算请求体摘要，并恢复请求体供后续处理器读取
+func hashBody(r *http.Request, maxSize int64) (string, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return emptyBodyHash, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxSize+1))
+	r.Body.Close()
+	if err != nil {
+		return "", err
+	}
+	if int64(len(body)) > maxSize {
+		return "", fmt.Errorf("request body exceeds %d bytes", maxSize)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// emptyBodyHash 空请求体的 SHA256
+var emptyBodyHash = func() string {
+	sum := sha256.Sum256(nil)
+	return hex.EncodeToString(sum[:])
+}()
+
+// canonicalRequest 生成规范请求
+func canonicalRequest(r *http.Request, signedHeaders []string, timestamp, nonce, bodyHash string) string {
+	headers := slices.Clone(signedHeaders)
+	sort.Strings(headers)
+
+	var b strings.Builder
+	b.WriteString(r.Method)
+	b.WriteByte('\n')
+	b.WriteString(r.URL.EscapedPath())
+	b.WriteByte('\n')
+	b.WriteString(canonicalQuery(r.URL.Query()))
+	b.WriteByte('\n')
+	for _, header := range headers {
+		value := r.Header.Get(header)
+		if header == "host" {
+			value = r.Host
+		}
+		b.WriteString(header)
+		b.WriteByte(':')
+		b.WriteString(strings.TrimSpace(value))
+		b.WriteByte('\n')
+	}
+	b.WriteString(strings.Join(headers, ";"))
+	b.WriteByte('\n')
+	b.WriteString(timestamp)
+	b.WriteByte('\n')
+	b.WriteString(nonce)
+	b.WriteByte('\n')
+	b.WriteString(bodyHash)
+	return b.String()
+}
+
+// canonicalQuery 按键和值排序的查询参数
+func canonicalQuery(query url.Values) string {
+	pairs := make([]string, 0, len(query))
+	for key, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, url.QueryEscape(key)+"="+url.QueryEscape(value))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// signCanonicalRequest 计算签名
+func signCanonicalRequest(secret []byte, canonical string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(canonical))
+	return mac.Sum(nil)
+}
+
+// SignRequest 为请求生成 HMAC 签名，设置 X-Timestamp、X-Nonce 和 Authorization 头
+// signedHeaders 为额外参与签名的请求头，host 总是参与签名；请求体会被读取后恢复
+func SignRequest(r *http.Request, keyID string, secret []byte, signedHeaders ...string) error {
+	headers := []string{"host"}
+	for _, header := range signedHeaders {
+		if header = strings.ToLower(header); !slices.Contains(headers, header) {
+			headers = append(headers, header)
+		}
+	}
+	sort.Strings(headers)
+	if r.Host == "" {
+		r.Host = r.URL.Host
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	r.Header.Set(HeaderTimestamp, timestamp)
+	r.Header.Set(HeaderNonce, hex.EncodeToString(nonce))
+
+	bodyHash, err := hashBody(r, 1<<62)
+	if err != nil {
+		return err
+	}
+	signature := signCanonicalRequest(secret, canonicalRequest(r, headers, timestamp, r.Header.Get(HeaderNonce), bodyHash))
+	r.Header.Set("Authorization", fmt.Sprintf("%s KeyId=%s, SignedHeaders=%s, Signature=%s",
+		HMACAlgorithm, keyID, strings.Join(headers, ";"), hex.EncodeToString(signature)))
+	return nil
+}
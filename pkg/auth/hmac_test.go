@@ -0,0 +1,156 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package auth_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stones-hub/taurus-pro-http/pkg/auth"
+)
+
+var hmacSecret = []byte("hmac-secret")
+
+// newHMACAuthenticator 创建使用 KeyId "client" 的 HMAC 认证器
+func newHMACAuthenticator(t *testing.T, required ...string) *auth.HMACAuthenticator {
+	t.Helper()
+	nonces := auth.NewMemoryNonceStore(0)
+	t.Cleanup(nonces.Close)
+	a, err := auth.NewHMACAuthenticator(auth.HMACConfig{
+		Secrets:         map[string][]byte{"client": hmacSecret},
+		Nonces:          nonces,
+		RequiredHeaders: required,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+// signedRequest 创建已签名的 POST 请求
+func signedRequest(t *testing.T, secret []byte, headers ...string) *http.Request {
+	t.Helper()
+	r := httptest.NewRequest(http.MethodPost, "http://api.example.com/orders?b=2&a=1", strings.NewReader(`{"id":1}`))
+	r.Header.Set("Content-Type", "application/json")
+	if err := auth.SignRequest(r, "client", secret, headers...); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestHMACAuthenticator(t *testing.T) {
+	a := newHMACAuthenticator(t)
+
+	r := signedRequest(t, hmacSecret, "content-type")
+	principal, err := a.Authenticate(r)
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if principal.ID != "client" || principal.Scheme != auth.SchemeHMAC {
+		t.Errorf("principal = %+v, want client via hmac", principal)
+	}
+	// 请求体在验证后仍可读取
+	if body, _ := io.ReadAll(r.Body); string(body) != `{"id":1}` {
+		t.Errorf("body after Authenticate = %q", body)
+	}
+}
+
+func TestHMACAuthenticatorRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		request func(t *testing.T) *http.Request
+		wantErr error
+	}{
+		{"no credentials", func(t *testing.T) *http.Request {
+			return httptest.NewRequest(http.MethodGet, "/", nil)
+		}, auth.ErrNoCredentials},
+		{"wrong secret", func(t *testing.T) *http.Request {
+			return signedRequest(t, []byte("other"))
+		}, auth.ErrInvalidSignature},
+		{"unknown key id", func(t *testing.T) *http.Request {
+			r := signedRequest(t, hmacSecret)
+			r.Header.Set("Authorization", strings.Replace(r.Header.Get("Authorization"), "KeyId=client", "KeyId=other", 1))
+			return r
+		}, auth.ErrInvalidCredentials},
+		{"tampered body", func(t *testing.T) *http.Request {
+			r := signedRequest(t, hmacSecret)
+			r.Body = io.NopCloser(strings.NewReader(`{"id":2}`))
+			return r
+		}, auth.ErrInvalidSignature},
+		{"tampered query", func(t *testing.T) *http.Request {
+			r := signedRequest(t, hmacSecret)
+			r.URL.RawQuery = "a=1&b=3"
+			return r
+		}, auth.ErrInvalidSignature},
+		{"tampered signed header", func(t *testing.T) *http.Request {
+			r := signedRequest(t, hmacSecret, "content-type")
+			r.Header.Set("Content-Type", "text/plain")
+			return r
+		}, auth.ErrInvalidSignature},
+		{"expired timestamp", func(t *testing.T) *http.Request {
+			r := signedRequest(t, hmacSecret)
+			r.Header.Set(auth.HeaderTimestamp, strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10))
+			return r
+		}, auth.ErrSignatureExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newHMACAuthenticator(t)
+			if _, err := a.Authenticate(tt.request(t)); !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHMACAuthenticatorRejectsReplay(t *testing.T) {
+	a := newHMACAuthenticator(t)
+	r := signedRequest(t, hmacSecret)
+	replay := r.Clone(r.Context())
+	replay.Body = io.NopCloser(strings.NewReader(`{"id":1}`))
+
+	if _, err := a.Authenticate(r); err != nil {
+		t.Fatalf("first request: %v", err)
+	}
+	if _, err := a.Authenticate(replay); !errors.Is(err, auth.ErrReplayedRequest) {
+		t.Errorf("replayed request: err = %v, want %v", err, auth.ErrReplayedRequest)
+	}
+}
+
+func TestHMACAuthenticatorRequiredHeaders(t *testing.T) {
+	required := []string{"Host", "Content-Type"}
+	a := newHMACAuthenticator(t, required...)
+	if want := []string{"Host", "Content-Type"}; !slices.Equal(required, want) {
+		t.Errorf("NewHMACAuthenticator modified RequiredHeaders to %q", required)
+	}
+
+	if _, err := a.Authenticate(signedRequest(t, hmacSecret)); !errors.Is(err, auth.ErrInvalidCredentials) {
+		t.Errorf("request without signed content-type: err = %v, want %v", err, auth.ErrInvalidCredentials)
+	}
+	if _, err := a.Authenticate(signedRequest(t, hmacSecret, "Content-Type")); err != nil {
+		t.Errorf("request with signed content-type: %v", err)
+	}
+}
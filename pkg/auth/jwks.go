@@ -0,0 +1,235 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// JWKS 带缓存的 JWK Set
+// 1. 密钥按 RefreshInterval 周期性刷新，刷新失败时继续使用旧密钥
+// 2. 遇到未知 kid 时立即刷新一次（受 MinRefreshInterval 限制），以支持密钥轮换
+// 3. 刷新在后台进行，并发请求共享同一次加载，缓存过期时请求不等待刷新
+type JWKS struct {
+	source             string
+	fetch              func(ctx context.Context) ([]byte, error)
+	refreshInterval    time.Duration
+	minRefreshInterval time.Duration
+	httpClient         *http.Client
+
+	mu          sync.RWMutex
+	keys        []Key
+	fetchedAt   time.Time
+	lastAttempt time.Time
+
+	refreshMu  sync.Mutex
+	refreshing *refreshCall // 正在进行的刷新
+}
+
+// jwksRefreshTimeout 单次刷新的超时时间，刷新不再受请求 ctx 控制
+const jwksRefreshTimeout = 30 * time.Second
+
+// JWKSOption JWKS 配置选项
+type JWKSOption func(*JWKS)
+
+// WithRefreshInterval 设置周期刷新间隔，默认 5 分钟
+func WithRefreshInterval(d time.Duration) JWKSOption {
+	return func(j *JWKS) {
+		j.refreshInterval = d
+	}
+}
+
+// WithMinRefreshInterval 设置两次刷新的最小间隔，避免伪造 kid 的请求频繁触发刷新，默认 10 秒
+func WithMinRefreshInterval(d time.Duration) JWKSOption {
+	return func(j *JWKS) {
+		j.minRefreshInterval = d
+	}
+}
+
+// WithHTTPClient 设置获取远程 JWKS 使用的 HTTP 客户端，默认超时 10 秒
+func WithHTTPClient(client *http.Client) JWKSOption {
+	return func(j *JWKS) {
+		j.httpClient = client
+	}
+}
+
+// NewJWKSFromFile 从本地文件加载 JWKS，文件内容变化后在下次刷新时生效
+func NewJWKSFromFile(path string, options ...JWKSOption) (*JWKS, error) {
+	j := newJWKS(path, options...)
+	j.fetch = func(context.Context) ([]byte, error) {
+		return os.ReadFile(path)
+	}
+	if err := j.Refresh(context.Background()); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// NewJWKSFromURL 从 HTTP 地址加载 JWKS，如 https://issuer/.well-known/jwks.json
+func NewJWKSFromURL(url string, options ...JWKSOption) (*JWKS, error) {
+	j := newJWKS(url, options...)
+	if j.httpClient == nil {
+		j.httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	j.fetch = func(ctx context.Context) ([]byte, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "application/json")
+		resp, err := j.httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		}
+		return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	}
+	if err := j.Refresh(context.Background()); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+func newJWKS(source string, options ...JWKSOption) *JWKS {
+	j := &JWKS{
+		source:             source,
+		refreshInterval:    5 * time.Minute,
+		minRefreshInterval: 10 * time.Second,
+	}
+	for _, option := range options {
+		option(j)
+	}
+	return j
+}
+
+// Keys 返回当前缓存的密钥
+func (j *JWKS) Keys() []Key {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return append([]Key(nil), j.keys...)
+}
+
+// LookupKey 实现 KeyProvider
+func (j *JWKS) LookupKey(ctx context.Context, kid, alg string) (Key, error) {
+	j.mu.RLock()
+	keys, fetchedAt := j.keys, j.fetchedAt
+	j.mu.RUnlock()
+
+	if time.Since(fetchedAt) >= j.refreshInterval {
+		// 缓存过期时在后台刷新，本次请求继续使用旧密钥
+		j.startRefresh(ctx, false)
+	}
+
+	key, err := findKey(keys, kid, alg)
+	if errors.Is(err, ErrKeyNotFound) && kid != "" {
+		// 未知 kid，可能是密钥已轮换，等待刷新结果
+		call := j.startRefresh(ctx, false)
+		if call == nil || call.wait(ctx) != nil {
+			return Key{}, err
+		}
+		return findKey(j.Keys(), kid, alg)
+	}
+	return key, err
+}
+
+// Refresh 立即重新加载密钥，已有刷新在进行时等待其结果
+func (j *JWKS) Refresh(ctx context.Context) error {
+	return j.startRefresh(ctx, true).wait(ctx)
+}
+
+// refreshCall 一次正在进行的刷新，并发的请求共享同一次加载
+type refreshCall struct {
+	done chan struct{}
+	err  error
+}
+
+// wait 等待刷新完成，ctx 取消时提前返回，但不会中断刷新本身
+func (c *refreshCall) wait(ctx context.Context) error {
+	select {
+	case <-c.done:
+		return c.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// startRefresh 在后台刷新密钥，已有刷新在进行时直接返回该刷新
+// force 为 false 时距离上次刷新不足 MinRefreshInterval 返回 nil
+func (j *JWKS) startRefresh(ctx context.Context, force bool) *refreshCall {
+	j.refreshMu.Lock()
+	defer j.refreshMu.Unlock()
+
+	if j.refreshing != nil {
+		return j.refreshing
+	}
+	j.mu.Lock()
+	if !force && time.Since(j.lastAttempt) < j.minRefreshInterval {
+		j.mu.Unlock()
+		return nil
+	}
+	j.lastAttempt = time.Now()
+	j.mu.Unlock()
+
+	call := &refreshCall{done: make(chan struct{})}
+	j.refreshing = call
+	// 刷新与触发它的请求解绑，请求取消不影响其他等待同一次刷新的请求
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), jwksRefreshTimeout)
+	go func() {
+		defer cancel()
+		call.err = j.load(ctx)
+		if call.err != nil {
+			log.Printf("[JWKS] refresh %s failed: %v", j.source, call.err)
+		}
+
+		j.refreshMu.Lock()
+		j.refreshing = nil
+		j.refreshMu.Unlock()
+		close(call.done)
+	}()
+	return call
+}
+
+// load 加载并替换密钥
+func (j *JWKS) load(ctx context.Context) error {
+	data, err := j.fetch(ctx)
+	if err != nil {
+		return fmt.Errorf("load jwks from %s: %w", j.source, err)
+	}
+	keys, err := ParseJWKS(data)
+	if err != nil {
+		return fmt.Errorf("load jwks from %s: %w", j.source, err)
+	}
+
+	j.mu.Lock()
+	j.keys = keys
+	j.fetchedAt = time.Now()
+	j.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,192 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package auth_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stones-hub/taurus-pro-http/pkg/auth"
+)
+
+// jwksBody 生成包含指定 kid 的 HS256 密钥集
+func jwksBody(kids ...string) string {
+	keys := make([]string, len(kids))
+	for i, kid := range kids {
+		keys[i] = fmt.Sprintf(`{"kty":"oct","kid":%q,"alg":"HS256","k":"c2VjcmV0"}`, kid)
+	}
+	return `{"keys":[` + strings.Join(keys, ",") + `]}`
+}
+
+// jwksServer 可切换密钥、可阻塞响应的 JWKS 服务
+type jwksServer struct {
+	*httptest.Server
+	hits atomic.Int32
+
+	mu    sync.Mutex
+	body  string
+	block chan struct{} // 不为 nil 时响应等待该通道关闭
+}
+
+func newJWKSServer(t *testing.T, kids ...string) *jwksServer {
+	s := &jwksServer{body: jwksBody(kids...)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.hits.Add(1)
+		s.mu.Lock()
+		body, block := s.body, s.block
+		s.mu.Unlock()
+		if block != nil {
+			<-block
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// update 替换密钥，并让之后的请求阻塞直到返回的函数被调用
+func (s *jwksServer) update(kids ...string) (release func()) {
+	block := make(chan struct{})
+	s.mu.Lock()
+	s.body, s.block = jwksBody(kids...), block
+	s.mu.Unlock()
+	return sync.OnceFunc(func() { close(block) })
+}
+
+func TestJWKSUnknownKidSharesOneRefresh(t *testing.T) {
+	server := newJWKSServer(t, "k1")
+	jwks, err := auth.NewJWKSFromURL(server.URL, auth.WithMinRefreshInterval(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	release := server.update("k1", "k2")
+	defer release()
+
+	// 触发刷新的请求被取消后，刷新继续进行，其他请求仍能拿到新密钥
+	canceled, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := jwks.LookupKey(canceled, "k2", auth.AlgHS256)
+		first <- err
+	}()
+	for server.hits.Load() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-first; err == nil {
+		t.Error("canceled lookup found the key before the refresh finished")
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			key, err := jwks.LookupKey(context.Background(), "k2", auth.AlgHS256)
+			if err == nil && key.ID != "k2" {
+				err = fmt.Errorf("got key %q", key.ID)
+			}
+			errs <- err
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	release()
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("LookupKey: %v", err)
+		}
+	}
+	if hits := server.hits.Load(); hits != 2 {
+		t.Errorf("jwks fetched %d times, want 2", hits)
+	}
+}
+
+func TestJWKSStaleKeysRefreshInBackground(t *testing.T) {
+	server := newJWKSServer(t, "k1")
+	jwks, err := auth.NewJWKSFromURL(server.URL, auth.WithRefreshInterval(time.Millisecond), auth.WithMinRefreshInterval(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	release := server.update("k2")
+	defer release()
+	time.Sleep(2 * time.Millisecond)
+
+	// 刷新被阻塞时，缓存过期的请求直接使用旧密钥
+	done := make(chan error, 1)
+	go func() {
+		_, err := jwks.LookupKey(context.Background(), "k1", auth.AlgHS256)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("LookupKey with stale keys: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("LookupKey waited for the background refresh")
+	}
+
+	release()
+	if err := jwks.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if keys := jwks.Keys(); len(keys) != 1 || keys[0].ID != "k2" {
+		t.Errorf("keys after refresh = %v, want k2", keys)
+	}
+}
+
+func TestJWKSFromFileKeepsKeysOnError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, []byte(jwksBody("k1")), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	jwks, err := auth.NewJWKSFromFile(path, auth.WithMinRefreshInterval(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, []byte("not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := jwks.Refresh(context.Background()); err == nil {
+		t.Fatal("Refresh accepted an invalid jwks")
+	}
+	if _, err := jwks.LookupKey(context.Background(), "k1", auth.AlgHS256); err != nil {
+		t.Errorf("cached key lost after a failed refresh: %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte(jwksBody("k1", "k2")), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if key, err := jwks.LookupKey(context.Background(), "k2", auth.AlgHS256); err != nil || key.ID != "k2" {
+		t.Errorf("LookupKey after rotation = %q, %v, want k2", key.ID, err)
+	}
+}
@@ -0,0 +1,513 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Claims JWT claims
+// 标准字段已解析，自定义字段通过 Raw 或 String/Strings 获取
+type Claims struct {
+	Issuer    string
+	Subject   string
+	Audience  []string
+	ExpiresAt time.Time
+	NotBefore time.Time
+	IssuedAt  time.Time
+	ID        string
+	Raw       map[string]any // 全部 claims，数字类型为 json.Number
+}
+
+// String 获取字符串类型的自定义字段
+func (c *Claims) String(name string) string {
+	s, _ := c.Raw[name].(string)
+	return s
+}
+
+// Strings 获取字符串数组类型的自定义字段，值为字符串时按空格分割（如 OAuth2 的 scope）
+func (c *Claims) Strings(name string) []string {
+	switch v := c.Raw[name].(type) {
+	case string:
+		return strings.Fields(v)
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+// jwtHeader JOSE header
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid,omitempty"`
+	Typ string `json:"typ,omitempty"`
+}
+
+// JWTConfig JWT 认证配置
+type JWTConfig struct {
+	// Keys 验证密钥，必填，可以是 StaticKeys、HMACKey 或 JWKS
+	Keys KeyProvider
+	// Algorithms 允许的签名算法，默认 HS256/RS256/ES256/EdDSA 全部允许
+	// 建议只配置实际使用的算法
+	Algorithms []string
+	// Issuers 允许的签发者，为空时不校验 iss
+	Issuers []string
+	// Audience 允许的受众，token 的 aud 至少包含其中一个，为空时不校验 aud
+	Audience []string
+	// ClockSkew 校验 exp/nbf/iat 时允许的时钟偏差，默认 1 分钟
+	ClockSkew time.Duration
+	// AllowMissingExpiry 允许 token 不带 exp，默认不允许
+	AllowMissingExpiry bool
+
+	// QueryParam 从查询参数中读取 token，如 "access_token"，默认只从 Authorization: Bearer 读取
+	// 浏览器的 WebSocket 无法设置请求头，可以通过该参数传递 token
+	QueryParam string
+	// CookieName 从 Cookie 中读取 token
+	CookieName string
+	// Optional 没有 token 时放行（不写入 claims），token 无效时仍然返回 401
+	Optional bool
+	// OnError 认证失败回调，可用于记录日志或指标
+	OnError func(r *http.Request, err error)
+	// PrincipalFunc 将 claims 转换为 Principal，默认使用 sub 作为 ID，
+	// roles/permissions 作为角色和权限，scope/scp 作为 scope
+	PrincipalFunc func(claims *Claims) *Principal
+}
+
+// JWTVerifier JWT 验证器
+type JWTVerifier struct {
+	config JWTConfig
+}
+
+// NewJWTVerifier 创建 JWT 验证器
+func NewJWTVerifier(config JWTConfig) (*JWTVerifier, error) {
+	if config.Keys == nil {
+		return nil, fmt.Errorf("jwt keys are required")
+	}
+	if len(config.Algorithms) == 0 {
+		config.Algorithms = []string{AlgHS256, AlgRS256, AlgES256, AlgEdDSA}
+	}
+	for _, alg := range config.Algorithms {
+		switch alg {
+		case AlgHS256, AlgRS256, AlgES256, AlgEdDSA:
+		default:
+			return nil, fmt.Errorf("unsupported jwt algorithm: %s", alg)
+		}
+	}
+	if config.ClockSkew <= 0 {
+		config.ClockSkew = time.Minute
+	}
+	if config.PrincipalFunc == nil {
+		config.PrincipalFunc = defaultPrincipal
+	}
+	return &JWTVerifier{config: config}, nil
+}
+
+// NewJWTMiddleware 创建 JWT 认证中间件，配置无效时返回错误
+// 认证成功后 claims 和 Principal 写入请求上下文，通过 auth.GetClaims(r)、auth.GetPrincipal(r) 获取；失败时返回 401
+func NewJWTMiddleware(config JWTConfig) (func(http.Handler) http.Handler, error) {
+	verifier, err := NewJWTVerifier(config)
+	if err != nil {
+		return nil, err
+	}
+	return verifier.Middleware(), nil
+}
+
+// Middleware 返回 JWT 认证中间件
+func (v *JWTVerifier) Middleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := v.extractToken(r)
+			if token == "" {
+				if v.config.Optional {
+					next.ServeHTTP(w, r)
+					return
+				}
+				v.fail(w, r, ErrMissingToken)
+				return
+			}
+
+			claims, err := v.Verify(r.Context(), token)
+			if err != nil {
+				v.fail(w, r, err)
+				return
+			}
+			ctx := WithClaims(r.Context(), claims)
+			ctx = WithPrincipal(ctx, v.config.PrincipalFunc(claims))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Authenticate 实现 Authenticator，可以与其他认证方式组合使用
+func (v *JWTVerifier) Authenticate(r *http.Request) (*Principal, error) {
+	token := v.extractToken(r)
+	if token == "" {
+		return nil, ErrNoCredentials
+	}
+	claims, err := v.Verify(r.Context(), token)
+	if err != nil {
+		return nil, err
+	}
+	return v.config.PrincipalFunc(claims), nil
+}
+
+// Challenge 实现 Challenger
+func (v *JWTVerifier) Challenge() string {
+	return "Bearer"
+}
+
+// defaultPrincipal 默认的 claims 到 Principal 的转换
+func defaultPrincipal(claims *Claims) *Principal {
+	return &Principal{
+		ID:          claims.Subject,
+		Scheme:      SchemeJWT,
+		Roles:       claims.Strings("roles"),
+		Permissions: claims.Strings("permissions"),
+		Scopes:      append(claims.Strings("scope"), claims.Strings("scp")...),
+		Claims:      claims,
+	}
+}
+
+// fail 返回 401
+func (v *JWTVerifier) fail(w http.ResponseWriter, r *http.Request, err error) {
+	if v.config.OnError != nil {
+		v.config.OnError(r, err)
+	}
+	challenge := "Bearer"
+	if !errors.Is(err, ErrMissingToken) {
+		log.Printf("[JWT] authentication failed, path: %s, error: %v", r.URL.Path, err)
+		challenge = fmt.Sprintf("Bearer error=\"invalid_token\", error_description=%q", err.Error())
+	}
+	sendUnauthorized(w, challenge, err)
+}
+
+// extractToken 依次从 Authorization 头、查询参数、Cookie 中读取 token
+func (v *JWTVerifier) extractToken(r *http.Request) string {
+	if authorization := r.Header.Get("Authorization"); authorization != "" {
+		if scheme, token, ok := strings.Cut(authorization, " "); ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+	}
+	if v.config.QueryParam != "" {
+		if token := r.URL.Query().Get(v.config.QueryParam); token != "" {
+			return token
+		}
+	}
+	if v.config.CookieName != "" {
+		if cookie, err := r.Cookie(v.config.CookieName); err == nil {
+			return cookie.Value
+		}
+	}
+	return ""
+}
+
+// Verify 验证 token 的签名和标准 claims，返回解析后的 claims
+// 返回的错误可以通过 errors.Is 与 ErrTokenExpired 等错误比较
+func (v *JWTVerifier) Verify(ctx context.Context, token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformedToken
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, ErrMalformedToken
+	}
+	if !slices.Contains(v.config.Algorithms, header.Alg) {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, header.Alg)
+	}
+
+	key, err := v.config.Keys.LookupKey(ctx, header.Kid, header.Alg)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+	if err := verifySignature(header.Alg, key.Key, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, err
+	}
+
+	var raw map[string]any
+	if err := decodeSegment(parts[1], &raw); err != nil {
+		return nil, ErrMalformedToken
+	}
+	claims, err := parseClaims(raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := v.validateClaims(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// validateClaims 校验 exp/nbf/iat/iss/aud
+func (v *JWTVerifier) validateClaims(claims *Claims) error {
+	now := time.Now()
+	skew := v.config.ClockSkew
+
+	if claims.ExpiresAt.IsZero() {
+		if !v.config.AllowMissingExpiry {
+			return fmt.Errorf("%w: missing exp", ErrMalformedToken)
+		}
+	} else if now.After(claims.ExpiresAt.Add(skew)) {
+		return ErrTokenExpired
+	}
+	if !claims.NotBefore.IsZero() && now.Add(skew).Before(claims.NotBefore) {
+		return ErrTokenNotYetValid
+	}
+	if !claims.IssuedAt.IsZero() && now.Add(skew).Before(claims.IssuedAt) {
+		return ErrTokenNotYetValid
+	}
+	if len(v.config.Issuers) > 0 && !slices.Contains(v.config.Issuers, claims.Issuer) {
+		return ErrInvalidIssuer
+	}
+	if len(v.config.Audience) > 0 && !slices.ContainsFunc(claims.Audience, func(aud string) bool {
+		return slices.Contains(v.config.Audience, aud)
+	}) {
+		return ErrInvalidAudience
+	}
+	return nil
+}
+
+// parseClaims 解析标准 claims
+func parseClaims(raw map[string]any) (*Claims, error) {
+	claims := &Claims{Raw: raw}
+	var err error
+	claims.Issuer, _ = raw["iss"].(string)
+	claims.Subject, _ = raw["sub"].(string)
+	claims.ID, _ = raw["jti"].(string)
+	if claims.Audience, err = parseAudience(raw["aud"]); err != nil {
+		return nil, err
+	}
+	for name, target := range map[string]*time.Time{"exp": &claims.ExpiresAt, "nbf": &claims.NotBefore, "iat": &claims.IssuedAt} {
+		if *target, err = parseNumericDate(raw[name]); err != nil {
+			return nil, fmt.Errorf("%w: invalid %s", ErrMalformedToken, name)
+		}
+	}
+	return claims, nil
+}
+
+// parseAudience aud 可以是字符串或字符串数组
+func parseAudience(value any) ([]string, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{v}, nil
+	case []any:
+		audience := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%w: invalid aud", ErrMalformedToken)
+			}
+			audience = append(audience, s)
+		}
+		return audience, nil
+	default:
+		return nil, fmt.Errorf("%w: invalid aud", ErrMalformedToken)
+	}
+}
+
+// parseNumericDate 解析 NumericDate（Unix 秒，允许小数）
+func parseNumericDate(value any) (time.Time, error) {
+	switch v := value.(type) {
+	case nil:
+		return time.Time{}, nil
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return time.Time{}, err
+		}
+		sec, frac := int64(f), f-float64(int64(f))
+		return time.Unix(sec, int64(frac*1e9)), nil
+	default:
+		return time.Time{}, fmt.Errorf("invalid numeric date")
+	}
+}
+
+// decodeSegment 解码 base64url 编码的 JSON 片段
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+// verifySignature 验证签名，密钥类型必须与算法匹配
+func verifySignature(alg string, key any, signingInput, signature []byte) error {
+	switch alg {
+	case AlgHS256:
+		secret, ok := key.([]byte)
+		if !ok {
+			return ErrKeyNotFound
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(signingInput)
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return ErrInvalidSignature
+		}
+	case AlgRS256:
+		pub, ok := publicKey(key).(*rsa.PublicKey)
+		if !ok {
+			return ErrKeyNotFound
+		}
+		digest := sha256.Sum256(signingInput)
+		if rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], signature) != nil {
+			return ErrInvalidSignature
+		}
+	case AlgES256:
+		pub, ok := publicKey(key).(*ecdsa.PublicKey)
+		if !ok {
+			return ErrKeyNotFound
+		}
+		// JWS 中 ES256 签名为 r||s 各 32 字节，不是 ASN.1 编码
+		if len(signature) != 64 {
+			return ErrInvalidSignature
+		}
+		digest := sha256.Sum256(signingInput)
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(pub, digest[:], r, s) {
+			return ErrInvalidSignature
+		}
+	case AlgEdDSA:
+		pub, ok := publicKey(key).(ed25519.PublicKey)
+		if !ok {
+			return ErrKeyNotFound
+		}
+		if !ed25519.Verify(pub, signingInput, signature) {
+			return ErrInvalidSignature
+		}
+	default:
+		return ErrUnsupportedAlgorithm
+	}
+	return nil
+}
+
+// publicKey 私钥转换为公钥，方便同一个 Key 同时用于签名和验证
+func publicKey(key any) any {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return &k.PublicKey
+	case *ecdsa.PrivateKey:
+		return &k.PublicKey
+	case ed25519.PrivateKey:
+		return k.Public()
+	default:
+		return key
+	}
+}
+
+// SignJWT 使用 key 签发 token，key.Algorithm 为空时根据密钥类型推断
+// claims 中的时间字段（exp/nbf/iat）使用 Unix 秒
+// 示例: auth.SignJWT(map[string]any{"sub": "1", "exp": time.Now().Add(time.Hour).Unix()}, auth.Key{Key: secret})
+func SignJWT(claims map[string]any, key Key) (string, error) {
+	alg := key.Algorithm
+	if alg == "" {
+		alg = inferAlgorithm(key.Key)
+	}
+	if !key.supports(alg) {
+		return "", fmt.Errorf("%w: key type %T does not support %q", ErrUnsupportedAlgorithm, key.Key, alg)
+	}
+
+	header, err := json.Marshal(jwtHeader{Alg: alg, Kid: key.ID, Typ: "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	var signature []byte
+	switch k := key.Key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, k)
+		mac.Write([]byte(signingInput))
+		signature = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		digest := sha256.Sum256([]byte(signingInput))
+		if signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:]); err != nil {
+			return "", err
+		}
+	case *ecdsa.PrivateKey:
+		digest := sha256.Sum256([]byte(signingInput))
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		if err != nil {
+			return "", err
+		}
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+	case ed25519.PrivateKey:
+		signature = ed25519.Sign(k, []byte(signingInput))
+	default:
+		return "", fmt.Errorf("signing requires a secret or private key, got %T", key.Key)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// inferAlgorithm 根据密钥类型推断签名算法
+func inferAlgorithm(key any) string {
+	switch key.(type) {
+	case []byte:
+		return AlgHS256
+	case *rsa.PrivateKey, *rsa.PublicKey:
+		return AlgRS256
+	case *ecdsa.PrivateKey, *ecdsa.PublicKey:
+		return AlgES256
+	case ed25519.PrivateKey, ed25519.PublicKey:
+		return AlgEdDSA
+	default:
+		return ""
+	}
+}
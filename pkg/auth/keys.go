@@ -0,0 +1,226 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// 支持的签名算法
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgES256 = "ES256"
+	AlgEdDSA = "EdDSA"
+)
+
+// Key 签名密钥
+// Key 字段的类型:
+//   - HS256: []byte
+//   - RS256: *rsa.PublicKey（验证）/ *rsa.PrivateKey（签名）
+//   - ES256: *ecdsa.PublicKey（验证）/ *ecdsa.PrivateKey（签名），曲线必须为 P-256
+//   - EdDSA: ed25519.PublicKey（验证）/ ed25519.PrivateKey（签名）
+type Key struct {
+	ID        string // kid，为空时匹配任意 kid
+	Algorithm string // 限定算法，为空时根据密钥类型推断
+	Key       any
+}
+
+// supports 密钥是否可以用于该算法
+func (k Key) supports(alg string) bool {
+	if k.Algorithm != "" && k.Algorithm != alg {
+		return false
+	}
+	switch key := k.Key.(type) {
+	case []byte:
+		return alg == AlgHS256
+	case *rsa.PublicKey, *rsa.PrivateKey:
+		return alg == AlgRS256
+	case *ecdsa.PublicKey:
+		return alg == AlgES256 && key.Curve == elliptic.P256()
+	case *ecdsa.PrivateKey:
+		return alg == AlgES256 && key.Curve == elliptic.P256()
+	case ed25519.PublicKey, ed25519.PrivateKey:
+		return alg == AlgEdDSA
+	default:
+		return false
+	}
+}
+
+// KeyProvider 根据 JWT header 中的 kid 和 alg 查找验证密钥
+type KeyProvider interface {
+	LookupKey(ctx context.Context, kid, alg string) (Key, error)
+}
+
+// StaticKeys 固定的密钥集合，适用于 HS256 共享密钥或手动配置的公钥
+type StaticKeys []Key
+
+// NewStaticKeys 创建固定密钥集合
+func NewStaticKeys(keys ...Key) StaticKeys {
+	return StaticKeys(keys)
+}
+
+// HMACKey 创建 HS256 共享密钥集合
+func HMACKey(secret []byte) StaticKeys {
+	return StaticKeys{{Algorithm: AlgHS256, Key: secret}}
+}
+
+// LookupKey 实现 KeyProvider
+func (s StaticKeys) LookupKey(_ context.Context, kid, alg string) (Key, error) {
+	return findKey(s, kid, alg)
+}
+
+// findKey 查找与 kid 和 alg 匹配的密钥
+// token 带 kid 时优先精确匹配，没有匹配的 kid 时退回到未设置 ID 的密钥
+func findKey(keys []Key, kid, alg string) (Key, error) {
+	var fallback *Key
+	for i := range keys {
+		key := keys[i]
+		if !key.supports(alg) {
+			continue
+		}
+		if kid != "" && key.ID == kid {
+			return key, nil
+		}
+		if fallback == nil && (kid == "" || key.ID == "") {
+			fallback = &keys[i]
+		}
+	}
+	if fallback != nil {
+		return *fallback, nil
+	}
+	return Key{}, ErrKeyNotFound
+}
+
+// jwk JSON Web Key，参考 RFC 7517 / RFC 7518 / RFC 8037
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+// ParseJWKS 解析 JWK Set（{"keys": [...]}），跳过用于加密（use=enc）和不支持的密钥
+func ParseJWKS(data []byte) ([]Key, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("invalid jwks: %w", err)
+	}
+
+	keys := make([]Key, 0, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.toKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid jwk %q: %w", k.Kid, err)
+		}
+		if key.Key == nil {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// toKey 转换为 Key，不支持的密钥类型返回空 Key
+func (k jwk) toKey() (Key, error) {
+	key := Key{ID: k.Kid, Algorithm: k.Alg}
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return key, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return key, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return key, fmt.Errorf("invalid rsa exponent")
+		}
+		key.Key = &rsa.PublicKey{N: n, E: int(e.Int64())}
+	case "EC":
+		if k.Crv != "P-256" {
+			return Key{}, nil
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return key, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return key, err
+		}
+		if !elliptic.P256().IsOnCurve(x, y) {
+			return key, fmt.Errorf("point is not on curve P-256")
+		}
+		key.Key = &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return Key{}, nil
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return key, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return key, fmt.Errorf("invalid ed25519 public key size")
+		}
+		key.Key = ed25519.PublicKey(x)
+	case "oct":
+		secret, err := base64.RawURLEncoding.DecodeString(k.K)
+		if err != nil {
+			return key, err
+		}
+		key.Key = secret
+	default:
+		return Key{}, nil
+	}
+	return key, nil
+}
+
+// decodeBigInt 解码 base64url 编码的大整数
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, fmt.Errorf("empty integer")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
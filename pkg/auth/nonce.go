@@ -0,0 +1,117 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// NonceStore 记录已使用的 nonce，用于防重放
+type NonceStore interface {
+	// Remember 记录 nonce，ttl 内重复出现时返回 false
+	Remember(ctx context.Context, nonce string, ttl time.Duration) (bool, error)
+}
+
+// MemoryNonceStore 基于内存的 nonce 存储，适合单机部署
+type MemoryNonceStore struct {
+	mu     sync.Mutex
+	nonces map[string]time.Time
+	stop   chan struct{}
+	once   sync.Once
+}
+
+// NewMemoryNonceStore 创建内存 nonce 存储
+// cleanupInterval 为过期数据清理周期，<=0 时默认 1 分钟
+func NewMemoryNonceStore(cleanupInterval time.Duration) *MemoryNonceStore {
+	if cleanupInterval <= 0 {
+		cleanupInterval = time.Minute
+	}
+	s := &MemoryNonceStore{
+		nonces: make(map[string]time.Time),
+		stop:   make(chan struct{}),
+	}
+	go s.cleanup(cleanupInterval)
+	return s
+}
+
+// Remember 实现 NonceStore
+func (s *MemoryNonceStore) Remember(_ context.Context, nonce string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if expires, ok := s.nonces[nonce]; ok && now.Before(expires) {
+		return false, nil
+	}
+	s.nonces[nonce] = now.Add(ttl)
+	return true, nil
+}
+
+// cleanup 定期清理过期的 nonce
+func (s *MemoryNonceStore) cleanup(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case now := <-ticker.C:
+			s.mu.Lock()
+			for nonce, expires := range s.nonces {
+				if now.After(expires) {
+					delete(s.nonces, nonce)
+				}
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// Close 停止清理协程
+func (s *MemoryNonceStore) Close() {
+	s.once.Do(func() { close(s.stop) })
+}
+
+// RedisNonceStore 基于 Redis 的 nonce 存储，适合多实例部署
+type RedisNonceStore struct {
+	client redis.StringCmdable
+	prefix string
+}
+
+// NewRedisNonceStore 创建 Redis nonce 存储
+// client 可以是 *redis.Client、*redis.ClusterClient 等；prefix 为 key 前缀，默认 "nonce:"
+func NewRedisNonceStore(client redis.StringCmdable, prefix string) *RedisNonceStore {
+	if prefix == "" {
+		prefix = "nonce:"
+	}
+	return &RedisNonceStore{client: client, prefix: prefix}
+}
+
+// Remember 实现 NonceStore
+func (s *RedisNonceStore) Remember(ctx context.Context, nonce string, ttl time.Duration) (bool, error) {
+	ok, err := s.client.SetNX(ctx, s.prefix+nonce, 1, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("redis nonce store: %w", err)
+	}
+	return ok, nil
+}
@@ -0,0 +1,188 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/stones-hub/taurus-pro-http/pkg/httpx"
+)
+
+// ErrNoCredentials 请求中没有该认证方式的凭证，认证链会继续尝试下一个认证器
+var ErrNoCredentials = errors.New("missing credentials")
+
+// 内置认证方式名称
+const (
+	SchemeJWT    = "jwt"
+	SchemeAPIKey = "api_key"
+	SchemeBasic  = "basic"
+	SchemeHMAC   = "hmac"
+)
+
+// Principal 认证后的调用方身份，与认证方式无关
+type Principal struct {
+	ID          string         // 用户或调用方 ID
+	Scheme      string         // 认证方式，如 jwt、api_key、basic、hmac
+	Roles       []string       // 角色
+	Permissions []string       // 权限
+	Scopes      []string       // OAuth2 scope
+	Attributes  map[string]any // 其他属性，如租户 ID
+	Claims      *Claims        // JWT 认证时的原始 claims
+}
+
+// HasRole 是否拥有角色
+func (p *Principal) HasRole(role string) bool {
+	return p != nil && slices.Contains(p.Roles, role)
+}
+
+// HasPermission 是否拥有权限
+func (p *Principal) HasPermission(permission string) bool {
+	return p != nil && slices.Contains(p.Permissions, permission)
+}
+
+// HasScope 是否拥有 scope
+func (p *Principal) HasScope(scope string) bool {
+	return p != nil && slices.Contains(p.Scopes, scope)
+}
+
+// Authenticator 认证器
+// 请求中没有对应凭证时返回 ErrNoCredentials，凭证无效时返回其他错误
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+// Challenger 可选接口，返回认证失败时 WWW-Authenticate 头的值
+type Challenger interface {
+	Challenge() string
+}
+
+// AuthenticatorFunc 函数形式的认证器
+type AuthenticatorFunc func(r *http.Request) (*Principal, error)
+
+// Authenticate 实现 Authenticator
+func (f AuthenticatorFunc) Authenticate(r *http.Request) (*Principal, error) {
+	return f(r)
+}
+
+// chain 认证链
+type chain []Authenticator
+
+// Chain 组合多个认证器，按顺序使用第一个携带了凭证的认证器
+// 凭证存在但无效时直接失败，不会继续尝试后面的认证器
+func Chain(authenticators ...Authenticator) Authenticator {
+	return chain(authenticators)
+}
+
+// Authenticate 实现 Authenticator
+func (c chain) Authenticate(r *http.Request) (*Principal, error) {
+	for _, authenticator := range c {
+		principal, err := authenticator.Authenticate(r)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		if err == nil && principal == nil {
+			return nil, errors.New("authenticator returned no principal")
+		}
+		return principal, err
+	}
+	return nil, ErrNoCredentials
+}
+
+// Challenge 合并所有认证器的 challenge
+func (c chain) Challenge() string {
+	challenges := make([]string, 0, len(c))
+	for _, authenticator := range c {
+		if challenger, ok := authenticator.(Challenger); ok {
+			if challenge := challenger.Challenge(); challenge != "" {
+				challenges = append(challenges, challenge)
+			}
+		}
+	}
+	return strings.Join(challenges, ", ")
+}
+
+// Authenticate 创建认证中间件，依次尝试多个认证方式，认证成功后 Principal 写入请求上下文
+// 所有认证方式都没有凭证或凭证无效时返回 401
+// 示例: auth.Authenticate(jwtVerifier, auth.NewAPIKeyAuthenticator(...), auth.NewHMACAuthenticator(...))
+func Authenticate(authenticators ...Authenticator) func(http.Handler) http.Handler {
+	return authenticate(chain(authenticators), false)
+}
+
+// OptionalAuthenticate 与 Authenticate 相同，但没有凭证时放行（不写入 Principal），凭证无效时仍然返回 401
+func OptionalAuthenticate(authenticators ...Authenticator) func(http.Handler) http.Handler {
+	return authenticate(chain(authenticators), true)
+}
+
+func authenticate(c chain, optional bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, err := c.Authenticate(r)
+			if err != nil {
+				if optional && errors.Is(err, ErrNoCredentials) {
+					next.ServeHTTP(w, r)
+					return
+				}
+				if !errors.Is(err, ErrNoCredentials) {
+					log.Printf("[Auth] authentication failed, path: %s, error: %v", r.URL.Path, err)
+				}
+				sendUnauthorized(w, c.Challenge(), err)
+				return
+			}
+			ctx := WithPrincipal(r.Context(), principal)
+			if principal.Claims != nil {
+				ctx = WithClaims(ctx, principal.Claims)
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// WithPrincipal 将 Principal 写入上下文，同时回填到外层的请求记录（访问日志的 user 字段）
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	if principal != nil {
+		httpx.RecordUser(ctx, principal.ID)
+	}
+	return context.WithValue(ctx, principalKey, principal)
+}
+
+// PrincipalFromContext 从上下文获取 Principal
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey).(*Principal)
+	return principal, ok && principal != nil
+}
+
+// GetPrincipal 获取请求的 Principal，未认证时返回 nil
+func GetPrincipal(r *http.Request) *Principal {
+	principal, _ := PrincipalFromContext(r.Context())
+	return principal
+}
+
+// withScheme 复制 Principal 并设置认证方式，避免修改共享的配置数据
+func withScheme(principal *Principal, scheme string) *Principal {
+	p := *principal
+	if p.Scheme == "" {
+		p.Scheme = scheme
+	}
+	return &p
+}
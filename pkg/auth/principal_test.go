@@ -0,0 +1,148 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package auth_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stones-hub/taurus-pro-http/pkg/auth"
+)
+
+// newAPIKeyAuthenticator 创建只接受 key "k1" 的 API Key 认证器
+func newAPIKeyAuthenticator(t *testing.T) *auth.APIKeyAuthenticator {
+	t.Helper()
+	a, err := auth.NewAPIKeyAuthenticator(auth.APIKeyConfig{
+		Keys: map[string]*auth.Principal{"k1": {ID: "service-a", Roles: []string{"reader"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func newBasicAuthenticator(t *testing.T) *auth.BasicAuthenticator {
+	t.Helper()
+	a, err := auth.NewBasicAuthenticator(auth.BasicConfig{Users: map[string]string{"alice": "secret"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func TestChain(t *testing.T) {
+	c := auth.Chain(newAPIKeyAuthenticator(t), newBasicAuthenticator(t))
+
+	tests := []struct {
+		name    string
+		prepare func(r *http.Request)
+		wantID  string
+		wantErr error
+	}{
+		{"no credentials", func(r *http.Request) {}, "", auth.ErrNoCredentials},
+		{"first authenticator", func(r *http.Request) { r.Header.Set("X-Api-Key", "k1") }, "service-a", nil},
+		{"falls through to second", func(r *http.Request) { r.SetBasicAuth("alice", "secret") }, "alice", nil},
+		// 凭证存在但无效时不再尝试后面的认证器
+		{"invalid credentials stop the chain", func(r *http.Request) {
+			r.Header.Set("X-Api-Key", "wrong")
+			r.SetBasicAuth("alice", "secret")
+		}, "", auth.ErrInvalidCredentials},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			tt.prepare(r)
+			principal, err := c.Authenticate(r)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && principal.ID != tt.wantID {
+				t.Errorf("principal = %q, want %q", principal.ID, tt.wantID)
+			}
+		})
+	}
+
+	challenge := c.(auth.Challenger).Challenge()
+	if challenge != `Basic realm="Restricted", charset="UTF-8"` {
+		t.Errorf("challenge = %q", challenge)
+	}
+}
+
+func TestChainRejectsNilPrincipal(t *testing.T) {
+	c := auth.Chain(auth.AuthenticatorFunc(func(r *http.Request) (*auth.Principal, error) { return nil, nil }))
+	if _, err := c.Authenticate(httptest.NewRequest(http.MethodGet, "/", nil)); err == nil {
+		t.Fatal("chain accepted an authenticator that returned neither a principal nor an error")
+	}
+}
+
+func TestAuthenticateMiddleware(t *testing.T) {
+	var got *auth.Principal
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = auth.GetPrincipal(r)
+	})
+	authenticators := []auth.Authenticator{newAPIKeyAuthenticator(t), newBasicAuthenticator(t)}
+
+	tests := []struct {
+		name       string
+		optional   bool
+		key        string
+		wantStatus int
+		wantID     string
+	}{
+		{"authenticated", false, "k1", http.StatusOK, "service-a"},
+		{"missing credentials", false, "", http.StatusUnauthorized, ""},
+		{"invalid credentials", false, "wrong", http.StatusUnauthorized, ""},
+		{"optional without credentials", true, "", http.StatusOK, ""},
+		{"optional with invalid credentials", true, "wrong", http.StatusUnauthorized, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got = nil
+			mw := auth.Authenticate(authenticators...)
+			if tt.optional {
+				mw = auth.OptionalAuthenticate(authenticators...)
+			}
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.key != "" {
+				r.Header.Set("X-Api-Key", tt.key)
+			}
+			rec := httptest.NewRecorder()
+			mw(next).ServeHTTP(rec, r)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusUnauthorized {
+				if !strings.HasPrefix(rec.Header().Get("WWW-Authenticate"), "Basic ") {
+					t.Errorf("WWW-Authenticate = %q, want the Basic challenge", rec.Header().Get("WWW-Authenticate"))
+				}
+				return
+			}
+			switch {
+			case tt.wantID == "" && got != nil:
+				t.Errorf("principal = %+v, want none", got)
+			case tt.wantID != "" && (got == nil || got.ID != tt.wantID || got.Scheme != auth.SchemeAPIKey):
+				t.Errorf("principal = %+v, want %s via api_key", got, tt.wantID)
+			}
+		})
+	}
+}
@@ -0,0 +1,499 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+// Package config 从 YAML / JSON / TOML 文件和环境变量加载服务器、CORS、限流、超时、WebSocket 和 MCP 配置
+package config
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stones-hub/taurus-pro-http/pkg/mcp"
+	"github.com/stones-hub/taurus-pro-http/pkg/middleware"
+	"github.com/stones-hub/taurus-pro-http/pkg/server"
+	"github.com/stones-hub/taurus-pro-http/pkg/wsocket"
+)
+
+// Config 配置文件结构，字段名即配置文件中的键（三种格式相同）
+// 未配置的可选部分（指针）为 nil，表示不启用该功能
+type Config struct {
+	Server    Server     `json:"server"`
+	Cors      *Cors      `json:"cors,omitempty"`
+	RateLimit *RateLimit `json:"rate_limit,omitempty"`
+	Timeout   *Timeout   `json:"timeout,omitempty"`
+	WebSocket *WebSocket `json:"websocket,omitempty"`
+	MCP       *MCP       `json:"mcp,omitempty"`
+}
+
+// Server 服务器配置，对应 server.Config，未配置的字段使用 server.DefaultConfig
+type Server struct {
+	Addr            string   `json:"addr"`
+	ReadTimeout     Duration `json:"read_timeout"`
+	WriteTimeout    Duration `json:"write_timeout"`
+	IdleTimeout     Duration `json:"idle_timeout"`
+	ShutdownTimeout Duration `json:"shutdown_timeout"`
+	MaxHeaderBytes  int      `json:"max_header_bytes"`
+	H2C             bool     `json:"h2c"`
+	TLS             *TLS     `json:"tls,omitempty"`
+	Health          *Health  `json:"health,omitempty"`
+	Restart         *Restart `json:"restart,omitempty"`
+}
+
+// TLS HTTPS 配置，对应 server.TLSConfig
+type TLS struct {
+	CertFile       string   `json:"cert_file"`
+	KeyFile        string   `json:"key_file"`
+	ReloadInterval Duration `json:"reload_interval"`
+	// MinVersion 最低 TLS 版本，"1.2"（默认）或 "1.3"
+	MinVersion string `json:"min_version"`
+	// ClientAuth 客户端证书校验方式: "none"（默认）、"request"、"require"、"verify_if_given"、"require_and_verify"
+	ClientAuth   string `json:"client_auth"`
+	ClientCAFile string `json:"client_ca_file"`
+}
+
+// Health 健康检查端点配置，对应 server.HealthConfig
+type Health struct {
+	Timeout       Duration `json:"timeout"`
+	CacheTTL      Duration `json:"cache_ttl"`
+	ShutdownDelay Duration `json:"shutdown_delay"`
+}
+
+// Restart 平滑重启配置，对应 server.RestartConfig
+type Restart struct {
+	ReadyTimeout Duration `json:"ready_timeout"`
+	DrainTimeout Duration `json:"drain_timeout"`
+}
+
+// Cors CORS 配置，对应 middleware.CorsConfig，未配置的字段使用 middleware.DefaultCorsConfig
+type Cors struct {
+	AllowOrigins        []string         `json:"allow_origins"`
+	AllowOriginRegexps  []string         `json:"allow_origin_regexps"`
+	AllowMethods        []string         `json:"allow_methods"`
+	AllowHeaders        []string         `json:"allow_headers"`
+	ExposeHeaders       []string         `json:"expose_headers"`
+	AllowCredentials    bool             `json:"allow_credentials"`
+	AllowPrivateNetwork bool             `json:"allow_private_network"`
+	MaxAge              Duration         `json:"max_age"`
+	Routes              map[string]*Cors `json:"routes,omitempty"`
+}
+
+// RateLimitPolicy 限流策略，对应 middleware.RateLimitPolicy
+type RateLimitPolicy struct {
+	Limit  int      `json:"limit"`
+	Window Duration `json:"window"`
+	Burst  int      `json:"burst"`
+	// Algorithm "token_bucket"（默认）或 "sliding_window"
+	Algorithm string `json:"algorithm"`
+}
+
+// RateLimit 限流配置，使用内存存储；需要 Redis 存储时在代码中设置 middleware.RateLimitConfig.Store
+type RateLimit struct {
+	RateLimitPolicy
+	// KeyBy 限流 key: "ip"（默认）、"api_key"、"route" 或 "header:<请求头>"
+	KeyBy      string                     `json:"key_by"`
+	FailClosed bool                       `json:"fail_closed"`
+	Routes     map[string]RateLimitPolicy `json:"routes,omitempty"`
+}
+
+// Timeout 请求超时配置，对应 middleware.TimeoutConfig
+type Timeout struct {
+	Default    Duration            `json:"default"`
+	Routes     map[string]Duration `json:"routes,omitempty"`
+	StatusCode int                 `json:"status_code"`
+}
+
+// WebSocket WebSocket 升级配置，对应 wsocket.UpgraderConfig
+type WebSocket struct {
+	HandshakeTimeout  Duration `json:"handshake_timeout"`
+	ReadBufferSize    int      `json:"read_buffer_size"`
+	WriteBufferSize   int      `json:"write_buffer_size"`
+	EnableCompression bool     `json:"enable_compression"`
+	AllowedOrigins    []string `json:"allowed_origins"`
+}
+
+// MCP MCP 服务配置，对应 mcp.New 的选项
+type MCP struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// Transport "stdio"、"sse" 或 "streamable_http"
+	Transport string `json:"transport"`
+	// Mode "stateful" 或 "stateless"
+	Mode string `json:"mode"`
+}
+
+// Duration 配置中的时间间隔，使用 time.ParseDuration 格式，如 "30s"、"1m30s"
+type Duration time.Duration
+
+// UnmarshalText 解析 "30s" 格式的时间间隔，用于环境变量
+func (d *Duration) UnmarshalText(text []byte) error {
+	value, err := time.ParseDuration(string(text))
+	if err != nil {
+		return fmt.Errorf("invalid duration %q, expected a value like \"30s\" or \"1m30s\"", text)
+	}
+	*d = Duration(value)
+	return nil
+}
+
+// UnmarshalJSON 只接受字符串，避免数字被误解为纳秒
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return fmt.Errorf("invalid duration %s, expected a string like \"30s\" or \"1m30s\"", data)
+	}
+	return d.UnmarshalText([]byte(text))
+}
+
+// MarshalText 输出 "30s" 格式
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// Default 返回默认配置，服务器配置来自 server.DefaultConfig
+func Default() *Config {
+	return &Config{
+		Server: Server{
+			Addr:            server.DefaultConfig.Addr,
+			ReadTimeout:     Duration(server.DefaultConfig.ReadTimeout),
+			WriteTimeout:    Duration(server.DefaultConfig.WriteTimeout),
+			IdleTimeout:     Duration(server.DefaultConfig.IdleTimeout),
+			ShutdownTimeout: Duration(server.DefaultConfig.ShutdownTimeout),
+			MaxHeaderBytes:  server.DefaultConfig.MaxHeaderBytes,
+		},
+	}
+}
+
+// setDefaults 填充已启用部分中未配置的字段
+func (c *Config) setDefaults() {
+	if c.Cors != nil {
+		c.Cors.setDefaults()
+		for _, route := range c.Cors.Routes {
+			if route != nil {
+				route.setDefaults()
+			}
+		}
+	}
+	if c.RateLimit != nil && c.RateLimit.KeyBy == "" {
+		c.RateLimit.KeyBy = "ip"
+	}
+}
+
+// setDefaults 使用 middleware.DefaultCorsConfig 填充未配置的字段
+func (c *Cors) setDefaults() {
+	if len(c.AllowOrigins) == 0 && len(c.AllowOriginRegexps) == 0 {
+		c.AllowOrigins = splitList(middleware.DefaultCorsConfig.AllowOrigins)
+	}
+	if len(c.AllowMethods) == 0 {
+		c.AllowMethods = splitList(middleware.DefaultCorsConfig.AllowMethods)
+	}
+	if len(c.AllowHeaders) == 0 {
+		c.AllowHeaders = splitList(middleware.DefaultCorsConfig.AllowHeaders)
+	}
+	if c.MaxAge == 0 {
+		seconds, _ := strconv.Atoi(middleware.DefaultCorsConfig.MaxAge)
+		c.MaxAge = Duration(time.Duration(seconds) * time.Second)
+	}
+}
+
+// Validate 校验配置，返回所有错误，每个错误以配置键开头，如 "server.read_timeout: must not be negative"
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(path string, err error) {
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+		}
+	}
+
+	s := c.Server
+	if s.Addr == "" {
+		check("server.addr", errors.New("must not be empty"))
+	}
+	for _, d := range []struct {
+		path  string
+		value Duration
+	}{
+		{"server.read_timeout", s.ReadTimeout},
+		{"server.write_timeout", s.WriteTimeout},
+		{"server.idle_timeout", s.IdleTimeout},
+		{"server.shutdown_timeout", s.ShutdownTimeout},
+	} {
+		if d.value < 0 {
+			check(d.path, errors.New("must not be negative"))
+		}
+	}
+	if s.MaxHeaderBytes < 0 {
+		check("server.max_header_bytes", errors.New("must not be negative"))
+	}
+	if s.TLS != nil {
+		if s.TLS.CertFile == "" || s.TLS.KeyFile == "" {
+			check("server.tls", errors.New("cert_file and key_file are required"))
+		}
+		if _, ok := tlsVersions[s.TLS.MinVersion]; !ok {
+			check("server.tls.min_version", fmt.Errorf("unknown version %q, expected \"1.2\" or \"1.3\"", s.TLS.MinVersion))
+		}
+		if _, ok := clientAuthTypes[s.TLS.ClientAuth]; !ok {
+			check("server.tls.client_auth", fmt.Errorf("unknown value %q, expected one of none, request, require, verify_if_given, require_and_verify", s.TLS.ClientAuth))
+		}
+	}
+
+	if c.Cors != nil {
+		_, err := middleware.NewCors(c.CorsConfig())
+		check("cors", err)
+	}
+	if c.RateLimit != nil {
+		check("rate_limit", c.RateLimit.policy().Validate())
+		for route, policy := range c.RateLimit.Routes {
+			check("rate_limit.routes."+route, policy.policy().Validate())
+		}
+		_, err := c.RateLimit.keyFunc()
+		check("rate_limit.key_by", err)
+	}
+	if c.Timeout != nil {
+		switch c.Timeout.StatusCode {
+		case 0, 503, 504:
+		default:
+			check("timeout.status_code", fmt.Errorf("must be 503 or 504, got %d", c.Timeout.StatusCode))
+		}
+	}
+	if c.WebSocket != nil {
+		if c.WebSocket.ReadBufferSize < 0 || c.WebSocket.WriteBufferSize < 0 {
+			check("websocket", errors.New("read_buffer_size and write_buffer_size must not be negative"))
+		}
+		if _, err := middleware.MatchOrigins(c.WebSocket.AllowedOrigins); err != nil {
+			check("websocket.allowed_origins", err)
+		}
+	}
+	if c.MCP != nil {
+		switch mcp.Transport(c.MCP.Transport) {
+		case "", mcp.TransportStdio, mcp.TransportSSE, mcp.TransportStreamableHTTP:
+		default:
+			check("mcp.transport", fmt.Errorf("unknown transport %q, expected stdio, sse or streamable_http", c.MCP.Transport))
+		}
+		switch mcp.Mode(c.MCP.Mode) {
+		case "", mcp.ModeStateful, mcp.ModeStateless:
+		default:
+			check("mcp.mode", fmt.Errorf("unknown mode %q, expected stateful or stateless", c.MCP.Mode))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// tlsVersions 配置中的 TLS 版本，空字符串使用 server 的默认值
+var tlsVersions = map[string]uint16{
+	"":    0,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// clientAuthTypes 配置中的客户端证书校验方式
+var clientAuthTypes = map[string]tls.ClientAuthType{
+	"":                   tls.NoClientCert,
+	"none":               tls.NoClientCert,
+	"request":            tls.RequestClientCert,
+	"require":            tls.RequireAnyClientCert,
+	"verify_if_given":    tls.VerifyClientCertIfGiven,
+	"require_and_verify": tls.RequireAndVerifyClientCert,
+}
+
+// ServerConfig 转换为 server.Config，可以直接传给 server.New
+func (c *Config) ServerConfig() server.Config {
+	s := c.Server
+	config := server.Config{
+		Addr:            s.Addr,
+		ReadTimeout:     time.Duration(s.ReadTimeout),
+		WriteTimeout:    time.Duration(s.WriteTimeout),
+		IdleTimeout:     time.Duration(s.IdleTimeout),
+		ShutdownTimeout: time.Duration(s.ShutdownTimeout),
+		MaxHeaderBytes:  s.MaxHeaderBytes,
+		H2C:             s.H2C,
+	}
+	if s.TLS != nil {
+		config.TLS = &server.TLSConfig{
+			CertFile:       s.TLS.CertFile,
+			KeyFile:        s.TLS.KeyFile,
+			ReloadInterval: time.Duration(s.TLS.ReloadInterval),
+			MinVersion:     tlsVersions[s.TLS.MinVersion],
+			ClientAuth:     clientAuthTypes[s.TLS.ClientAuth],
+			ClientCAFile:   s.TLS.ClientCAFile,
+		}
+	}
+	if s.Health != nil {
+		config.Health = &server.HealthConfig{
+			Timeout:       time.Duration(s.Health.Timeout),
+			CacheTTL:      time.Duration(s.Health.CacheTTL),
+			ShutdownDelay: time.Duration(s.Health.ShutdownDelay),
+		}
+	}
+	if s.Restart != nil {
+		config.Restart = &server.RestartConfig{
+			ReadyTimeout: time.Duration(s.Restart.ReadyTimeout),
+			DrainTimeout: time.Duration(s.Restart.DrainTimeout),
+		}
+	}
+	return config
+}
+
+// CorsConfig 转换为 middleware.CorsConfig，未配置 cors 时返回 nil
+func (c *Config) CorsConfig() *middleware.CorsConfig {
+	if c.Cors == nil {
+		return nil
+	}
+	return c.Cors.corsConfig()
+}
+
+func (c *Cors) corsConfig() *middleware.CorsConfig {
+	config := &middleware.CorsConfig{
+		AllowOrigins:        strings.Join(c.AllowOrigins, ","),
+		AllowOriginRegexps:  c.AllowOriginRegexps,
+		AllowMethods:        strings.Join(c.AllowMethods, ","),
+		AllowHeaders:        strings.Join(c.AllowHeaders, ","),
+		ExposeHeaders:       strings.Join(c.ExposeHeaders, ","),
+		AllowCredentials:    c.AllowCredentials,
+		AllowPrivateNetwork: c.AllowPrivateNetwork,
+		MaxAge:              strconv.Itoa(int(time.Duration(c.MaxAge).Seconds())),
+	}
+	if len(c.Routes) > 0 {
+		config.Routes = make(map[string]*middleware.CorsConfig, len(c.Routes))
+		for route, routeConfig := range c.Routes {
+			if routeConfig != nil {
+				config.Routes[route] = routeConfig.corsConfig()
+			} else {
+				config.Routes[route] = nil
+			}
+		}
+	}
+	return config
+}
+
+// RateLimitConfig 转换为 middleware.RateLimitConfig，使用内存存储；未配置 rate_limit 时返回 nil
+func (c *Config) RateLimitConfig() *middleware.RateLimitConfig {
+	if c.RateLimit == nil {
+		return nil
+	}
+	keyFunc, _ := c.RateLimit.keyFunc()
+	return &middleware.RateLimitConfig{
+		Policy:        c.RateLimit.policy(),
+		RoutePolicies: c.RateLimit.routePolicies(),
+		KeyFunc:       keyFunc,
+		FailClosed:    c.RateLimit.FailClosed,
+	}
+}
+
+func (p RateLimitPolicy) policy() middleware.RateLimitPolicy {
+	return middleware.RateLimitPolicy{
+		Limit:     p.Limit,
+		Window:    time.Duration(p.Window),
+		Burst:     p.Burst,
+		Algorithm: middleware.RateLimitAlgorithm(p.Algorithm),
+	}
+}
+
+func (r *RateLimit) routePolicies() map[string]middleware.RateLimitPolicy {
+	routes := make(map[string]middleware.RateLimitPolicy, len(r.Routes))
+	for route, policy := range r.Routes {
+		routes[route] = policy.policy()
+	}
+	return routes
+}
+
+// keyFunc 根据 KeyBy 返回限流 key 函数
+func (r *RateLimit) keyFunc() (middleware.RateLimitKeyFunc, error) {
+	switch r.KeyBy {
+	case "", "ip":
+		return middleware.KeyByIP(), nil
+	case "api_key":
+		return middleware.KeyByAPIKey(""), nil
+	case "route":
+		return middleware.KeyByRoute(), nil
+	}
+	if header, ok := strings.CutPrefix(r.KeyBy, "header:"); ok && header != "" {
+		return middleware.KeyByHeader(header), nil
+	}
+	return nil, fmt.Errorf("unknown value %q, expected ip, api_key, route or header:<name>", r.KeyBy)
+}
+
+// TimeoutConfig 转换为 middleware.TimeoutConfig，未配置 timeout 时返回 nil
+func (c *Config) TimeoutConfig() *middleware.TimeoutConfig {
+	if c.Timeout == nil {
+		return nil
+	}
+	return &middleware.TimeoutConfig{
+		Timeout:       time.Duration(c.Timeout.Default),
+		RouteTimeouts: c.Timeout.routeTimeouts(),
+		StatusCode:    c.Timeout.StatusCode,
+	}
+}
+
+func (t *Timeout) routeTimeouts() map[string]time.Duration {
+	routes := make(map[string]time.Duration, len(t.Routes))
+	for route, d := range t.Routes {
+		routes[route] = time.Duration(d)
+	}
+	return routes
+}
+
+// UpgraderConfig 转换为 wsocket.UpgraderConfig，未配置 websocket 时返回零值（允许所有 Origin）
+func (c *Config) UpgraderConfig() wsocket.UpgraderConfig {
+	if c.WebSocket == nil {
+		return wsocket.UpgraderConfig{}
+	}
+	return wsocket.UpgraderConfig{
+		HandshakeTimeout:  time.Duration(c.WebSocket.HandshakeTimeout),
+		ReadBufferSize:    c.WebSocket.ReadBufferSize,
+		WriteBufferSize:   c.WebSocket.WriteBufferSize,
+		EnableCompression: c.WebSocket.EnableCompression,
+		AllowedOrigins:    c.WebSocket.AllowedOrigins,
+	}
+}
+
+// MCPOptions 转换为 mcp.New 的选项，未配置的字段使用 mcp 的默认值
+// 示例: mcpServer, cleanup, err := mcp.New(append(cfg.MCPOptions(), mcp.WithHttpServer(srv))...)
+func (c *Config) MCPOptions() []mcp.McpServerOption {
+	if c.MCP == nil {
+		return nil
+	}
+	var options []mcp.McpServerOption
+	if c.MCP.Name != "" {
+		options = append(options, mcp.WithName(c.MCP.Name))
+	}
+	if c.MCP.Version != "" {
+		options = append(options, mcp.WithVersion(c.MCP.Version))
+	}
+	if c.MCP.Transport != "" {
+		options = append(options, mcp.WithTransport(mcp.Transport(c.MCP.Transport)))
+	}
+	if c.MCP.Mode != "" {
+		options = append(options, mcp.WithMode(mcp.Mode(c.MCP.Mode)))
+	}
+	return options
+}
+
+// splitList 分割逗号分隔的列表，去掉空白和空项
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,252 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package config
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultEnvPrefix 默认的环境变量前缀
+const DefaultEnvPrefix = "TAURUS"
+
+// Loader 配置加载器
+//
+// 加载顺序（后面的覆盖前面的）:
+//  1. Default() 的默认值
+//  2. 按顺序读取的配置文件，格式由扩展名决定: .yaml / .yml / .json / .toml
+//  3. 环境变量，名称为前缀加上大写的配置键，如 TAURUS_SERVER_ADDR、TAURUS_SERVER_READ_TIMEOUT、TAURUS_CORS_ALLOW_ORIGINS
+//     列表使用逗号分隔，时间间隔使用 "30s" 格式；按路由的配置（routes）只能在文件中设置
+type Loader struct {
+	files     []string
+	envPrefix string
+	env       func(string) (string, bool)
+}
+
+// LoaderOption 加载器配置选项
+type LoaderOption func(*Loader)
+
+// WithFiles 添加配置文件，多个文件按顺序合并，后面的覆盖前面的（如 config.yaml + config.prod.yaml）
+func WithFiles(files ...string) LoaderOption {
+	return func(l *Loader) {
+		l.files = append(l.files, files...)
+	}
+}
+
+// WithEnvPrefix 设置环境变量前缀，默认 "TAURUS"；设置为空字符串时不读取环境变量
+func WithEnvPrefix(prefix string) LoaderOption {
+	return func(l *Loader) {
+		l.envPrefix = prefix
+	}
+}
+
+// NewLoader 创建配置加载器
+func NewLoader(options ...LoaderOption) *Loader {
+	l := &Loader{envPrefix: DefaultEnvPrefix, env: os.LookupEnv}
+	for _, option := range options {
+		option(l)
+	}
+	return l
+}
+
+// Load 从配置文件和 TAURUS_ 开头的环境变量加载配置
+// 示例:
+//
+//	cfg, err := config.Load("config.yaml")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	srv := server.New(cfg.ServerConfig())
+func Load(files ...string) (*Config, error) {
+	return NewLoader(WithFiles(files...)).Load()
+}
+
+// Load 加载并校验配置，错误信息包含文件名或环境变量名，以及出错的配置键
+func (l *Loader) Load() (*Config, error) {
+	merged := map[string]any{}
+	for _, file := range l.files {
+		values, err := readFile(file)
+		if err != nil {
+			return nil, err
+		}
+		mergeMaps(merged, values)
+	}
+
+	config := Default()
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(config); err != nil {
+		return nil, fmt.Errorf("config %s: %s", strings.Join(l.files, ", "), strings.TrimPrefix(err.Error(), "json: "))
+	}
+
+	if l.envPrefix != "" {
+		if _, err := l.applyEnv(reflect.ValueOf(config).Elem(), l.envPrefix); err != nil {
+			return nil, err
+		}
+	}
+	config.setDefaults()
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config:\n%w", err)
+	}
+	return config, nil
+}
+
+// readFile 读取配置文件为通用的 map，统一转换为 JSON 后再解码到 Config
+func readFile(file string) (map[string]any, error) {
+	ext := strings.ToLower(filepath.Ext(file))
+	switch ext {
+	case ".yaml", ".yml", ".json", ".toml":
+	default:
+		return nil, fmt.Errorf("config %s: unsupported format %q, expected .yaml, .yml, .json or .toml", file, ext)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+	values := map[string]any{}
+	switch ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	case ".json":
+		err = json.Unmarshal(data, &values)
+	case ".toml":
+		err = toml.Unmarshal(data, &values)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config %s: %w", file, err)
+	}
+	return values, nil
+}
+
+// mergeMaps 把 src 深度合并到 dst，嵌套的 map 逐键合并，其他值直接覆盖
+func mergeMaps(dst, src map[string]any) {
+	for key, value := range src {
+		srcMap, ok := value.(map[string]any)
+		if !ok {
+			dst[key] = value
+			continue
+		}
+		dstMap, ok := dst[key].(map[string]any)
+		if !ok {
+			dstMap = map[string]any{}
+			dst[key] = dstMap
+		}
+		mergeMaps(dstMap, srcMap)
+	}
+}
+
+// textUnmarshalerType encoding.TextUnmarshaler 的类型，用于识别 Duration 等自定义类型
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+// applyEnv 按 json 标签把环境变量写入结构体字段，返回是否设置了任何字段
+// 指针类型的部分（如 cors）只有存在对应的环境变量时才会创建
+func (l *Loader) applyEnv(v reflect.Value, prefix string) (bool, error) {
+	set := false
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		value := v.Field(i)
+		name := prefix
+		if !field.Anonymous {
+			tag, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if tag == "" || tag == "-" {
+				continue
+			}
+			name = prefix + "_" + strings.ToUpper(tag)
+		}
+
+		switch {
+		case field.Type.Kind() == reflect.Struct:
+			fieldSet, err := l.applyEnv(value, name)
+			if err != nil {
+				return false, err
+			}
+			set = set || fieldSet
+		case field.Type.Kind() == reflect.Pointer && field.Type.Elem().Kind() == reflect.Struct:
+			target := value
+			if value.IsNil() {
+				target = reflect.New(field.Type.Elem())
+			}
+			fieldSet, err := l.applyEnv(target.Elem(), name)
+			if err != nil {
+				return false, err
+			}
+			if fieldSet && value.IsNil() {
+				value.Set(target)
+			}
+			set = set || fieldSet
+		default:
+			raw, ok := l.env(name)
+			if !ok {
+				continue
+			}
+			if err := setField(value, raw); err != nil {
+				return false, fmt.Errorf("env %s: %w", name, err)
+			}
+			set = true
+		}
+	}
+	return set, nil
+}
+
+// setField 把环境变量的值写入字段，支持字符串、布尔、整数、时间间隔和字符串列表
+func setField(value reflect.Value, raw string) error {
+	if value.Addr().Type().Implements(textUnmarshalerType) {
+		return value.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw))
+	}
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		value.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		value.SetInt(n)
+	case reflect.Slice:
+		if value.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s", value.Type())
+		}
+		value.Set(reflect.ValueOf(splitList(raw)))
+	default:
+		// 按路由的配置（map）只能在文件中设置
+		return fmt.Errorf("unsupported type %s, set it in the config file", value.Type())
+	}
+	return nil
+}
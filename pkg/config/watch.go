@@ -0,0 +1,202 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/stones-hub/taurus-pro-http/pkg/middleware"
+	"github.com/stones-hub/taurus-pro-http/pkg/router"
+)
+
+// Watch 定期检查配置文件是否变化，变化时重新加载并调用 onChange，阻塞直到 ctx 结束
+// 加载或校验失败时记录日志并保留当前配置；interval <= 0 时默认 5 秒
+// current 为当前生效的配置，onChange 的 old 参数为上一次生效的配置，updated 为新配置
+//
+// 示例:
+//
+//	dynamic, _ := config.NewDynamic(cfg)
+//	go loader.Watch(ctx, 5*time.Second, cfg, func(old, updated *config.Config) {
+//		if err := dynamic.Update(updated); err != nil {
+//			log.Printf("apply config failed: %v", err)
+//		}
+//	})
+func (l *Loader) Watch(ctx context.Context, interval time.Duration, current *Config, onChange func(old, updated *Config)) {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	stamp, err := fileStamp(l.files...)
+	if err != nil {
+		log.Printf("[Config] watch failed: %v \n", err)
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		next, err := fileStamp(l.files...)
+		if err != nil {
+			// 编辑器保存文件时可能短暂不存在，下个周期再检查
+			log.Printf("[Config] watch failed: %v \n", err)
+			continue
+		}
+		if next == stamp {
+			continue
+		}
+		stamp = next
+
+		config, err := l.Load()
+		if err != nil {
+			log.Printf("[Config] reload failed, keep current config: %v \n", err)
+			continue
+		}
+		for _, section := range restartRequired(current, config) {
+			log.Printf("[Config] %s changed, restart required to take effect \n", section)
+		}
+		log.Printf("[Config] reloaded from %s \n", strings.Join(l.files, ", "))
+		onChange(current, config)
+		current = config
+	}
+}
+
+// fileStamp 文件修改时间和大小，用于判断文件是否变化
+func fileStamp(files ...string) (string, error) {
+	var b strings.Builder
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return "", fmt.Errorf("stat %s: %w", file, err)
+		}
+		fmt.Fprintf(&b, "%d:%d;", info.ModTime().UnixNano(), info.Size())
+	}
+	return b.String(), nil
+}
+
+// restartRequired 返回变化后需要重启才能生效的配置
+func restartRequired(old, updated *Config) []string {
+	var sections []string
+	if !reflect.DeepEqual(old.Server, updated.Server) {
+		sections = append(sections, "server")
+	}
+	if !reflect.DeepEqual(old.WebSocket, updated.WebSocket) {
+		sections = append(sections, "websocket")
+	}
+	if !reflect.DeepEqual(old.MCP, updated.MCP) {
+		sections = append(sections, "mcp")
+	}
+	if (old.Cors == nil) != (updated.Cors == nil) {
+		sections = append(sections, "cors (enable / disable)")
+	}
+	if (old.Timeout == nil) != (updated.Timeout == nil) {
+		sections = append(sections, "timeout (enable / disable)")
+	} else if old.Timeout != nil && old.Timeout.StatusCode != updated.Timeout.StatusCode {
+		sections = append(sections, "timeout.status_code")
+	}
+	if (old.RateLimit == nil) != (updated.RateLimit == nil) {
+		sections = append(sections, "rate_limit (enable / disable)")
+	} else if old.RateLimit != nil && (old.RateLimit.KeyBy != updated.RateLimit.KeyBy || old.RateLimit.FailClosed != updated.RateLimit.FailClosed) {
+		sections = append(sections, "rate_limit.key_by / rate_limit.fail_closed")
+	}
+	return sections
+}
+
+// Dynamic 根据配置创建的可热更新中间件: CORS 允许的域名、限流策略和请求超时
+// 启动时未配置的部分为 nil，之后启用需要重启
+type Dynamic struct {
+	Cors      *middleware.Cors
+	RateLimit *middleware.RateLimiter
+	Timeout   *middleware.Timeout
+}
+
+// NewDynamic 根据配置创建可热更新的中间件
+func NewDynamic(config *Config) (*Dynamic, error) {
+	d := &Dynamic{}
+	var err error
+	if cors := config.CorsConfig(); cors != nil {
+		if d.Cors, err = middleware.NewCors(cors); err != nil {
+			return nil, fmt.Errorf("cors: %w", err)
+		}
+	}
+	if rateLimit := config.RateLimitConfig(); rateLimit != nil {
+		if d.RateLimit, err = middleware.NewRateLimiter(*rateLimit); err != nil {
+			return nil, fmt.Errorf("rate_limit: %w", err)
+		}
+	}
+	if timeout := config.TimeoutConfig(); timeout != nil {
+		if d.Timeout, err = middleware.NewTimeout(*timeout); err != nil {
+			d.Close()
+			return nil, fmt.Errorf("timeout: %w", err)
+		}
+	}
+	return d, nil
+}
+
+// Close 释放限流器使用的内存存储，服务关闭后调用
+func (d *Dynamic) Close() {
+	if d.RateLimit != nil {
+		d.RateLimit.Close()
+	}
+}
+
+// Middleware 返回已启用的中间件，顺序为 CORS、限流、超时，可以作为全局或路由组中间件
+func (d *Dynamic) Middleware() []router.MiddlewareFunc {
+	var middlewares []router.MiddlewareFunc
+	if d.Cors != nil {
+		middlewares = append(middlewares, d.Cors.Middleware())
+	}
+	if d.RateLimit != nil {
+		middlewares = append(middlewares, d.RateLimit.Middleware())
+	}
+	if d.Timeout != nil {
+		middlewares = append(middlewares, d.Timeout.Middleware())
+	}
+	return middlewares
+}
+
+// Update 使用新配置更新中间件，对之后的请求生效；启动时未启用的部分被忽略
+// 某一部分更新失败时不影响其他部分，返回所有错误
+func (d *Dynamic) Update(config *Config) error {
+	var errs []error
+	if d.Cors != nil && config.Cors != nil {
+		if err := d.Cors.Update(config.CorsConfig()); err != nil {
+			errs = append(errs, fmt.Errorf("cors: %w", err))
+		}
+	}
+	if d.RateLimit != nil && config.RateLimit != nil {
+		rateLimit := config.RateLimitConfig()
+		if err := d.RateLimit.Update(rateLimit.Policy, rateLimit.RoutePolicies); err != nil {
+			errs = append(errs, fmt.Errorf("rate_limit: %w", err))
+		}
+	}
+	if d.Timeout != nil && config.Timeout != nil {
+		timeout := config.TimeoutConfig()
+		d.Timeout.Update(timeout.Timeout, timeout.RouteTimeouts)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,334 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package httpx
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const (
+	HeaderRequestID     = "X-Request-ID"  // 请求 ID 头
+	HeaderTraceParent   = "traceparent"   // W3C Trace Context 请求头
+	HeaderTraceState    = "tracestate"    // W3C Trace Context 厂商扩展头
+	HeaderTraceResponse = "traceresponse" // W3C Trace Context Level 2 响应头
+)
+
+// contextKey 请求上下文中使用的键类型，避免与其他包冲突
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	traceContextKey
+	remoteInfoKey
+	requestRecordKey
+)
+
+// TraceContext W3C Trace Context 信息
+// 参考: https://www.w3.org/TR/trace-context/
+type TraceContext struct {
+	TraceID      string // 32 位十六进制 trace id
+	SpanID       string // 当前服务处理本次请求的 span id，16 位十六进制
+	ParentSpanID string // 上游传入的 span id，没有上游时为空
+	Flags        byte   // trace flags，01 表示采样
+	TraceState   string // tracestate 原样透传
+}
+
+// Sampled 是否被采样
+func (tc TraceContext) Sampled() bool {
+	return tc.Flags&0x01 == 0x01
+}
+
+// TraceParent 生成 traceparent 头的值，使用当前 span id 作为下游的 parent-id
+func (tc TraceContext) TraceParent() string {
+	return fmt.Sprintf("00-%s-%s-%02x", tc.TraceID, tc.SpanID, tc.Flags)
+}
+
+// ParseTraceParent 解析 traceparent 头
+// 格式: version-traceid-parentid-flags，如 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01
+func ParseTraceParent(value string) (TraceContext, error) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 {
+		return TraceContext{}, fmt.Errorf("invalid traceparent: %q", value)
+	}
+	version, traceID, parentID, flags := parts[0], parts[1], parts[2], parts[3]
+	if len(version) != 2 || !isLowerHex(version) || version == "ff" {
+		return TraceContext{}, fmt.Errorf("invalid traceparent version: %q", version)
+	}
+	// version 00 必须恰好 4 段，更高版本允许追加字段
+	if version == "00" && len(parts) != 4 {
+		return TraceContext{}, fmt.Errorf("invalid traceparent: %q", value)
+	}
+	if len(traceID) != 32 || !isLowerHex(traceID) || isAllZero(traceID) {
+		return TraceContext{}, fmt.Errorf("invalid trace id: %q", traceID)
+	}
+	if len(parentID) != 16 || !isLowerHex(parentID) || isAllZero(parentID) {
+		return TraceContext{}, fmt.Errorf("invalid parent id: %q", parentID)
+	}
+	if len(flags) != 2 || !isLowerHex(flags) {
+		return TraceContext{}, fmt.Errorf("invalid trace flags: %q", flags)
+	}
+	b, _ := hex.DecodeString(flags)
+	return TraceContext{TraceID: traceID, ParentSpanID: parentID, Flags: b[0]}, nil
+}
+
+// NewTraceID 生成新的 32 位十六进制 trace id
+func NewTraceID() string {
+	return randomHex(16)
+}
+
+// NewSpanID 生成新的 16 位十六进制 span id
+func NewSpanID() string {
+	return randomHex(8)
+}
+
+// WithRequestID 将请求 ID 写入上下文，同时回填到外层的请求记录
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	if record := requestRecordFromContext(ctx); record != nil {
+		record.mu.Lock()
+		record.requestID = requestID
+		record.mu.Unlock()
+	}
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestIDFromContext 从上下文中获取请求 ID
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// GetRequestID 获取请求 ID，需要 middleware.TraceMiddleware 先执行
+func GetRequestID(r *http.Request) string {
+	return RequestIDFromContext(r.Context())
+}
+
+// WithTraceContext 将 trace 信息写入上下文，同时回填到外层的请求记录
+func WithTraceContext(ctx context.Context, tc TraceContext) context.Context {
+	if record := requestRecordFromContext(ctx); record != nil {
+		record.mu.Lock()
+		record.traceID = tc.TraceID
+		record.mu.Unlock()
+	}
+	return context.WithValue(ctx, traceContextKey, tc)
+}
+
+// TraceContextFromContext 从上下文中获取 trace 信息
+func TraceContextFromContext(ctx context.Context) (TraceContext, bool) {
+	tc, ok := ctx.Value(traceContextKey).(TraceContext)
+	return tc, ok
+}
+
+// GetTraceID 获取当前请求的 trace id，没有时返回空字符串
+func GetTraceID(r *http.Request) string {
+	tc, _ := TraceContextFromContext(r.Context())
+	return tc.TraceID
+}
+
+// RemoteInfo 经过可信代理解析后的客户端信息，由 middleware.NewProxyMiddleware 写入上下文
+type RemoteInfo struct {
+	ClientIP string // 真实客户端 IP
+	Scheme   string // 客户端使用的协议，http 或 https
+	Host     string // 客户端请求的 Host
+}
+
+// WithRemoteInfo 将客户端信息写入上下文
+func WithRemoteInfo(ctx context.Context, info RemoteInfo) context.Context {
+	return context.WithValue(ctx, remoteInfoKey, info)
+}
+
+// RemoteInfoFromContext 从上下文中获取客户端信息
+func RemoteInfoFromContext(ctx context.Context) (RemoteInfo, bool) {
+	info, ok := ctx.Value(remoteInfoKey).(RemoteInfo)
+	return info, ok
+}
+
+// ClientIP 获取客户端 IP
+// 挂载了 middleware.NewProxyMiddleware 时返回经过可信代理解析的 IP，否则返回 RemoteAddr 中的 IP
+func ClientIP(r *http.Request) string {
+	if info, ok := RemoteInfoFromContext(r.Context()); ok && info.ClientIP != "" {
+		return info.ClientIP
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// Scheme 获取客户端使用的协议，http 或 https
+// 挂载了 middleware.NewProxyMiddleware 时使用可信代理传递的协议，否则根据连接是否为 TLS 判断
+func Scheme(r *http.Request) string {
+	if info, ok := RemoteInfoFromContext(r.Context()); ok && info.Scheme != "" {
+		return info.Scheme
+	}
+	if r.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// Host 获取客户端请求的 Host
+// 挂载了 middleware.NewProxyMiddleware 时使用可信代理传递的 Host，否则返回 r.Host
+func Host(r *http.Request) string {
+	if info, ok := RemoteInfoFromContext(r.Context()); ok && info.Host != "" {
+		return info.Host
+	}
+	return r.Host
+}
+
+// RequestRecord 请求处理过程中由内层中间件回填、供外层中间件读取的信息
+// 上下文只能向内传递，外层中间件（如访问日志）无法读取内层写入上下文的值；
+// 外层在调用 next 前通过 WithRequestRecord 放入记录，内层写入后，外层在 next 返回后读取，与中间件的注册顺序无关
+type RequestRecord struct {
+	mu        sync.Mutex
+	requestID string
+	traceID   string
+	user      string
+}
+
+// WithRequestRecord 在上下文中放入一个空的请求记录
+func WithRequestRecord(ctx context.Context) (context.Context, *RequestRecord) {
+	record := &RequestRecord{}
+	return context.WithValue(ctx, requestRecordKey, record), record
+}
+
+// requestRecordFromContext 从上下文中获取请求记录，外层没有放入时返回 nil
+func requestRecordFromContext(ctx context.Context) *RequestRecord {
+	record, _ := ctx.Value(requestRecordKey).(*RequestRecord)
+	return record
+}
+
+// RecordUser 将认证后的用户写入请求记录，由 auth 中间件调用
+func RecordUser(ctx context.Context, user string) {
+	if record := requestRecordFromContext(ctx); record != nil {
+		record.mu.Lock()
+		record.user = user
+		record.mu.Unlock()
+	}
+}
+
+// User 认证后的用户，未认证时为空
+func (r *RequestRecord) User() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.user
+}
+
+// RequestID 内层 TraceMiddleware 生成或透传的请求 ID
+func (r *RequestRecord) RequestID() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.requestID
+}
+
+// TraceID 内层 TraceMiddleware 或 OpenTelemetry 中间件设置的 trace id
+func (r *RequestRecord) TraceID() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.traceID
+}
+
+// contextResponseWriter 携带请求上下文的 ResponseWriter
+type contextResponseWriter struct {
+	http.ResponseWriter
+	ctx context.Context
+}
+
+// WithResponseContext 返回携带 ctx 的 ResponseWriter
+// SendResponse 等函数只接收 ResponseWriter，通过它从请求上下文读取请求 ID 和 trace id；
+// 由 TraceMiddleware 调用，替换了 ResponseWriter 且不提供 Unwrap 的中间件（如超时中间件）需要再次调用
+func WithResponseContext(w http.ResponseWriter, ctx context.Context) http.ResponseWriter {
+	return &contextResponseWriter{ResponseWriter: w, ctx: ctx}
+}
+
+// Flush 透传 Flush，SSE 依赖此方法
+func (w *contextResponseWriter) Flush() {
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Hijack 透传 Hijack，WebSocket 升级依赖此方法
+func (w *contextResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+// Unwrap 供 http.ResponseController 获取原始 ResponseWriter
+func (w *contextResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// responseContext 沿 Unwrap 链查找 WithResponseContext 携带的请求上下文，找不到时返回 nil
+func responseContext(w http.ResponseWriter) context.Context {
+	for {
+		switch rw := w.(type) {
+		case *contextResponseWriter:
+			return rw.ctx
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = rw.Unwrap()
+		default:
+			return nil
+		}
+	}
+}
+
+// InjectTraceHeaders 将上下文中的请求 ID 和 trace 信息注入到下游请求头中
+// 用于服务间调用时透传链路信息
+func InjectTraceHeaders(ctx context.Context, header http.Header) {
+	if id := RequestIDFromContext(ctx); id != "" {
+		header.Set(HeaderRequestID, id)
+	}
+	if tc, ok := TraceContextFromContext(ctx); ok && tc.TraceID != "" {
+		header.Set(HeaderTraceParent, tc.TraceParent())
+		if tc.TraceState != "" {
+			header.Set(HeaderTraceState, tc.TraceState)
+		}
+	}
+}
+
+// randomHex 生成 n 字节的随机十六进制字符串
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("crypto/rand failed: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
+
+// isLowerHex 是否全部为小写十六进制字符
+func isLowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c >= '0' && c <= '9') && !(c >= 'a' && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// isAllZero 是否全部为 0
+func isAllZero(s string) bool {
+	return strings.Trim(s, "0") == ""
+}
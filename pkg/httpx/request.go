@@ -221,6 +221,21 @@ func GetPathParamDefault(r *http.Request, key, defaultValue string) string {
 	return value
 }
 
+// RoutePattern 获取请求匹配到的路由模式（去掉方法前缀和 host）
+// 示例: 路由 "GET /video/{userid}/get" 返回 "/video/{userid}/get"
+// 只能在 ServeMux 匹配之后（路由处理器或路由中间件中）获取，未匹配时返回空字符串
+// 用于日志、指标等场景，避免使用原始路径导致基数膨胀
+func RoutePattern(r *http.Request) string {
+	pattern := r.Pattern
+	if idx := strings.IndexByte(pattern, ' '); idx >= 0 {
+		pattern = strings.TrimSpace(pattern[idx+1:])
+	}
+	if idx := strings.IndexByte(pattern, '/'); idx > 0 {
+		pattern = pattern[idx:]
+	}
+	return pattern
+}
+
 // SaveUploadFiles 将文件数据存储到指定目录
 func SaveUploadFiles(files []*multipart.FileHeader, destDir string) error {
 	for _, fileHeader := range files {
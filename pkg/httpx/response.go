@@ -31,9 +31,11 @@ import (
 
 // Response is a struct for standardizing API responses
 type Response struct {
-	Code    int         `json:"code"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data,omitempty"`
+	Code      int         `json:"code"`
+	Message   string      `json:"message"`
+	Data      interface{} `json:"data,omitempty"`
+	RequestID string      `json:"request_id,omitempty" xml:",omitempty"` // 由 TraceMiddleware 设置的请求 ID
+	TraceID   string      `json:"trace_id,omitempty" xml:",omitempty"`   // 由 TraceMiddleware 设置的 trace id
 }
 
 const (
@@ -60,7 +62,19 @@ var errorMessages = map[int]string{
 // SendResponse formats and sends a response with a flexible content type
 func SendResponse(w http.ResponseWriter, code int, data interface{}, headers map[string]string) {
 	httpStatus, message := getResponseStatusAndMessage(code)
+	writeResponse(w, httpStatus, code, message, data, headers)
+}
+
+// SendResponseWithStatus 与 SendResponse 使用相同的响应格式，但强制使用 httpStatus 作为 HTTP 状态码
+// SendResponse 对 errorMessages 中的错误码返回 HTTP 200，而限流、过载、超时等场景需要真实的状态码
+// （如 503 + Retry-After），以便负载均衡器和客户端正确处理
+func SendResponseWithStatus(w http.ResponseWriter, httpStatus int, data interface{}, headers map[string]string) {
+	_, message := getResponseStatusAndMessage(httpStatus)
+	writeResponse(w, httpStatus, httpStatus, message, data, headers)
+}
 
+// writeResponse 写入响应头和响应体
+func writeResponse(w http.ResponseWriter, httpStatus int, code int, message string, data interface{}, headers map[string]string) {
 	// 如果 headers 为 nil，初始化为一个空的 map
 	if headers == nil {
 		headers = make(map[string]string)
@@ -76,15 +90,16 @@ func SendResponse(w http.ResponseWriter, code int, data interface{}, headers map
 	}
 
 	contentType := headers["Content-Type"]
+	requestID, traceID := responseTraceIDs(w)
 
 	// 写入响应头
 	w.WriteHeader(httpStatus)
 
 	// 根据不同类型的 contentType 前缀，进行不同的数据处理, 支持 xml/json/text/html
 	if strings.HasPrefix(contentType, "application/json") {
-		json.NewEncoder(w).Encode(Response{Code: code, Message: message, Data: data})
+		json.NewEncoder(w).Encode(Response{Code: code, Message: message, Data: data, RequestID: requestID, TraceID: traceID})
 	} else if strings.HasPrefix(contentType, "application/xml") {
-		xml.NewEncoder(w).Encode(Response{Code: code, Message: message, Data: data})
+		xml.NewEncoder(w).Encode(Response{Code: code, Message: message, Data: data, RequestID: requestID, TraceID: traceID})
 	} else if strings.HasPrefix(contentType, "text/plain") || strings.HasPrefix(contentType, "text/html") {
 		if str, ok := data.(string); ok {
 			w.Write([]byte(str))
@@ -99,8 +114,19 @@ func SendResponse(w http.ResponseWriter, code int, data interface{}, headers map
 		}
 	} else {
 		// 默认返回 json
-		json.NewEncoder(w).Encode(Response{Code: code, Message: message, Data: data})
+		json.NewEncoder(w).Encode(Response{Code: code, Message: message, Data: data, RequestID: requestID, TraceID: traceID})
+	}
+}
+
+// responseTraceIDs 从请求上下文中获取 TraceMiddleware 设置的请求 ID 和 trace id
+// 请求 ID 头可以自定义（TraceConfig.RequestIDHeader），因此不从响应头读取
+func responseTraceIDs(w http.ResponseWriter) (requestID, traceID string) {
+	ctx := responseContext(w)
+	if ctx == nil {
+		return "", ""
 	}
+	tc, _ := TraceContextFromContext(ctx)
+	return RequestIDFromContext(ctx), tc.TraceID
 }
 
 // CustomJSONResponse sends a custom JSON response with a specified status code
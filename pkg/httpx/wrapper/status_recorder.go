@@ -0,0 +1,99 @@
+package wrapper
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// StatusRecorder 记录响应状态码和写入字节数
+// 与 ResponseWrapper 不同，它不缓冲响应体，数据直接写给客户端，
+// 同时透传 Flush/Hijack，保证 SSE 和 WebSocket 升级不受影响
+type StatusRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+	hijacked    bool
+}
+
+// NewStatusRecorder 创建响应记录器
+func NewStatusRecorder(w http.ResponseWriter) *StatusRecorder {
+	return &StatusRecorder{ResponseWriter: w}
+}
+
+// WriteHeader 记录状态码
+func (rw *StatusRecorder) WriteHeader(code int) {
+	if rw.wroteHeader {
+		return
+	}
+	// 1xx 是信息性响应，后面还会有最终状态码
+	if code >= 100 && code < 200 && code != http.StatusSwitchingProtocols {
+		rw.ResponseWriter.WriteHeader(code)
+		return
+	}
+	rw.status = code
+	rw.wroteHeader = true
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+// Write 记录写入字节数
+func (rw *StatusRecorder) Write(b []byte) (int, error) {
+	if !rw.wroteHeader {
+		rw.WriteHeader(http.StatusOK)
+	}
+	n, err := rw.ResponseWriter.Write(b)
+	rw.bytes += int64(n)
+	return n, err
+}
+
+// Flush 透传 Flush，SSE 依赖此方法
+func (rw *StatusRecorder) Flush() {
+	if !rw.wroteHeader {
+		rw.WriteHeader(http.StatusOK)
+	}
+	if f, ok := rw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack 透传 Hijack，WebSocket 升级依赖此方法
+func (rw *StatusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := rw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("underlying ResponseWriter does not implement http.Hijacker")
+	}
+	conn, brw, err := h.Hijack()
+	if err == nil {
+		rw.hijacked = true
+		if !rw.wroteHeader {
+			rw.status = http.StatusSwitchingProtocols
+			rw.wroteHeader = true
+		}
+	}
+	return conn, brw, err
+}
+
+// Hijacked 连接是否已被接管（如 WebSocket 升级）
+func (rw *StatusRecorder) Hijacked() bool {
+	return rw.hijacked
+}
+
+// Unwrap 供 http.ResponseController 获取原始 ResponseWriter
+func (rw *StatusRecorder) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// Status 返回响应状态码，处理器未写入任何内容时视为 200
+func (rw *StatusRecorder) Status() int {
+	if rw.status == 0 {
+		return http.StatusOK
+	}
+	return rw.status
+}
+
+// BytesWritten 返回响应体字节数
+func (rw *StatusRecorder) BytesWritten() int64 {
+	return rw.bytes
+}
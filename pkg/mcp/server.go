@@ -22,6 +22,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/http"
 
 	"github.com/stones-hub/taurus-pro-http/pkg/router"
 	httpServer "github.com/stones-hub/taurus-pro-http/pkg/server"
@@ -136,7 +137,7 @@ func New(options ...McpServerOption) (*MCPServer, func(), error) {
 	case *transport.SSEHandler:
 		opts.httpServer.AddRouter(router.Router{
 			Path:       "/sse",
-			Handler:    h.HandleSSE(),
+			Handler:    httpServer.LongLivedHandler(h.HandleSSE()), // 服务关闭时结束 SSE 流
 			Middleware: nil,
 		})
 
@@ -148,7 +149,7 @@ func New(options ...McpServerOption) (*MCPServer, func(), error) {
 	case *transport.StreamableHTTPHandler:
 		opts.httpServer.AddRouter(router.Router{
 			Path:       "/mcp",
-			Handler:    h.HandleMCP(),
+			Handler:    longLivedGet(h.HandleMCP()),
 			Middleware: nil,
 		})
 	default:
@@ -169,6 +170,21 @@ func (s *MCPServer) Shutdown(ctx context.Context) error {
 	return s.server.Shutdown(ctx)
 }
 
+var _ httpServer.Service = (*MCPServer)(nil)
+
+// Start 实现 server.Service，可以和 HTTP 服务一起交给 server.Lifecycle 管理
+// stdio transport 在后台运行，退出时把错误写入 errChan；其他 transport 的路由已经注册到 HTTP 服务，不需要单独启动
+func (s *MCPServer) Start(errChan chan error) {
+	if s.Transport != TransportStdio {
+		return
+	}
+	go func() {
+		if err := s.server.Run(); err != nil {
+			httpServer.ReportError(errChan, err)
+		}
+	}()
+}
+
 // if you want to run stdio transport, you should run the server in the main thread
 // for stdio transport, run the server in the main thread
 func (s *MCPServer) Run() error {
@@ -178,6 +194,19 @@ func (s *MCPServer) Run() error {
 	return nil
 }
 
+// longLivedGet 只把 GET 请求（服务端推送的 SSE 流）登记为长连接，服务关闭时结束推送
+// POST 请求（工具调用）按普通请求处理，关闭时等待其正常完成
+func longLivedGet(next http.Handler) http.Handler {
+	stream := httpServer.LongLivedHandler(next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			stream.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 func getTransport(transportName Transport, stateMode transport.StateMode) (transport.ServerTransport, interface{}) {
 	var err error
 	var t transport.ServerTransport
@@ -210,8 +239,13 @@ func getTransport(transportName Transport, stateMode transport.StateMode) (trans
 	return t, handler
 }
 
-func (s *MCPServer) RegisterTool(tool *protocol.Tool, handler server.ToolHandlerFunc) {
-	s.server.RegisterTool(tool, handler)
+func (s *MCPServer) RegisterTool(tool *protocol.Tool, handler server.ToolHandlerFunc, middlewares ...server.ToolMiddleware) {
+	s.server.RegisterTool(tool, handler, middlewares...)
+}
+
+// Use 注册全局工具中间件，作用于所有工具调用，如 telemetry.Instrumentation.ToolMiddleware()
+func (s *MCPServer) Use(middlewares ...server.ToolMiddleware) {
+	s.server.Use(middlewares...)
 }
 
 func (s *MCPServer) UnregisterTool(name string) {
@@ -0,0 +1,269 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+// Package metrics 提供 Prometheus 指标采集和 /metrics 端点
+package metrics
+
+import (
+	"net/http"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/stones-hub/taurus-pro-http/pkg/httpx"
+	"github.com/stones-hub/taurus-pro-http/pkg/httpx/wrapper"
+	"github.com/stones-hub/taurus-pro-http/pkg/router"
+	"github.com/stones-hub/taurus-pro-http/pkg/server"
+	"github.com/stones-hub/taurus-pro-http/pkg/wsocket"
+)
+
+// unmatchedRoute 未匹配到路由模式时使用的标签值，避免使用原始路径导致基数膨胀
+const unmatchedRoute = "unmatched"
+
+// Metrics Prometheus 指标集合
+type Metrics struct {
+	namespace string
+	buckets   []float64
+	registry  *prometheus.Registry
+
+	requestsTotal    *prometheus.CounterVec
+	requestDuration  *prometheus.HistogramVec
+	requestsInFlight *prometheus.GaugeVec
+	responseSize     *prometheus.HistogramVec
+}
+
+// Option 指标配置选项
+type Option func(*Metrics)
+
+// WithNamespace 设置指标命名空间（前缀），默认 "taurus"
+func WithNamespace(namespace string) Option {
+	return func(m *Metrics) {
+		m.namespace = namespace
+	}
+}
+
+// WithBuckets 设置请求耗时直方图的分桶（秒），默认 prometheus.DefBuckets
+func WithBuckets(buckets []float64) Option {
+	return func(m *Metrics) {
+		m.buckets = buckets
+	}
+}
+
+// WithRegistry 使用自定义的 Registry，默认创建独立的 Registry（不污染全局 DefaultRegisterer）
+func WithRegistry(registry *prometheus.Registry) Option {
+	return func(m *Metrics) {
+		m.registry = registry
+	}
+}
+
+// New 创建指标集合，同时注册 Go 运行时和进程指标
+func New(options ...Option) *Metrics {
+	m := &Metrics{
+		namespace: "taurus",
+		buckets:   prometheus.DefBuckets,
+	}
+	for _, option := range options {
+		option(m)
+	}
+	if m.registry == nil {
+		m.registry = prometheus.NewRegistry()
+	}
+
+	m.requestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: m.namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Total number of HTTP requests by method, route pattern and status code.",
+	}, []string{"method", "route", "code"})
+
+	m.requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: m.namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency in seconds by method and route pattern.",
+		Buckets:   m.buckets,
+	}, []string{"method", "route"})
+
+	m.requestsInFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: m.namespace,
+		Subsystem: "http",
+		Name:      "requests_in_flight",
+		Help:      "Number of HTTP requests currently being served by route pattern.",
+	}, []string{"route"})
+
+	m.responseSize = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: m.namespace,
+		Subsystem: "http",
+		Name:      "response_size_bytes",
+		Help:      "HTTP response size in bytes by method and route pattern.",
+		Buckets:   prometheus.ExponentialBuckets(100, 10, 7),
+	}, []string{"method", "route"})
+
+	m.registry.MustRegister(
+		m.requestsTotal,
+		m.requestDuration,
+		m.requestsInFlight,
+		m.responseSize,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// Registry 返回底层 Registry，可用于注册业务自定义指标
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// Middleware 记录请求数、耗时、响应大小和进行中的请求数
+// 标签使用路由模式（如 /user/{id}）而非原始路径，需要作为路由中间件挂载
+func (m *Metrics) Middleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := httpx.RoutePattern(r)
+			if route == "" {
+				route = unmatchedRoute
+			}
+			method := normalizeMethod(r.Method)
+
+			inFlight := m.requestsInFlight.WithLabelValues(route)
+			inFlight.Inc()
+			defer inFlight.Dec()
+
+			start := time.Now()
+			rec := wrapper.NewStatusRecorder(w)
+			next.ServeHTTP(rec, r)
+
+			m.requestsTotal.WithLabelValues(method, route, strconv.Itoa(rec.Status())).Inc()
+			m.requestDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+			m.responseSize.WithLabelValues(method, route).Observe(float64(rec.BytesWritten()))
+		})
+	}
+}
+
+// Handler 返回 Prometheus 文本格式的指标处理器
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{
+		Registry:          m.registry,
+		EnableOpenMetrics: true,
+	})
+}
+
+// Router 返回可以通过 server.Server.AddRouter 挂载的指标路由
+// 示例: srv.AddRouter(m.Router("/metrics"))
+func (m *Metrics) Router(path string, middlewares ...router.MiddlewareFunc) router.Router {
+	if path == "" {
+		path = "/metrics"
+	}
+	return router.Router{
+		Path:       "GET " + path,
+		Handler:    m.Handler(),
+		Middleware: middlewares,
+	}
+}
+
+// RegisterWebSocketHub 注册 WebSocketHub 的房间数和连接数指标
+// 指标在采集时实时读取，不需要额外的协程；name 用于区分多个 Hub
+func (m *Metrics) RegisterWebSocketHub(name string, hub *wsocket.WebSocketHub) error {
+	labels := prometheus.Labels{"hub": name}
+	rooms := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   m.namespace,
+		Subsystem:   "websocket",
+		Name:        "rooms",
+		Help:        "Number of websocket rooms.",
+		ConstLabels: labels,
+	}, func() float64 { return float64(hub.RoomCount()) })
+	connections := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   m.namespace,
+		Subsystem:   "websocket",
+		Name:        "connections",
+		Help:        "Number of websocket connections across all rooms.",
+		ConstLabels: labels,
+	}, func() float64 { return float64(hub.ClientCount()) })
+
+	if err := m.registry.Register(rooms); err != nil {
+		return err
+	}
+	return m.registry.Register(connections)
+}
+
+// RegisterServer 注册服务器生命周期指标
+// taurus_server_info: 常量 1，标签为监听地址和 Go 版本
+// taurus_server_start_time_seconds: 服务启动时间（Unix 秒），未启动时为 0
+// taurus_server_uptime_seconds: 服务运行时长
+func (m *Metrics) RegisterServer(name string, srv *server.Server) error {
+	labels := prometheus.Labels{"server": name}
+	info := prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: m.namespace,
+		Subsystem: "server",
+		Name:      "info",
+		Help:      "Server information, value is always 1.",
+		ConstLabels: prometheus.Labels{
+			"server":     name,
+			"addr":       srv.GetConfig().Addr,
+			"go_version": runtime.Version(),
+		},
+	})
+	info.Set(1)
+
+	startTime := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   m.namespace,
+		Subsystem:   "server",
+		Name:        "start_time_seconds",
+		Help:        "Server start time since unix epoch in seconds.",
+		ConstLabels: labels,
+	}, func() float64 {
+		if t := srv.StartTime(); !t.IsZero() {
+			return float64(t.UnixNano()) / 1e9
+		}
+		return 0
+	})
+	uptime := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   m.namespace,
+		Subsystem:   "server",
+		Name:        "uptime_seconds",
+		Help:        "Server uptime in seconds.",
+		ConstLabels: labels,
+	}, func() float64 {
+		if t := srv.StartTime(); !t.IsZero() {
+			return time.Since(t).Seconds()
+		}
+		return 0
+	})
+
+	for _, c := range []prometheus.Collector{info, startTime, uptime} {
+		if err := m.registry.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// normalizeMethod 非标准方法统一记为 OTHER，避免基数膨胀
+func normalizeMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	default:
+		return "OTHER"
+	}
+}
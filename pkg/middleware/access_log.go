@@ -0,0 +1,380 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stones-hub/taurus-pro-http/pkg/auth"
+	"github.com/stones-hub/taurus-pro-http/pkg/httpx"
+	"github.com/stones-hub/taurus-pro-http/pkg/httpx/wrapper"
+)
+
+// AccessLogFormat 访问日志输出格式
+type AccessLogFormat string
+
+const (
+	AccessLogFormatJSON     AccessLogFormat = "json"     // slog JSON 格式，适合日志采集系统
+	AccessLogFormatLogfmt   AccessLogFormat = "logfmt"   // key=value 格式，适合人眼阅读和 grep
+	AccessLogFormatCombined AccessLogFormat = "combined" // Apache combined 格式，兼容传统日志分析工具
+)
+
+// redactedValue 脱敏后的占位值
+const redactedValue = "REDACTED"
+
+// AccessLogConfig 访问日志配置
+type AccessLogConfig struct {
+	// Logger 自定义 slog.Logger，设置后忽略 Format 和 Output
+	Logger *slog.Logger
+	// Format 日志格式，默认 json
+	Format AccessLogFormat
+	// Output 日志输出位置，默认 os.Stdout
+	Output io.Writer
+	// SampleRate 采样率，取值 (0, 1]，0 表示不采样（全部记录）
+	// 状态码 >= 500 的请求始终记录，不受采样影响
+	SampleRate float64
+	// SkipPaths 不记录日志的路径，支持以 * 结尾的前缀匹配，如 "/static/*"
+	SkipPaths []string
+	// LogHeaders 需要记录的请求头，如 "Referer"、"X-Forwarded-For"
+	LogHeaders []string
+	// RedactHeaders 需要脱敏的请求头（大小写不敏感），为空时使用默认列表
+	RedactHeaders []string
+	// RedactQueryParams 需要脱敏的查询参数（大小写不敏感），为空时使用默认列表
+	RedactQueryParams []string
+}
+
+// DefaultAccessLogConfig 默认的访问日志配置
+var DefaultAccessLogConfig = AccessLogConfig{
+	Format:            AccessLogFormatJSON,
+	SampleRate:        0,
+	RedactHeaders:     []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"},
+	RedactQueryParams: []string{"token", "access_token", "refresh_token", "password", "secret", "api_key", "signature"},
+}
+
+// AccessLogMiddleware 记录请求的访问日志
+// 记录字段: method, route, path, query, status, bytes, latency, client_ip, user, user_agent, request_id, trace_id
+func AccessLogMiddleware(config *AccessLogConfig) func(http.Handler) http.Handler {
+	if config == nil {
+		config = &DefaultAccessLogConfig
+	}
+
+	logger := newAccessLogger(config)
+	skip := newPathMatcher(config.SkipPaths)
+
+	redactHeaders := config.RedactHeaders
+	if len(redactHeaders) == 0 {
+		redactHeaders = DefaultAccessLogConfig.RedactHeaders
+	}
+	redactQuery := config.RedactQueryParams
+	if len(redactQuery) == 0 {
+		redactQuery = DefaultAccessLogConfig.RedactQueryParams
+	}
+	redactHeaderSet := lowerSet(redactHeaders)
+	redactQuerySet := lowerSet(redactQuery)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if skip.match(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now()
+			ctx, record := httpx.WithRequestRecord(r.Context())
+			r = r.WithContext(ctx)
+			rec := wrapper.NewStatusRecorder(w)
+			next.ServeHTTP(rec, r)
+			latency := time.Since(start)
+
+			status := rec.Status()
+			if !sampled(config.SampleRate, status) {
+				return
+			}
+
+			attrs := []slog.Attr{
+				slog.String("method", r.Method),
+				slog.String("route", httpx.RoutePattern(r)),
+				slog.String("path", r.URL.Path),
+				slog.String("query", redactQueryString(r.URL.RawQuery, redactQuerySet)),
+				slog.String("proto", r.Proto),
+				slog.Int("status", status),
+				slog.Int64("bytes", rec.BytesWritten()),
+				slog.Duration("latency", latency),
+				slog.String("client_ip", httpx.ClientIP(r)),
+				slog.String("user", userOf(r, record)),
+				slog.String("user_agent", r.UserAgent()),
+				slog.String("referer", r.Referer()),
+				slog.String("request_id", requestIDOf(r, record)),
+				slog.String("trace_id", traceIDOf(r, record)),
+			}
+			if len(config.LogHeaders) > 0 {
+				headers := make([]any, 0, len(config.LogHeaders))
+				for _, name := range config.LogHeaders {
+					value := r.Header.Get(name)
+					if value == "" {
+						continue
+					}
+					if redactHeaderSet[strings.ToLower(name)] {
+						value = redactedValue
+					}
+					headers = append(headers, slog.String(http.CanonicalHeaderKey(name), value))
+				}
+				if len(headers) > 0 {
+					attrs = append(attrs, slog.Group("headers", headers...))
+				}
+			}
+
+			logger.LogAttrs(r.Context(), statusLevel(status), "access", attrs...)
+		})
+	}
+}
+
+// newAccessLogger 根据配置创建 slog.Logger
+func newAccessLogger(config *AccessLogConfig) *slog.Logger {
+	if config.Logger != nil {
+		return config.Logger
+	}
+	out := config.Output
+	if out == nil {
+		out = os.Stdout
+	}
+	switch config.Format {
+	case AccessLogFormatLogfmt:
+		return slog.New(slog.NewTextHandler(out, nil))
+	case AccessLogFormatCombined:
+		return slog.New(newCombinedHandler(out))
+	default:
+		return slog.New(slog.NewJSONHandler(out, nil))
+	}
+}
+
+// statusLevel 根据状态码决定日志级别
+func statusLevel(status int) slog.Level {
+	switch {
+	case status >= 500:
+		return slog.LevelError
+	case status >= 400:
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// sampled 判断本次请求是否需要记录
+func sampled(rate float64, status int) bool {
+	if rate <= 0 || rate >= 1 || status >= 500 {
+		return true
+	}
+	return rand.Float64() < rate
+}
+
+// userOf 获取认证后的用户 ID
+// 优先从请求记录获取（认证中间件在内层），其次从上下文获取（认证中间件在外层）
+func userOf(r *http.Request, record *httpx.RequestRecord) string {
+	if user := record.User(); user != "" {
+		return user
+	}
+	if principal := auth.GetPrincipal(r); principal != nil {
+		return principal.ID
+	}
+	return ""
+}
+
+// requestIDOf 获取请求 ID
+// 优先从上下文获取（TraceMiddleware 在外层），其次从请求记录获取（TraceMiddleware 在内层），最后使用请求头
+func requestIDOf(r *http.Request, record *httpx.RequestRecord) string {
+	if id := httpx.GetRequestID(r); id != "" {
+		return id
+	}
+	if id := record.RequestID(); id != "" {
+		return id
+	}
+	return r.Header.Get(httpx.HeaderRequestID)
+}
+
+// traceIDOf 获取 trace id，获取顺序同 requestIDOf
+func traceIDOf(r *http.Request, record *httpx.RequestRecord) string {
+	if id := httpx.GetTraceID(r); id != "" {
+		return id
+	}
+	if id := record.TraceID(); id != "" {
+		return id
+	}
+	if tc, err := httpx.ParseTraceParent(r.Header.Get(httpx.HeaderTraceParent)); err == nil {
+		return tc.TraceID
+	}
+	return ""
+}
+
+// redactQueryString 对查询参数中的敏感字段进行脱敏
+func redactQueryString(rawQuery string, redact map[string]bool) string {
+	if rawQuery == "" {
+		return ""
+	}
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		// 无法解析的查询串不记录原文，避免泄露敏感信息
+		return redactedValue
+	}
+	for key := range values {
+		if redact[strings.ToLower(key)] {
+			for i := range values[key] {
+				values[key][i] = redactedValue
+			}
+		}
+	}
+	return values.Encode()
+}
+
+// lowerSet 将字符串列表转换为小写集合
+func lowerSet(items []string) map[string]bool {
+	set := make(map[string]bool, len(items))
+	for _, item := range items {
+		set[strings.ToLower(strings.TrimSpace(item))] = true
+	}
+	return set
+}
+
+// pathMatcher 路径匹配器，支持精确匹配和以 * 结尾的前缀匹配
+type pathMatcher struct {
+	exact    map[string]bool
+	prefixes []string
+}
+
+// newPathMatcher 创建路径匹配器
+func newPathMatcher(paths []string) *pathMatcher {
+	m := &pathMatcher{exact: make(map[string]bool)}
+	for _, p := range paths {
+		if strings.HasSuffix(p, "*") {
+			m.prefixes = append(m.prefixes, strings.TrimSuffix(p, "*"))
+		} else {
+			m.exact[p] = true
+		}
+	}
+	return m
+}
+
+// match 判断路径是否匹配
+func (m *pathMatcher) match(path string) bool {
+	if m.exact[path] {
+		return true
+	}
+	for _, prefix := range m.prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// combinedHandler 以 Apache combined 格式输出的 slog.Handler
+// 格式: host - user [time] "method path proto" status bytes "referer" "user-agent" request_id latency
+type combinedHandler struct {
+	mu    *sync.Mutex
+	out   io.Writer
+	attrs []slog.Attr
+}
+
+// newCombinedHandler 创建 combined 格式处理器
+func newCombinedHandler(out io.Writer) *combinedHandler {
+	return &combinedHandler{mu: &sync.Mutex{}, out: out}
+}
+
+// Enabled 访问日志所有级别都输出
+func (h *combinedHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+// Handle 将记录渲染为 combined 格式
+func (h *combinedHandler) Handle(_ context.Context, record slog.Record) error {
+	fields := make(map[string]slog.Value, 16)
+	for _, a := range h.attrs {
+		fields[a.Key] = a.Value
+	}
+	record.Attrs(func(a slog.Attr) bool {
+		fields[a.Key] = a.Value
+		return true
+	})
+
+	get := func(key string) string {
+		v, ok := fields[key]
+		if !ok {
+			return ""
+		}
+		return v.String()
+	}
+	dash := func(s string) string {
+		if s == "" {
+			return "-"
+		}
+		return s
+	}
+
+	target := get("path")
+	if q := get("query"); q != "" {
+		target += "?" + q
+	}
+	bytes := "-"
+	if v, ok := fields["bytes"]; ok && v.Int64() > 0 {
+		bytes = strconv.FormatInt(v.Int64(), 10)
+	}
+	latency := ""
+	if v, ok := fields["latency"]; ok {
+		latency = v.Duration().String()
+	}
+
+	line := fmt.Sprintf("%s - %s [%s] %q %s %s %q %q %s %s\n",
+		dash(get("client_ip")),
+		dash(get("user")),
+		record.Time.Format("02/Jan/2006:15:04:05 -0700"),
+		get("method")+" "+target+" "+get("proto"),
+		get("status"),
+		bytes,
+		dash(get("referer")),
+		dash(get("user_agent")),
+		dash(get("request_id")),
+		dash(latency),
+	)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.out, line)
+	return err
+}
+
+// WithAttrs 附加公共属性
+func (h *combinedHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &combinedHandler{mu: h.mu, out: h.out, attrs: append(append([]slog.Attr{}, h.attrs...), attrs...)}
+}
+
+// WithGroup combined 格式不支持分组，忽略
+func (h *combinedHandler) WithGroup(string) slog.Handler {
+	return h
+}
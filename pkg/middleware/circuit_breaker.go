@@ -0,0 +1,279 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/stones-hub/taurus-pro-http/pkg/httpx"
+	"github.com/stones-hub/taurus-pro-http/pkg/httpx/wrapper"
+)
+
+// ErrCircuitOpen 熔断器打开时 Execute 返回的错误
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitState 熔断器状态
+type CircuitState int
+
+const (
+	CircuitClosed   CircuitState = iota // 关闭：请求正常通过，统计失败率
+	CircuitOpen                         // 打开：直接拒绝请求，等待 OpenTimeout 后进入半开
+	CircuitHalfOpen                     // 半开：放行少量探测请求，成功则关闭，失败则重新打开
+)
+
+// String 状态名称
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreakerConfig 熔断器配置
+type CircuitBreakerConfig struct {
+	// Name 熔断器名称，用于日志，通常为下游依赖名称
+	Name string
+	// Window 失败率统计窗口，默认 10 秒
+	Window time.Duration
+	// MinRequests 窗口内最少请求数，达到后才计算失败率，默认 20
+	MinRequests int
+	// FailureRatio 失败率阈值 (0, 1]，默认 0.5
+	FailureRatio float64
+	// ConsecutiveFailures 连续失败次数阈值，达到后直接打开，0 表示不启用
+	ConsecutiveFailures int
+	// OpenTimeout 打开状态持续时间，之后进入半开，默认 30 秒
+	OpenTimeout time.Duration
+	// HalfOpenRequests 半开状态允许的探测请求数，全部成功后关闭，默认 1
+	HalfOpenRequests int
+	// IsFailure 判断响应状态码是否计为失败（仅中间件使用），默认 5xx 为失败
+	IsFailure func(status int) bool
+	// OnStateChange 状态变化回调
+	OnStateChange func(name string, from, to CircuitState)
+}
+
+// CircuitBreaker 熔断器
+// 既可以作为中间件保护整组路由（根据响应状态码统计失败），
+// 也可以在处理器中通过 Execute 保护下游调用；二者共享同一个熔断器时，下游故障会让中间件直接返回 503
+type CircuitBreaker struct {
+	config CircuitBreakerConfig
+
+	mu          sync.Mutex
+	state       CircuitState
+	openedAt    time.Time
+	windowStart time.Time
+	requests    int
+	failures    int
+	consecutive int
+	halfOpen    int    // 半开状态已放行的探测请求数
+	halfOpenOK  int    // 半开状态成功的探测请求数
+	generation  uint64 // 状态代数，每次状态切换加一，用于丢弃切换前放行的请求结果
+}
+
+// NewCircuitBreaker 创建熔断器
+func NewCircuitBreaker(config CircuitBreakerConfig) (*CircuitBreaker, error) {
+	if config.FailureRatio < 0 || config.FailureRatio > 1 {
+		return nil, fmt.Errorf("FailureRatio must be within (0, 1]")
+	}
+	if config.Window <= 0 {
+		config.Window = 10 * time.Second
+	}
+	if config.MinRequests <= 0 {
+		config.MinRequests = 20
+	}
+	if config.FailureRatio == 0 {
+		config.FailureRatio = 0.5
+	}
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = 30 * time.Second
+	}
+	if config.HalfOpenRequests <= 0 {
+		config.HalfOpenRequests = 1
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = func(status int) bool { return status >= 500 }
+	}
+	return &CircuitBreaker{config: config, windowStart: time.Now()}, nil
+}
+
+// State 返回当前状态
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.refresh(time.Now())
+	return cb.state
+}
+
+// Allow 判断请求是否可以通过，可以通过时必须调用 done 上报结果
+// 打开状态时返回还需要等待的时间
+func (cb *CircuitBreaker) Allow() (done func(success bool), retryAfter time.Duration, ok bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	now := time.Now()
+	cb.refresh(now)
+
+	switch cb.state {
+	case CircuitOpen:
+		return nil, cb.config.OpenTimeout - now.Sub(cb.openedAt), false
+	case CircuitHalfOpen:
+		if cb.halfOpen >= cb.config.HalfOpenRequests {
+			return nil, time.Second, false
+		}
+		cb.halfOpen++
+	}
+
+	generation := cb.generation
+	var once sync.Once
+	return func(success bool) {
+		once.Do(func() { cb.record(generation, success) })
+	}, 0, true
+}
+
+// Execute 在熔断器保护下执行 fn，熔断器打开时返回 ErrCircuitOpen
+// fn panic 时计为失败并继续向上抛出
+func (cb *CircuitBreaker) Execute(fn func() error) (err error) {
+	done, _, ok := cb.Allow()
+	if !ok {
+		return ErrCircuitOpen
+	}
+	success := false
+	defer func() {
+		done(success)
+	}()
+	err = fn()
+	success = err == nil
+	return err
+}
+
+// Middleware 返回熔断中间件，熔断器打开时返回 503 和 Retry-After
+func (cb *CircuitBreaker) Middleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			done, retryAfter, ok := cb.Allow()
+			if !ok {
+				httpx.SendResponseWithStatus(w, http.StatusServiceUnavailable, "service temporarily unavailable", map[string]string{
+					"Retry-After": strconv.Itoa(max(1, ceilSeconds(retryAfter))),
+				})
+				return
+			}
+
+			rec := wrapper.NewStatusRecorder(w)
+			success := false
+			defer func() {
+				// 处理器 panic 时计为失败，继续向上抛出交给 RecoveryMiddleware
+				done(success)
+			}()
+			next.ServeHTTP(rec, r)
+			success = !cb.config.IsFailure(rec.Status())
+		})
+	}
+}
+
+// refresh 处理基于时间的状态迁移，调用方需持有锁
+func (cb *CircuitBreaker) refresh(now time.Time) {
+	switch cb.state {
+	case CircuitOpen:
+		if now.Sub(cb.openedAt) >= cb.config.OpenTimeout {
+			cb.setState(CircuitHalfOpen, now)
+		}
+	case CircuitClosed:
+		if now.Sub(cb.windowStart) >= cb.config.Window {
+			cb.resetWindow(now)
+		}
+	}
+}
+
+// record 记录一次请求结果，generation 为请求放行时的状态代数
+// 状态已经切换过的结果不再计入，避免打开前放行的慢请求被当作半开状态的探测结果
+func (cb *CircuitBreaker) record(generation uint64, success bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	now := time.Now()
+	cb.refresh(now)
+	if generation != cb.generation {
+		return
+	}
+
+	switch cb.state {
+	case CircuitHalfOpen:
+		if !success {
+			cb.setState(CircuitOpen, now)
+			return
+		}
+		cb.halfOpenOK++
+		if cb.halfOpenOK >= cb.config.HalfOpenRequests {
+			cb.setState(CircuitClosed, now)
+		}
+	case CircuitClosed:
+		cb.requests++
+		if success {
+			cb.consecutive = 0
+			return
+		}
+		cb.failures++
+		cb.consecutive++
+		if cb.config.ConsecutiveFailures > 0 && cb.consecutive >= cb.config.ConsecutiveFailures {
+			cb.setState(CircuitOpen, now)
+			return
+		}
+		if cb.requests >= cb.config.MinRequests && float64(cb.failures)/float64(cb.requests) >= cb.config.FailureRatio {
+			cb.setState(CircuitOpen, now)
+		}
+	}
+}
+
+// setState 切换状态并重置计数，调用方需持有锁
+func (cb *CircuitBreaker) setState(state CircuitState, now time.Time) {
+	if cb.state == state {
+		return
+	}
+	from := cb.state
+	cb.state = state
+	cb.generation++
+	cb.halfOpen, cb.halfOpenOK = 0, 0
+	cb.resetWindow(now)
+	if state == CircuitOpen {
+		cb.openedAt = now
+	}
+
+	log.Printf("[CircuitBreaker] %s state changed: %s -> %s", cb.config.Name, from, state)
+	if cb.config.OnStateChange != nil {
+		// 回调中可能会调用 State()，放到协程中避免死锁
+		go cb.config.OnStateChange(cb.config.Name, from, state)
+	}
+}
+
+// resetWindow 重置统计窗口
+func (cb *CircuitBreaker) resetWindow(now time.Time) {
+	cb.windowStart = now
+	cb.requests, cb.failures, cb.consecutive = 0, 0, 0
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package middleware
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerExecutePanic(t *testing.T) {
+	cb, err := NewCircuitBreaker(CircuitBreakerConfig{ConsecutiveFailures: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("Execute swallowed the panic")
+			}
+		}()
+		cb.Execute(func() error { panic("boom") })
+	}()
+	if state := cb.State(); state != CircuitOpen {
+		t.Errorf("state after panic = %s, want open", state)
+	}
+	if err := cb.Execute(func() error { return nil }); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Execute = %v, want ErrCircuitOpen", err)
+	}
+}
+
+func TestCircuitBreakerIgnoresStaleResults(t *testing.T) {
+	cb, err := NewCircuitBreaker(CircuitBreakerConfig{ConsecutiveFailures: 1, OpenTimeout: 20 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	slow, _, ok := cb.Allow()
+	if !ok {
+		t.Fatal("closed breaker rejected a request")
+	}
+	failing, _, _ := cb.Allow()
+	failing(false)
+	time.Sleep(30 * time.Millisecond)
+	if state := cb.State(); state != CircuitHalfOpen {
+		t.Fatalf("state = %s, want half-open", state)
+	}
+
+	// 打开之前放行的慢请求不能当作探测结果关闭熔断器
+	slow(true)
+	if state := cb.State(); state != CircuitHalfOpen {
+		t.Fatalf("state after stale result = %s, want half-open", state)
+	}
+
+	probe, _, ok := cb.Allow()
+	if !ok {
+		t.Fatal("half-open breaker rejected the probe")
+	}
+	probe(true)
+	if state := cb.State(); state != CircuitClosed {
+		t.Errorf("state after successful probe = %s, want closed", state)
+	}
+}
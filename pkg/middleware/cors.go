@@ -14,7 +14,7 @@
 
 // Author: yelei
 // Email: 61647649@qq.com
-// Date: 2025-06-13
+// Date: 2026-10-18
 
 package middleware
 
@@ -23,8 +23,10 @@ import (
 	"log"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"unicode"
 
 	"github.com/stones-hub/taurus-pro-http/pkg/httpx"
@@ -35,13 +37,28 @@ type CorsConfig struct {
 	// AllowOrigins 支持多个域名，用逗号分隔，如："http://localhost:8080,https://example.com"
 	// 如果设置为"*"且AllowCredentials为false时允许所有域名
 	// 如果设置为具体域名，则只允许列表中的域名访问
+	// 支持子域名通配，如 "https://*.example.com" 匹配 https://a.example.com、https://a.b.example.com，但不匹配 https://example.com
 	AllowOrigins string
-	AllowMethods string
-	AllowHeaders string
+	// AllowOriginRegexps Origin 正则表达式，自动完整匹配（加上 ^ 和 $），匹配前 Origin 会被转换为小写
+	// 如 `https://pr-\d+\.preview\.example\.com`
+	AllowOriginRegexps []string
+	// AllowOriginFunc 动态判断 Origin 是否允许（如按租户查询数据库），在静态列表不匹配时调用
+	// 结果可能依赖请求，会同时设置 Vary: Origin
+	AllowOriginFunc func(r *http.Request, origin string) bool
+	AllowMethods    string
+	AllowHeaders    string
+	// ExposeHeaders 允许浏览器 JS 读取的响应头，用逗号分隔，如 "X-Request-Id, X-RateLimit-Remaining"
+	ExposeHeaders string
 	// 是否允许携带凭证（cookies, HTTP认证及客户端SSL证书等）
 	// 当设置为true时，AllowOrigins不能为"*"，必须指定具体域名
 	AllowCredentials bool
-	MaxAge           string
+	// AllowPrivateNetwork 是否允许公网页面访问内网地址（Private Network Access）
+	// 预检请求携带 Access-Control-Request-Private-Network: true 时返回 Access-Control-Allow-Private-Network: true
+	AllowPrivateNetwork bool
+	MaxAge              string
+	// Routes 按路由覆盖的 CORS 策略，key 为路由模式或请求路径，支持以 * 结尾的前缀匹配，如 "/public/*"
+	// 精确匹配优先，前缀匹配时最长前缀优先；未匹配的请求使用外层配置，路由策略中不能再嵌套 Routes
+	Routes map[string]*CorsConfig
 }
 
 // DefaultCorsConfig 默认的 CORS 配置
@@ -97,18 +114,20 @@ func validateConfig(config *CorsConfig) error {
 	}
 
 	// 验证配置的 AllowOrigins 是否合法
-	if config.AllowOrigins != "*" {
-		origins := strings.Split(config.AllowOrigins, ",")
-		for _, origin := range origins {
+	if config.AllowOrigins != "*" && config.AllowOrigins != "" {
+		for _, origin := range strings.Split(config.AllowOrigins, ",") {
 			origin = strings.TrimSpace(origin)
 			if origin == "" {
 				return fmt.Errorf("origin 不能为空")
 			}
-			if !validateOrigin(origin) {
-				return fmt.Errorf("无效的 Origin: %s", origin)
+			if origin == "*" {
+				return fmt.Errorf("'*' 不能与其他 Origin 同时使用")
 			}
 		}
 	}
+	if config.AllowOrigins == "" && len(config.AllowOriginRegexps) == 0 && config.AllowOriginFunc == nil {
+		return fmt.Errorf("AllowOrigins、AllowOriginRegexps 和 AllowOriginFunc 不能同时为空")
+	}
 
 	// 验证配置的 AllowMethods 是否合法
 	if config.AllowMethods != "" && strings.TrimSpace(config.AllowMethods) != "*" {
@@ -129,256 +148,424 @@ func validateConfig(config *CorsConfig) error {
 		}
 	}
 
-	// 验证配置的 AllowHeaders 是否合法
-	if config.AllowHeaders != "" && strings.TrimSpace(config.AllowHeaders) != "*" {
-		headers := strings.Split(config.AllowHeaders, ",")
-		for _, header := range headers {
-			header = strings.TrimSpace(header)
-			if header == "" {
-				return fmt.Errorf("header 不能为空")
-			}
-			// 检查 header 是否是有效的 HTTP 头名称格式
-			// HTTP 头名称只能包含字母、数字和连字符(-)
-			for _, char := range header {
-				isLetter := unicode.IsLetter(char) // 是否是字母
-				isDigit := unicode.IsDigit(char)   // 是否是数字
-				isHyphen := char == '-'            // 是否是连字符
-
-				if !isLetter && !isDigit && !isHyphen {
-					return fmt.Errorf("header 名称 '%s' 包含非法字符，只允许字母、数字和连字符(-)", header)
-				}
+	// 验证配置的 AllowHeaders 和 ExposeHeaders 是否合法
+	if strings.TrimSpace(config.AllowHeaders) != "*" {
+		if err := validateHeaderList(config.AllowHeaders); err != nil {
+			return err
+		}
+	}
+	if strings.TrimSpace(config.ExposeHeaders) != "*" {
+		if err := validateHeaderList(config.ExposeHeaders); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateHeaderList 验证逗号分隔的头名称列表
+func validateHeaderList(list string) error {
+	if list == "" {
+		return nil
+	}
+	for _, header := range strings.Split(list, ",") {
+		header = strings.TrimSpace(header)
+		if header == "" {
+			return fmt.Errorf("header 不能为空")
+		}
+		// 检查 header 是否是有效的 HTTP 头名称格式
+		// HTTP 头名称只能包含字母、数字和连字符(-)
+		for _, char := range header {
+			isLetter := unicode.IsLetter(char) // 是否是字母
+			isDigit := unicode.IsDigit(char)   // 是否是数字
+			isHyphen := char == '-'            // 是否是连字符
+
+			if !isLetter && !isDigit && !isHyphen {
+				return fmt.Errorf("header 名称 '%s' 包含非法字符，只允许字母、数字和连字符(-)", header)
 			}
 		}
 	}
+	return nil
+}
+
+// corsPolicy 预先解析的 CORS 策略，避免每个请求重复分割配置字符串
+type corsPolicy struct {
+	config       *CorsConfig
+	origins      *originMatcher
+	anyMethod    bool
+	methods      map[string]bool
+	anyHeader    bool
+	headers      map[string]bool
+	exposeHeader string
+}
+
+// newCorsPolicy 校验并解析 CORS 配置
+func newCorsPolicy(config *CorsConfig) (*corsPolicy, error) {
+	if err := validateConfig(config); err != nil {
+		return nil, err
+	}
+	origins, err := newOriginMatcher(config.AllowOrigins)
+	if err != nil {
+		return nil, err
+	}
+	if err := origins.addRegexps(config.AllowOriginRegexps); err != nil {
+		return nil, err
+	}
 
+	p := &corsPolicy{
+		config:    config,
+		origins:   origins,
+		anyMethod: strings.TrimSpace(config.AllowMethods) == "*",
+		methods:   make(map[string]bool),
+		anyHeader: strings.TrimSpace(config.AllowHeaders) == "*",
+		headers:   make(map[string]bool),
+	}
+	for _, method := range strings.Split(config.AllowMethods, ",") {
+		if method = strings.TrimSpace(method); method != "" {
+			p.methods[method] = true
+		}
+	}
+	// 将允许的头转换为小写 map，便于查找
+	for _, header := range strings.Split(config.AllowHeaders, ",") {
+		if header = strings.TrimSpace(strings.ToLower(header)); header != "" {
+			p.headers[header] = true
+		}
+	}
+	var expose []string
+	for _, header := range strings.Split(config.ExposeHeaders, ",") {
+		if header = strings.TrimSpace(header); header != "" {
+			expose = append(expose, header)
+		}
+	}
+	p.exposeHeader = strings.Join(expose, ", ")
+	return p, nil
+}
+
+// allowOrigin 返回 Access-Control-Allow-Origin 的值，空字符串表示不允许
+func (p *corsPolicy) allowOrigin(r *http.Request, origin string) string {
+	if p.origins.any && !p.config.AllowCredentials && p.config.AllowOriginFunc == nil {
+		return "*"
+	}
+	if p.origins.match(origin) || (p.config.AllowOriginFunc != nil && p.config.AllowOriginFunc(r, origin)) {
+		return origin
+	}
+	return ""
+}
+
+// corsRoutes 按路由选择 CORS 策略
+type corsRoutes struct {
+	exact    map[string]*corsPolicy
+	prefixes []corsRoutePrefix
+}
+
+type corsRoutePrefix struct {
+	prefix string
+	policy *corsPolicy
+}
+
+// newCorsRoutes 解析 CorsConfig.Routes
+func newCorsRoutes(routes map[string]*CorsConfig) (*corsRoutes, error) {
+	rs := &corsRoutes{exact: make(map[string]*corsPolicy)}
+	for route, config := range routes {
+		if config == nil {
+			return nil, fmt.Errorf("路由 %s 的 CORS 配置不能为空", route)
+		}
+		if len(config.Routes) > 0 {
+			return nil, fmt.Errorf("路由 %s 的 CORS 配置不能嵌套 Routes", route)
+		}
+		policy, err := newCorsPolicy(config)
+		if err != nil {
+			return nil, fmt.Errorf("路由 %s 的 CORS 配置无效: %w", route, err)
+		}
+		if prefix, ok := strings.CutSuffix(route, "*"); ok {
+			rs.prefixes = append(rs.prefixes, corsRoutePrefix{prefix: prefix, policy: policy})
+		} else {
+			rs.exact[route] = policy
+		}
+	}
+	sort.Slice(rs.prefixes, func(i, j int) bool {
+		return len(rs.prefixes[i].prefix) > len(rs.prefixes[j].prefix)
+	})
+	return rs, nil
+}
+
+// lookup 依次使用路由模式和请求路径查找策略
+func (rs *corsRoutes) lookup(r *http.Request) *corsPolicy {
+	keys := []string{httpx.RoutePattern(r), r.URL.Path}
+	for _, key := range keys {
+		if p, ok := rs.exact[key]; ok && key != "" {
+			return p
+		}
+	}
+	for _, key := range keys {
+		for _, prefix := range rs.prefixes {
+			if key != "" && strings.HasPrefix(key, prefix.prefix) {
+				return prefix.policy
+			}
+		}
+	}
 	return nil
 }
 
-// CorsMiddleware 添加 CORS 头到响应中
-func CorsMiddleware(config *CorsConfig) func(http.Handler) http.Handler {
+// corsSettings 可热更新的 CORS 策略
+type corsSettings struct {
+	defaultPolicy *corsPolicy
+	routes        *corsRoutes
+}
+
+// Cors CORS 控制器，策略可以通过 Update 热更新（如从配置文件重新加载允许的域名）
+type Cors struct {
+	settings atomic.Pointer[corsSettings]
+}
+
+// NewCors 创建 CORS 控制器，配置无效时返回错误
+// config 为 nil 时使用 DefaultCorsConfig
+func NewCors(config *CorsConfig) (*Cors, error) {
+	c := &Cors{}
+	if err := c.Update(config); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Update 更新 CORS 配置，对之后的请求生效；配置无效时返回错误并保留原配置
+// config 为 nil 时使用 DefaultCorsConfig
+func (c *Cors) Update(config *CorsConfig) error {
 	// 如果没有提供配置，使用默认配置
 	if config == nil {
 		config = &DefaultCorsConfig
 	}
 
-	// 验证配置 config 是否合法
-	if err := validateConfig(config); err != nil {
-		panic(fmt.Sprintf("CORS 配置无效: %v", err))
+	defaultPolicy, err := newCorsPolicy(config)
+	if err != nil {
+		return err
+	}
+	routes, err := newCorsRoutes(config.Routes)
+	if err != nil {
+		return err
 	}
+	c.settings.Store(&corsSettings{defaultPolicy: defaultPolicy, routes: routes})
+	return nil
+}
 
+// Middleware 返回 CORS 中间件
+func (c *Cors) Middleware() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// 获取请求的 Origin
-			origin := r.Header.Get("Origin")
-			if origin == "" {
-				// 不是 CORS 请求，直接处理
-				next.ServeHTTP(w, r)
-				return
+			settings := c.settings.Load()
+			policy := settings.defaultPolicy
+			if p := settings.routes.lookup(r); p != nil {
+				policy = p
 			}
+			serveCors(w, r, next, policy)
+		})
+	}
+}
 
-			// 验证 origin 格式, 但是排除 origin 为空的情况
-			if !validateOrigin(origin) {
-				httpx.SendResponse(w, http.StatusForbidden, "Invalid Origin", nil)
-				return
-			}
-			// 已验证完 origin ， 获取的 origin 是合法的且不为空， 接下来检查是否在允许的域名列表中
-
-			// 1. 检查 Origin 是否允许
-			if config.AllowOrigins == "*" {
-				w.Header().Set("Access-Control-Allow-Origin", "*")
-			} else {
-				// 检查是否在允许的域名列表中
-				allowedOrigins := strings.Split(config.AllowOrigins, ",")
-				for _, allowedOrigin := range allowedOrigins {
-					if strings.TrimSpace(allowedOrigin) == origin {
-						w.Header().Set("Access-Control-Allow-Origin", origin)
-						w.Header().Set("Vary", "Origin")
-						break
-					}
-				}
-			}
-			// 如果允许跨域，那已经设置 Access-Control-Allow-Origin 头， 接下来检查是否允许该 Origin
-			// 如果没有设置 Access-Control-Allow-Origin 头，说明不允许该 Origin
-			if w.Header().Get("Access-Control-Allow-Origin") == "" {
-				httpx.SendResponse(w, http.StatusForbidden, "Forbidden", nil)
-				return
-			}
+// NewCorsMiddleware 创建 CORS 中间件，配置无效时返回错误
+// config 为 nil 时使用 DefaultCorsConfig；需要热更新配置时使用 NewCors
+func NewCorsMiddleware(config *CorsConfig) (func(http.Handler) http.Handler, error) {
+	c, err := NewCors(config)
+	if err != nil {
+		return nil, err
+	}
+	return c.Middleware(), nil
+}
 
-			// ----------------------------------------------验证请求方法和请求头(开始)--------------------------------------------------
-			// 2. 统一验证请求方法和请求头（不管是否是预检请求）
-			// 2.1 验证请求方法, 强制如果是跨域，预检请求必须携带 Access-Control-Request-Method 头
-			var requestMethod string
-			if r.Method == http.MethodOptions {
-				// 预检请求从头部获取方法
-				requestMethod = r.Header.Get("Access-Control-Request-Method")
-
-				// 如果预检请求没有携带 Access-Control-Request-Method 头，说明不是 CORS 请求，直接返回
-				if requestMethod == "" {
-					log.Println("[CORS] 预检请求没有携带 Access-Control-Request-Method 头，说明不是 CORS 请求，直接返回.")
-					httpx.SendResponse(w, http.StatusForbidden, "Method not allowed", nil)
-					return
-				}
-
-			} else {
-				// 非预检请求直接使用请求方法
-				requestMethod = r.Method
-			}
-			methodAllowed := false
-			if strings.TrimSpace(config.AllowMethods) == "*" {
-				methodAllowed = true
-			} else {
-				for _, method := range strings.Split(config.AllowMethods, ",") {
-					if strings.TrimSpace(method) == requestMethod {
-						methodAllowed = true
-						break
-					}
-				}
-			}
-			if !methodAllowed {
-				log.Printf("[CORS] 请求方法不允许, 请求方法: %s, 允许的方法: %s", requestMethod, config.AllowMethods)
-				httpx.SendResponse(w, http.StatusForbidden, "Method not allowed", nil)
-				return
-			}
+// CorsMiddleware 添加 CORS 头到响应中，配置无效时 panic
+// 需要处理配置错误（如从配置文件加载）时请使用 NewCorsMiddleware
+func CorsMiddleware(config *CorsConfig) func(http.Handler) http.Handler {
+	mw, err := NewCorsMiddleware(config)
+	if err != nil {
+		panic(fmt.Sprintf("CORS 配置无效: %v", err))
+	}
+	return mw
+}
 
-			// 2.2 验证请求头
-			var requestHeaders string
-			if r.Method == http.MethodOptions {
-				// 预检请求从头部获取
-				optionsHeaders := r.Header.Get("Access-Control-Request-Headers")
-				optionsCustomHeaders := []string{}
-				// 预检请求可能没有自定义头，这是正常的, 但是过滤掉标准头
-				for _, headerName := range strings.Split(optionsHeaders, ",") {
-					if !isStandardHeader(strings.TrimSpace(strings.ToLower(headerName))) {
-						optionsCustomHeaders = append(optionsCustomHeaders, headerName)
-					}
-				}
-				requestHeaders = strings.Join(optionsCustomHeaders, ",")
-			} else {
-				// 非预检请求只检查自定义头（非标准头）
-				var customHeaders []string
-				for headerName := range r.Header {
-					if !isStandardHeader(strings.TrimSpace(strings.ToLower(headerName))) {
-						customHeaders = append(customHeaders, headerName)
-					}
-				}
-				requestHeaders = strings.Join(customHeaders, ",")
-			}
+// serveCors 按策略处理一个请求
+func serveCors(w http.ResponseWriter, r *http.Request, next http.Handler, policy *corsPolicy) {
+	config := policy.config
 
-			// 只有当有自定义头时才需要验证
-			if requestHeaders != "" && strings.TrimSpace(config.AllowHeaders) != "*" {
-				// 验证请求中使用的自定义头是否在允许列表中
-				allowedHeadersMapLower := make(map[string]bool)
-				allowedHeaders := strings.Split(config.AllowHeaders, ",")
-
-				// 将允许的头转换为小写 map，便于查找
-				for _, header := range allowedHeaders {
-					allowedHeadersMapLower[strings.TrimSpace(strings.ToLower(header))] = true
-				}
-
-				// 检查请求中的每个自定义头是否在允许列表中
-				headersAllowed := true
-				for _, header := range strings.Split(requestHeaders, ",") {
-					header = strings.TrimSpace(strings.ToLower(header))
-					if header == "" {
-						continue
-					}
-
-					if !allowedHeadersMapLower[header] {
-						headersAllowed = false
-						break
-					}
-				}
-
-				if !headersAllowed {
-					log.Printf("[CORS] 请求头验证失败, 请求头: %s, 允许的请求头: %s (注意：自定义头大小写不敏感)", requestHeaders, config.AllowHeaders)
-					httpx.SendResponse(w, http.StatusForbidden, "Headers not allowed", nil)
-					return
-				}
-			}
+	// 获取请求的 Origin
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		// 不是 CORS 请求，直接处理
+		next.ServeHTTP(w, r)
+		return
+	}
 
-			// ----------------------------------------------验证请求方法和请求头(结束)--------------------------------------------------
+	// 验证 origin 格式, 但是排除 origin 为空的情况
+	if !validateOrigin(origin) {
+		httpx.SendResponse(w, http.StatusForbidden, "Invalid Origin", nil)
+		return
+	}
+	// 已验证完 origin ， 获取的 origin 是合法的且不为空， 接下来检查是否在允许的域名列表中
 
-			// 如果是预检请求，设置响应头并返回
-			if r.Method == http.MethodOptions {
-				w.Header().Set("Access-Control-Allow-Methods", config.AllowMethods)
+	// 1. 检查 Origin 是否允许
+	allowOrigin := policy.allowOrigin(r, origin)
+	if allowOrigin != "*" {
+		// 响应随 Origin 变化，缓存必须区分 Origin
+		w.Header().Add("Vary", "Origin")
+	}
+	// 如果没有允许的 Origin，说明不允许该 Origin
+	if allowOrigin == "" {
+		httpx.SendResponse(w, http.StatusForbidden, "Forbidden", nil)
+		return
+	}
+	w.Header().Set("Access-Control-Allow-Origin", allowOrigin)
+
+	// ----------------------------------------------验证请求方法和请求头(开始)--------------------------------------------------
+	// 2. 统一验证请求方法和请求头（不管是否是预检请求）
+	// 2.1 验证请求方法, 强制如果是跨域，预检请求必须携带 Access-Control-Request-Method 头
+	var requestMethod string
+	if r.Method == http.MethodOptions {
+		// 预检请求从头部获取方法
+		requestMethod = r.Header.Get("Access-Control-Request-Method")
+
+		// 如果预检请求没有携带 Access-Control-Request-Method 头，说明不是 CORS 请求，直接返回
+		if requestMethod == "" {
+			log.Println("[CORS] 预检请求没有携带 Access-Control-Request-Method 头，说明不是 CORS 请求，直接返回.")
+			httpx.SendResponse(w, http.StatusForbidden, "Method not allowed", nil)
+			return
+		}
 
-				// 直接返回预检请求中声明的头（已经验证过了）
-				optionsHeaders := r.Header.Get("Access-Control-Request-Headers")
-				if optionsHeaders != "" {
-					w.Header().Set("Access-Control-Allow-Headers", optionsHeaders)
-				}
+	} else {
+		// 非预检请求直接使用请求方法
+		requestMethod = r.Method
+	}
+	if !policy.anyMethod && !policy.methods[requestMethod] {
+		log.Printf("[CORS] 请求方法不允许, 请求方法: %s, 允许的方法: %s", requestMethod, config.AllowMethods)
+		httpx.SendResponse(w, http.StatusForbidden, "Method not allowed", nil)
+		return
+	}
 
-				w.Header().Set("Access-Control-Max-Age", config.MaxAge)
-				httpx.SendResponse(w, http.StatusNoContent, "No Content", nil)
+	// 2.2 验证请求头
+	var requestHeaders []string
+	if r.Method == http.MethodOptions {
+		// 预检请求从头部获取，预检请求可能没有自定义头，这是正常的, 但是过滤掉标准头
+		for _, headerName := range strings.Split(r.Header.Get("Access-Control-Request-Headers"), ",") {
+			if !isStandardHeader(strings.TrimSpace(headerName)) {
+				requestHeaders = append(requestHeaders, headerName)
+			}
+		}
+	} else {
+		// 非预检请求只检查自定义头（非标准头）
+		for headerName := range r.Header {
+			if !isStandardHeader(headerName) {
+				requestHeaders = append(requestHeaders, headerName)
+			}
+		}
+	}
+
+	// 只有当有自定义头时才需要验证，检查请求中的每个自定义头是否在允许列表中
+	if !policy.anyHeader {
+		for _, header := range requestHeaders {
+			header = strings.TrimSpace(strings.ToLower(header))
+			if header != "" && !policy.headers[header] {
+				log.Printf("[CORS] 请求头验证失败, 请求头: %s, 允许的请求头: %s (注意：自定义头大小写不敏感)", strings.Join(requestHeaders, ","), config.AllowHeaders)
+				httpx.SendResponse(w, http.StatusForbidden, "Headers not allowed", nil)
 				return
 			}
+		}
+	}
+
+	// ----------------------------------------------验证请求方法和请求头(结束)--------------------------------------------------
 
-			// 3. 对于非预检请求，设置必要的 CORS 响应头
-			if config.AllowCredentials {
-				w.Header().Set("Access-Control-Allow-Credentials", "true")
+	// 如果是预检请求，设置响应头并返回
+	if r.Method == http.MethodOptions {
+		w.Header().Add("Vary", "Access-Control-Request-Method")
+		w.Header().Add("Vary", "Access-Control-Request-Headers")
+		w.Header().Set("Access-Control-Allow-Methods", config.AllowMethods)
+
+		// 直接返回预检请求中声明的头（已经验证过了）
+		optionsHeaders := r.Header.Get("Access-Control-Request-Headers")
+		if optionsHeaders != "" {
+			w.Header().Set("Access-Control-Allow-Headers", optionsHeaders)
+		}
+		if config.AllowCredentials {
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+		// Private Network Access: 公网页面访问内网服务前，浏览器会在预检请求中询问
+		if r.Header.Get("Access-Control-Request-Private-Network") == "true" {
+			w.Header().Add("Vary", "Access-Control-Request-Private-Network")
+			if config.AllowPrivateNetwork {
+				w.Header().Set("Access-Control-Allow-Private-Network", "true")
 			}
+		}
 
-			next.ServeHTTP(w, r)
-		})
+		if config.MaxAge != "" {
+			w.Header().Set("Access-Control-Max-Age", config.MaxAge)
+		}
+		httpx.SendResponse(w, http.StatusNoContent, "No Content", nil)
+		return
+	}
+
+	// 3. 对于非预检请求，设置必要的 CORS 响应头
+	if config.AllowCredentials {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+	if policy.exposeHeader != "" {
+		w.Header().Set("Access-Control-Expose-Headers", policy.exposeHeader)
 	}
+
+	next.ServeHTTP(w, r)
+}
+
+// standardHeaders HTTP/1.1 标准请求头（RFC 7231），跨域请求中不需要验证
+var standardHeaders = map[string]bool{
+	// 通用头（General Headers）
+	"cache-control":     true,
+	"connection":        true,
+	"date":              true,
+	"pragma":            true,
+	"trailer":           true,
+	"transfer-encoding": true,
+	"upgrade":           true,
+	"via":               true,
+	"warning":           true,
+
+	// 请求头（Request Headers）
+	"accept":              true,
+	"accept-charset":      true,
+	"accept-encoding":     true,
+	"accept-language":     true,
+	"authorization":       true,
+	"expect":              true,
+	"from":                true,
+	"host":                true,
+	"if-match":            true,
+	"if-modified-since":   true,
+	"if-none-match":       true,
+	"if-range":            true,
+	"if-unmodified-since": true,
+	"max-forwards":        true,
+	"proxy-authorization": true,
+	"range":               true,
+	"referer":             true,
+	"te":                  true,
+	"user-agent":          true,
+
+	// 实体头（Entity Headers）
+	"content-encoding": true,
+	"content-language": true,
+	"content-length":   true,
+	"content-location": true,
+	"content-md5":      true,
+	"content-range":    true,
+	"content-type":     true,
+
+	// CORS 相关头
+	"origin":                                 true,
+	"access-control-request-method":          true,
+	"access-control-request-headers":         true,
+	"access-control-request-private-network": true,
+
+	// 其他常见标准头
+	"cookie": true,
+	"dnt":    true,
 }
 
 // isStandardHeader 判断是否是标准 HTTP 头（不需要验证的头）
 func isStandardHeader(headerName string) bool {
-	headerNameLower := strings.ToLower(headerName)
-
-	// HTTP/1.1 标准请求头（RFC 7231）
-	standardHeaders := map[string]bool{
-		// 通用头（General Headers）
-		"cache-control":     true,
-		"connection":        true,
-		"date":              true,
-		"pragma":            true,
-		"trailer":           true,
-		"transfer-encoding": true,
-		"upgrade":           true,
-		"via":               true,
-		"warning":           true,
-
-		// 请求头（Request Headers）
-		"accept":              true,
-		"accept-charset":      true,
-		"accept-encoding":     true,
-		"accept-language":     true,
-		"authorization":       true,
-		"expect":              true,
-		"from":                true,
-		"host":                true,
-		"if-match":            true,
-		"if-modified-since":   true,
-		"if-none-match":       true,
-		"if-range":            true,
-		"if-unmodified-since": true,
-		"max-forwards":        true,
-		"proxy-authorization": true,
-		"range":               true,
-		"referer":             true,
-		"te":                  true,
-		"user-agent":          true,
-
-		// 实体头（Entity Headers）
-		"content-encoding": true,
-		"content-language": true,
-		"content-length":   true,
-		"content-location": true,
-		"content-md5":      true,
-		"content-range":    true,
-		"content-type":     true,
-
-		// CORS 相关头
-		"origin":                         true,
-		"access-control-request-method":  true,
-		"access-control-request-headers": true,
-
-		// 其他常见标准头
-		"cookie": true,
-		"dnt":    true,
-	}
-
-	return standardHeaders[headerNameLower]
+	return standardHeaders[strings.ToLower(headerName)]
 }
@@ -0,0 +1,341 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package middleware
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/stones-hub/taurus-pro-http/pkg/httpx"
+	"github.com/stones-hub/taurus-pro-http/pkg/session"
+)
+
+// CSRFMode CSRF token 的保存方式
+type CSRFMode string
+
+const (
+	// CSRFDoubleSubmit 签名的双重提交 Cookie：token 保存在 HttpOnly Cookie 中，
+	// 请求时通过请求头或表单字段回传安全方法响应头中的 token（或 CSRFToken(r)），而不是 Cookie 的值
+	// 不依赖服务端状态，适合无会话的 API 和多实例部署
+	CSRFDoubleSubmit CSRFMode = "double_submit"
+	// CSRFSynchronizer 同步器 token：token 保存在服务端会话中，需要先挂载 session 中间件
+	CSRFSynchronizer CSRFMode = "synchronizer"
+)
+
+// csrfSessionKey 同步器模式下 token 在会话中的键
+const csrfSessionKey = "_csrf_token"
+
+// csrfTokenLength 原始 token 字节数
+const csrfTokenLength = 32
+
+// CSRFConfig CSRF 防护配置
+type CSRFConfig struct {
+	// Mode token 保存方式，默认 CSRFDoubleSubmit
+	Mode CSRFMode
+	// Secret 双重提交模式下签名 Cookie 的密钥，至少 32 字节
+	Secret []byte
+	// TrustedOrigins 允许跨域提交的来源，格式与 CorsConfig.AllowOrigins 相同（逗号分隔）
+	// 同源请求总是允许，不需要配置
+	TrustedOrigins string
+
+	// HeaderName JSON 客户端回传 token 的请求头，默认 X-CSRF-Token；安全方法的响应中也会通过该头下发 token
+	HeaderName string
+	// FormField 表单回传 token 的字段名，默认 csrf_token
+	FormField string
+	// CookieName 双重提交模式下的 Cookie 名称，默认 csrf_token
+	CookieName string
+	// CookiePath Cookie 路径，默认 "/"
+	CookiePath string
+	// CookieDomain Cookie 域名
+	CookieDomain string
+	// CookieMaxAge Cookie 有效期，默认 12 小时
+	CookieMaxAge time.Duration
+	// AllowInsecureCookie 不设置 Secure 属性，仅用于本地 HTTP 开发环境
+	AllowInsecureCookie bool
+
+	// ExemptPaths 不做 CSRF 检查的路径或路由模式，支持以 * 结尾的前缀匹配，如 "/webhooks/*"
+	ExemptPaths []string
+	// ExemptFunc 自定义豁免判断，如使用 API Key / HMAC 认证的请求不需要 CSRF 防护
+	ExemptFunc func(r *http.Request) bool
+	// OnFailure 校验失败回调，可用于记录日志或指标
+	OnFailure func(r *http.Request, reason string)
+}
+
+// csrfContextKey 请求上下文中保存 token 的键
+type csrfContextKey struct{}
+
+// NewCSRFMiddleware 创建 CSRF 防护中间件，配置无效时返回错误
+// 1. 安全方法（GET/HEAD/OPTIONS/TRACE）: 生成或复用 token，通过 CSRFToken(r) / CSRFTemplateField(r) 获取，并写入响应头
+// 2. 其他方法: 校验 Origin（或 HTTPS 下的 Referer）与请求同源或在 TrustedOrigins 中，再校验 token
+// 校验失败返回 403
+func NewCSRFMiddleware(config CSRFConfig) (func(http.Handler) http.Handler, error) {
+	if config.Mode == "" {
+		config.Mode = CSRFDoubleSubmit
+	}
+	switch config.Mode {
+	case CSRFDoubleSubmit:
+		if len(config.Secret) < 32 {
+			return nil, fmt.Errorf("csrf secret must be at least 32 bytes")
+		}
+	case CSRFSynchronizer:
+	default:
+		return nil, fmt.Errorf("unknown csrf mode %q", config.Mode)
+	}
+	trusted, err := newOriginMatcher(config.TrustedOrigins)
+	if err != nil {
+		return nil, fmt.Errorf("invalid csrf trusted origins: %w", err)
+	}
+	if config.HeaderName == "" {
+		config.HeaderName = "X-CSRF-Token"
+	}
+	if config.FormField == "" {
+		config.FormField = "csrf_token"
+	}
+	if config.CookieName == "" {
+		config.CookieName = "csrf_token"
+	}
+	if config.CookiePath == "" {
+		config.CookiePath = "/"
+	}
+	if config.CookieMaxAge <= 0 {
+		config.CookieMaxAge = 12 * time.Hour
+	}
+	exempt := newPathMatcher(config.ExemptPaths)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if exempt.match(r.URL.Path) || exempt.match(httpx.RoutePattern(r)) ||
+				(config.ExemptFunc != nil && config.ExemptFunc(r)) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			token, ok := loadCSRFToken(r, &config)
+			if !ok && config.Mode == CSRFSynchronizer && session.Get(r) == nil {
+				log.Printf("[CSRF] synchronizer mode requires session middleware, path: %s", r.URL.Path)
+				csrfFailure(w, r, &config, "csrf token unavailable")
+				return
+			}
+
+			if !isSafeMethod(r.Method) {
+				if reason := checkCSRFOrigin(r, trusted); reason != "" {
+					csrfFailure(w, r, &config, reason)
+					return
+				}
+				submitted := r.Header.Get(config.HeaderName)
+				if submitted == "" {
+					submitted = r.PostFormValue(config.FormField)
+				}
+				if !ok || !validCSRFToken(submitted, token) {
+					csrfFailure(w, r, &config, "invalid csrf token")
+					return
+				}
+			}
+
+			if !ok {
+				token = newCSRFToken()
+				saveCSRFToken(w, r, &config, token)
+			}
+			masked := maskCSRFToken(token)
+			w.Header().Set(config.HeaderName, masked)
+			w.Header().Add("Vary", "Cookie")
+			ctx := context.WithValue(r.Context(), csrfContextKey{}, csrfTokenInfo{token: masked, field: config.FormField})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}, nil
+}
+
+// csrfTokenInfo 上下文中的 token 信息
+type csrfTokenInfo struct {
+	token string
+	field string
+}
+
+// CSRFToken 获取当前请求的 CSRF token，用于 JSON 客户端或模板
+// 每次请求返回的 token 都经过随机掩码，避免 BREACH 攻击，但都可以通过校验
+func CSRFToken(r *http.Request) string {
+	info, _ := r.Context().Value(csrfContextKey{}).(csrfTokenInfo)
+	return info.token
+}
+
+// CSRFTemplateField 返回包含 CSRF token 的隐藏表单字段，用于 html/template
+// 示例: {{ .CSRFField }}，其中 CSRFField = middleware.CSRFTemplateField(r)
+func CSRFTemplateField(r *http.Request) template.HTML {
+	info, ok := r.Context().Value(csrfContextKey{}).(csrfTokenInfo)
+	if !ok {
+		return ""
+	}
+	return template.HTML(fmt.Sprintf(`<input type="hidden" name="%s" value="%s">`,
+		template.HTMLEscapeString(info.field), template.HTMLEscapeString(info.token)))
+}
+
+// isSafeMethod 是否为不修改状态的安全方法
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	default:
+		return false
+	}
+}
+
+// checkCSRFOrigin 校验请求来源，返回失败原因
+// 优先使用 Origin；没有 Origin 时，HTTPS 请求必须带有 Referer（与 Django 的策略一致）
+func checkCSRFOrigin(r *http.Request, trusted *originMatcher) string {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		referer := r.Header.Get("Referer")
+		if referer == "" {
+			if httpx.Scheme(r) == "https" {
+				return "missing referer"
+			}
+			return ""
+		}
+		u, err := url.Parse(referer)
+		if err != nil || u.Host == "" {
+			return "invalid referer"
+		}
+		origin = u.Scheme + "://" + u.Host
+	}
+	if origin == "null" {
+		return "untrusted origin"
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return "invalid origin"
+	}
+	// 同源: Origin 与请求的协议和 Host 一致，代理之后需要先挂载 NewProxyMiddleware
+	if strings.EqualFold(u.Host, httpx.Host(r)) && u.Scheme == httpx.Scheme(r) {
+		return ""
+	}
+	if trusted.match(origin) {
+		return ""
+	}
+	return "untrusted origin"
+}
+
+// csrfFailure 返回 403
+func csrfFailure(w http.ResponseWriter, r *http.Request, config *CSRFConfig, reason string) {
+	if config.OnFailure != nil {
+		config.OnFailure(r, reason)
+	}
+	log.Printf("[CSRF] request rejected, method: %s, path: %s, reason: %s", r.Method, r.URL.Path, reason)
+	httpx.SendResponseWithStatus(w, http.StatusForbidden, reason, nil)
+}
+
+// loadCSRFToken 读取已有的原始 token
+func loadCSRFToken(r *http.Request, config *CSRFConfig) ([]byte, bool) {
+	if config.Mode == CSRFSynchronizer {
+		s := session.Get(r)
+		if s == nil {
+			return nil, false
+		}
+		token, err := base64.RawURLEncoding.DecodeString(s.GetString(csrfSessionKey))
+		return token, err == nil && len(token) == csrfTokenLength
+	}
+
+	cookie, err := r.Cookie(config.CookieName)
+	if err != nil {
+		return nil, false
+	}
+	encoded, signature, ok := strings.Cut(cookie.Value, ".")
+	if !ok {
+		return nil, false
+	}
+	token, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(token) != csrfTokenLength {
+		return nil, false
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(sig, signCSRFToken(config.Secret, token)) {
+		return nil, false
+	}
+	return token, true
+}
+
+// saveCSRFToken 保存新生成的原始 token
+func saveCSRFToken(w http.ResponseWriter, r *http.Request, config *CSRFConfig, token []byte) {
+	if config.Mode == CSRFSynchronizer {
+		session.Get(r).Set(csrfSessionKey, base64.RawURLEncoding.EncodeToString(token))
+		return
+	}
+	// Cookie 中是签名后的原始 token，不能直接回传；客户端应回传响应头或 CSRFToken(r) 中的掩码 token
+	http.SetCookie(w, &http.Cookie{
+		Name:     config.CookieName,
+		Value:    base64.RawURLEncoding.EncodeToString(token) + "." + base64.RawURLEncoding.EncodeToString(signCSRFToken(config.Secret, token)),
+		Path:     config.CookiePath,
+		Domain:   config.CookieDomain,
+		MaxAge:   int(config.CookieMaxAge.Seconds()),
+		Secure:   !config.AllowInsecureCookie,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// signCSRFToken 计算 token 签名
+func signCSRFToken(secret, token []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(token)
+	return mac.Sum(nil)
+}
+
+// newCSRFToken 生成原始 token
+func newCSRFToken() []byte {
+	token := make([]byte, csrfTokenLength)
+	if _, err := rand.Read(token); err != nil {
+		panic("csrf: failed to read random bytes: " + err.Error())
+	}
+	return token
+}
+
+// maskCSRFToken 使用一次性随机数对 token 做异或掩码: base64url(otp || otp^token)
+func maskCSRFToken(token []byte) string {
+	otp := newCSRFToken()
+	masked := make([]byte, 2*csrfTokenLength)
+	copy(masked, otp)
+	for i := range token {
+		masked[csrfTokenLength+i] = otp[i] ^ token[i]
+	}
+	return base64.RawURLEncoding.EncodeToString(masked)
+}
+
+// validCSRFToken 去掉掩码后与原始 token 比较
+func validCSRFToken(submitted string, token []byte) bool {
+	masked, err := base64.RawURLEncoding.DecodeString(submitted)
+	if err != nil || len(masked) != 2*csrfTokenLength {
+		return false
+	}
+	unmasked := make([]byte, csrfTokenLength)
+	for i := range unmasked {
+		unmasked[i] = masked[i] ^ masked[csrfTokenLength+i]
+	}
+	return subtle.ConstantTimeCompare(unmasked, token) == 1
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCSRFDoubleSubmit(t *testing.T) {
+	mw, err := NewCSRFMiddleware(CSRFConfig{Secret: bytes.Repeat([]byte("s"), 32)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	// GET 下发 Cookie 和响应头中的 token
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://example.com/form", nil))
+	token := rec.Header().Get("X-CSRF-Token")
+	if token == "" {
+		t.Fatal("GET response has no X-CSRF-Token header")
+	}
+	var cookie *http.Cookie
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == "csrf_token" {
+			cookie = c
+		}
+	}
+	if cookie == nil {
+		t.Fatal("GET response did not set the csrf cookie")
+	}
+	if !cookie.HttpOnly || !cookie.Secure {
+		t.Errorf("csrf cookie HttpOnly = %v, Secure = %v, want both", cookie.HttpOnly, cookie.Secure)
+	}
+
+	post := func(submitted string) int {
+		r := httptest.NewRequest(http.MethodPost, "http://example.com/form", nil)
+		r.Header.Set("Origin", "http://example.com")
+		r.Header.Set("X-CSRF-Token", submitted)
+		r.AddCookie(cookie)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, r)
+		return rec.Code
+	}
+	if code := post(token); code != http.StatusOK {
+		t.Errorf("POST with the header token: status = %d, want 200", code)
+	}
+	// Cookie 的值是签名后的原始 token，不能作为 token 回传
+	if code := post(cookie.Value); code != http.StatusForbidden {
+		t.Errorf("POST echoing the cookie value: status = %d, want 403", code)
+	}
+	if code := post(""); code != http.StatusForbidden {
+		t.Errorf("POST without a token: status = %d, want 403", code)
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package middleware
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/stones-hub/taurus-pro-http/pkg/httpx"
+)
+
+// IPFilterConfig IP 黑白名单配置
+type IPFilterConfig struct {
+	// Allow 白名单，IP 或 CIDR；为空表示允许所有不在黑名单中的 IP
+	Allow []string
+	// Deny 黑名单，IP 或 CIDR，优先于白名单
+	Deny []string
+	// OnDenied 请求被拒绝时的回调，可用于记录日志或指标
+	OnDenied func(r *http.Request, ip string)
+}
+
+// ipFilterLists 可热更新的黑白名单
+type ipFilterLists struct {
+	allow prefixSet
+	deny  prefixSet
+}
+
+// IPFilter IP 黑白名单过滤器，客户端 IP 通过 httpx.ClientIP 获取
+// 部署在代理之后时需要先挂载 NewProxyMiddleware，否则过滤的是代理的 IP
+type IPFilter struct {
+	lists    atomic.Pointer[ipFilterLists]
+	onDenied func(r *http.Request, ip string)
+}
+
+// NewIPFilter 创建 IP 黑白名单过滤器
+func NewIPFilter(config IPFilterConfig) (*IPFilter, error) {
+	f := &IPFilter{onDenied: config.OnDenied}
+	if err := f.Update(config.Allow, config.Deny); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Update 更新黑白名单，对之后的请求生效，可用于配置热加载；列表无效时保留原有名单并返回错误
+func (f *IPFilter) Update(allow, deny []string) error {
+	allowSet, err := parsePrefixes(allow)
+	if err != nil {
+		return fmt.Errorf("invalid ip allow list: %w", err)
+	}
+	denySet, err := parsePrefixes(deny)
+	if err != nil {
+		return fmt.Errorf("invalid ip deny list: %w", err)
+	}
+	f.lists.Store(&ipFilterLists{allow: allowSet, deny: denySet})
+	return nil
+}
+
+// Allowed 判断 IP 是否允许访问，无法解析的 IP 只在没有白名单时允许
+func (f *IPFilter) Allowed(ip string) bool {
+	lists := f.lists.Load()
+	addr, ok := parseIP(ip)
+	if !ok {
+		return len(lists.allow) == 0
+	}
+	if lists.deny.contains(addr) {
+		return false
+	}
+	return len(lists.allow) == 0 || lists.allow.contains(addr)
+}
+
+// Middleware 返回 IP 过滤中间件，不允许的请求返回 403
+func (f *IPFilter) Middleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := httpx.ClientIP(r)
+			if !f.Allowed(ip) {
+				if f.onDenied != nil {
+					f.onDenied(r, ip)
+				}
+				log.Printf("[IPFilter] request denied, ip: %s, method: %s, path: %s", ip, r.Method, r.URL.Path)
+				httpx.SendResponseWithStatus(w, http.StatusForbidden, "access denied", nil)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,333 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package middleware
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/stones-hub/taurus-pro-http/pkg/httpx"
+)
+
+// Priority 请求优先级，过载时优先丢弃低优先级请求
+type Priority int
+
+const (
+	PriorityLow      Priority = iota - 1 // 低优先级：报表、批量导出等，过载时最先丢弃
+	PriorityNormal                       // 普通优先级（默认）
+	PriorityHigh                         // 高优先级：用户关键操作
+	PriorityCritical                     // 关键请求：健康检查、支付回调等，不因 CPU/延迟过载而丢弃
+)
+
+// String 优先级名称
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityNormal:
+		return "normal"
+	case PriorityHigh:
+		return "high"
+	case PriorityCritical:
+		return "critical"
+	default:
+		return strconv.Itoa(int(p))
+	}
+}
+
+// LoadShedConfig 过载保护配置
+type LoadShedConfig struct {
+	// MaxConcurrent 最大并发处理数，<=0 表示不限制
+	MaxConcurrent int
+	// MaxQueue 并发已满时允许排队的最大请求数，0 表示不排队直接拒绝
+	MaxQueue int
+	// QueueTimeout 排队最长等待时间，超时返回 503，默认 1 秒
+	// 请求上下文的 deadline 更早时以上下文为准
+	QueueTimeout time.Duration
+
+	// LatencyThreshold 请求平均延迟（EWMA）阈值，超过后按优先级丢弃请求，0 表示不启用
+	LatencyThreshold time.Duration
+	// CPUThreshold 进程 CPU 使用率阈值 (0, 1]，相对于 GOMAXPROCS，超过后按优先级丢弃请求，0 表示不启用
+	CPUThreshold float64
+	// CPUSampleInterval CPU 采样周期，默认 500ms
+	CPUSampleInterval time.Duration
+
+	// RoutePriorities 按路由模式设置优先级，key 与 httpx.RoutePattern 返回值一致
+	RoutePriorities map[string]Priority
+	// PriorityFunc 自定义优先级判断，优先于 RoutePriorities
+	PriorityFunc func(r *http.Request) Priority
+	// RetryAfter 拒绝时返回的 Retry-After，默认 1 秒
+	RetryAfter time.Duration
+	// OnShed 请求被丢弃时的回调
+	OnShed func(r *http.Request, priority Priority, reason string)
+}
+
+// latencyDecayInterval EWMA 延迟的衰减周期，周期内没有请求完成时延迟减半
+// 所有非关键请求都被丢弃后不再有新的延迟样本，衰减保证负载下降后能恢复放行
+const latencyDecayInterval = time.Second
+
+// LoadShedder 过载保护器
+// 1. 并发控制: 超过 MaxConcurrent 的请求进入队列，队列满或等待超时返回 503
+// 2. 自适应丢弃: CPU 或延迟超过阈值时，按过载程度从低优先级开始丢弃
+// 3. 队列按优先级分配: 低优先级只能使用一半队列，高优先级可以使用全部队列
+type LoadShedder struct {
+	config  LoadShedConfig
+	slots   chan struct{}
+	queued  atomic.Int64
+	latency atomic.Int64 // EWMA 延迟（纳秒）
+	sampled atomic.Bool  // 上次衰减之后是否有请求完成
+	cpu     atomic.Uint64
+	stop    chan struct{}
+	once    sync.Once
+}
+
+// NewLoadShedder 创建过载保护器
+func NewLoadShedder(config LoadShedConfig) (*LoadShedder, error) {
+	if config.MaxQueue < 0 {
+		return nil, fmt.Errorf("MaxQueue must not be negative")
+	}
+	if config.CPUThreshold < 0 || config.CPUThreshold > 1 {
+		return nil, fmt.Errorf("CPUThreshold must be within (0, 1]")
+	}
+	if config.QueueTimeout <= 0 {
+		config.QueueTimeout = time.Second
+	}
+	if config.CPUSampleInterval <= 0 {
+		config.CPUSampleInterval = 500 * time.Millisecond
+	}
+	if config.RetryAfter <= 0 {
+		config.RetryAfter = time.Second
+	}
+
+	ls := &LoadShedder{config: config, stop: make(chan struct{})}
+	if config.MaxConcurrent > 0 {
+		ls.slots = make(chan struct{}, config.MaxConcurrent)
+	}
+	if config.CPUThreshold > 0 {
+		go ls.sampleCPU()
+	}
+	if config.LatencyThreshold > 0 {
+		go ls.decayLatency()
+	}
+	return ls, nil
+}
+
+// Middleware 返回过载保护中间件
+func (ls *LoadShedder) Middleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			priority := ls.priority(r)
+
+			if reason := ls.overloaded(priority); reason != "" {
+				ls.reject(w, r, priority, reason)
+				return
+			}
+
+			if ls.slots != nil {
+				if reason := ls.acquire(r, priority); reason != "" {
+					ls.reject(w, r, priority, reason)
+					return
+				}
+				defer func() { <-ls.slots }()
+			}
+
+			start := time.Now()
+			next.ServeHTTP(w, r)
+			ls.observe(time.Since(start))
+		})
+	}
+}
+
+// Close 停止 CPU 采样和延迟衰减协程
+func (ls *LoadShedder) Close() {
+	ls.once.Do(func() { close(ls.stop) })
+}
+
+// Stats 返回当前状态：并发数、排队数、EWMA 延迟、CPU 使用率
+func (ls *LoadShedder) Stats() (inFlight int, queued int, latency time.Duration, cpu float64) {
+	if ls.slots != nil {
+		inFlight = len(ls.slots)
+	}
+	return inFlight, int(ls.queued.Load()), time.Duration(ls.latency.Load()), math.Float64frombits(ls.cpu.Load())
+}
+
+// priority 获取请求优先级
+func (ls *LoadShedder) priority(r *http.Request) Priority {
+	if ls.config.PriorityFunc != nil {
+		return ls.config.PriorityFunc(r)
+	}
+	if p, ok := ls.config.RoutePriorities[httpx.RoutePattern(r)]; ok {
+		return p
+	}
+	return PriorityNormal
+}
+
+// overloaded 根据 CPU 和延迟判断是否需要丢弃该优先级的请求，返回丢弃原因
+// 过载程度 pressure = max(延迟/阈值, CPU/阈值)
+//   - pressure >= 1.0: 丢弃 low
+//   - pressure >= 1.25: 丢弃 normal
+//   - pressure >= 1.5: 丢弃 high
+//   - critical 不丢弃
+func (ls *LoadShedder) overloaded(priority Priority) string {
+	if priority >= PriorityCritical {
+		return ""
+	}
+	pressure, reason := 0.0, ""
+	if ls.config.LatencyThreshold > 0 {
+		if p := float64(ls.latency.Load()) / float64(ls.config.LatencyThreshold); p > pressure {
+			pressure, reason = p, "latency"
+		}
+	}
+	if ls.config.CPUThreshold > 0 {
+		if p := math.Float64frombits(ls.cpu.Load()) / ls.config.CPUThreshold; p > pressure {
+			pressure, reason = p, "cpu"
+		}
+	}
+
+	var limit float64
+	switch {
+	case priority <= PriorityLow:
+		limit = 1.0
+	case priority == PriorityNormal:
+		limit = 1.25
+	default:
+		limit = 1.5
+	}
+	if pressure >= limit {
+		return reason
+	}
+	return ""
+}
+
+// acquire 获取并发槽位，必要时排队等待，失败时返回原因
+func (ls *LoadShedder) acquire(r *http.Request, priority Priority) string {
+	select {
+	case ls.slots <- struct{}{}:
+		return ""
+	default:
+	}
+
+	// 低优先级只能使用一半队列，保证高优先级请求有排队空间
+	queueLimit := int64(ls.config.MaxQueue)
+	if priority <= PriorityLow {
+		queueLimit /= 2
+	}
+	if ls.queued.Add(1) > queueLimit {
+		ls.queued.Add(-1)
+		return "queue_full"
+	}
+	defer ls.queued.Add(-1)
+
+	timer := time.NewTimer(ls.config.QueueTimeout)
+	defer timer.Stop()
+	select {
+	case ls.slots <- struct{}{}:
+		return ""
+	case <-timer.C:
+		return "queue_timeout"
+	case <-r.Context().Done():
+		return "client_gone"
+	}
+}
+
+// observe 更新 EWMA 延迟，权重 0.1
+func (ls *LoadShedder) observe(d time.Duration) {
+	for {
+		old := ls.latency.Load()
+		next := int64(d)
+		if old != 0 {
+			next = int64(0.9*float64(old) + 0.1*float64(d))
+		}
+		if ls.latency.CompareAndSwap(old, next) {
+			ls.sampled.Store(true)
+			return
+		}
+	}
+}
+
+// decayLatency 周期性衰减 EWMA 延迟，周期内有请求完成时不衰减
+func (ls *LoadShedder) decayLatency() {
+	ticker := time.NewTicker(latencyDecayInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ls.stop:
+			return
+		case <-ticker.C:
+			if ls.sampled.Swap(false) {
+				continue
+			}
+			for {
+				old := ls.latency.Load()
+				if old == 0 || ls.latency.CompareAndSwap(old, old/2) {
+					break
+				}
+			}
+		}
+	}
+}
+
+// reject 返回 503
+func (ls *LoadShedder) reject(w http.ResponseWriter, r *http.Request, priority Priority, reason string) {
+	if ls.config.OnShed != nil {
+		ls.config.OnShed(r, priority, reason)
+	}
+	if reason == "client_gone" {
+		// 客户端已断开，不需要响应
+		return
+	}
+	log.Printf("[LoadShed] request shed, route: %s, priority: %s, reason: %s", httpx.RoutePattern(r), priority, reason)
+	httpx.SendResponseWithStatus(w, http.StatusServiceUnavailable, "server overloaded", map[string]string{
+		"Retry-After": strconv.Itoa(ceilSeconds(ls.config.RetryAfter)),
+	})
+}
+
+// sampleCPU 周期性采样进程 CPU 使用率（相对于 GOMAXPROCS）
+// 当前平台不支持获取进程 CPU 时间时不启用 CPU 过载判断
+func (ls *LoadShedder) sampleCPU() {
+	lastCPU, ok := processCPUTime()
+	if !ok {
+		log.Printf("[LoadShed] process cpu time is not supported on this platform, cpu based shedding disabled")
+		return
+	}
+	lastWall := time.Now()
+
+	ticker := time.NewTicker(ls.config.CPUSampleInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ls.stop:
+			return
+		case now := <-ticker.C:
+			cpu, _ := processCPUTime()
+			if wall := now.Sub(lastWall); wall > 0 {
+				usage := float64(cpu-lastCPU) / (float64(wall) * float64(runtime.GOMAXPROCS(0)))
+				ls.cpu.Store(math.Float64bits(math.Max(0, math.Min(1, usage))))
+			}
+			lastCPU, lastWall = cpu, now
+		}
+	}
+}
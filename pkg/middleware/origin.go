@@ -0,0 +1,131 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package middleware
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// originMatcher Origin 白名单，格式与 CorsConfig.AllowOrigins 相同
+// 在创建中间件时解析一次，避免每个请求重复分割字符串
+type originMatcher struct {
+	any       bool
+	exact     map[string]bool
+	wildcards []originWildcard
+	regexps   []*regexp.Regexp
+}
+
+// originWildcard 子域名通配，如 https://*.example.com 解析为 prefix "https://"、suffix ".example.com"
+type originWildcard struct {
+	prefix string
+	suffix string
+}
+
+// newOriginMatcher 解析逗号分隔的 Origin 列表
+// "*" 表示允许所有 Origin；"https://*.example.com" 匹配 example.com 的任意子域名（不包括 example.com 本身）
+func newOriginMatcher(origins string) (*originMatcher, error) {
+	m := &originMatcher{exact: make(map[string]bool)}
+	for _, origin := range strings.Split(origins, ",") {
+		origin = strings.TrimSpace(origin)
+		if origin == "" {
+			continue
+		}
+		if origin == "*" {
+			m.any = true
+			continue
+		}
+		if scheme, host, ok := strings.Cut(origin, "://*."); ok {
+			// 用一个具体的子域名校验通配符之后的部分
+			if !validateOrigin(scheme+"://x."+host) || strings.Contains(host, "*") {
+				return nil, fmt.Errorf("无效的 Origin: %s", origin)
+			}
+			m.wildcards = append(m.wildcards, originWildcard{
+				prefix: strings.ToLower(scheme) + "://",
+				suffix: "." + normalizeOrigin(host),
+			})
+			continue
+		}
+		if !validateOrigin(origin) {
+			return nil, fmt.Errorf("无效的 Origin: %s", origin)
+		}
+		m.exact[normalizeOrigin(origin)] = true
+	}
+	return m, nil
+}
+
+// MatchOrigins 解析 Origin 列表并返回匹配函数，格式与 CorsConfig.AllowOrigins 相同
+// 供 WebSocket 等不经过 CORS 中间件、但需要校验 Origin 的场景复用；列表为空时不匹配任何 Origin
+func MatchOrigins(origins []string) (func(origin string) bool, error) {
+	m, err := newOriginMatcher(strings.Join(origins, ","))
+	if err != nil {
+		return nil, err
+	}
+	return m.match, nil
+}
+
+// addRegexps 添加正则表达式形式的 Origin，表达式会自动加上 ^ 和 $ 进行完整匹配
+func (m *originMatcher) addRegexps(patterns []string) error {
+	for _, pattern := range patterns {
+		re, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return fmt.Errorf("无效的 Origin 正则表达式 %q: %w", pattern, err)
+		}
+		m.regexps = append(m.regexps, re)
+	}
+	return nil
+}
+
+// match 判断 Origin 是否在白名单中
+func (m *originMatcher) match(origin string) bool {
+	if m.any {
+		return true
+	}
+	origin = normalizeOrigin(origin)
+	if m.exact[origin] {
+		return true
+	}
+	for _, w := range m.wildcards {
+		if strings.HasPrefix(origin, w.prefix) && strings.HasSuffix(origin, w.suffix) &&
+			len(origin) > len(w.prefix)+len(w.suffix) {
+			// 通配符只匹配子域名部分，不能跨越 scheme 和端口
+			sub := origin[len(w.prefix) : len(origin)-len(w.suffix)]
+			if !strings.ContainsAny(sub, "/:@") {
+				return true
+			}
+		}
+	}
+	for _, re := range m.regexps {
+		if re.MatchString(origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// empty 是否没有配置任何 Origin
+func (m *originMatcher) empty() bool {
+	return !m.any && len(m.exact) == 0 && len(m.wildcards) == 0 && len(m.regexps) == 0
+}
+
+// normalizeOrigin 统一为小写并去掉末尾的 /
+func normalizeOrigin(origin string) string {
+	return strings.ToLower(strings.TrimSuffix(origin, "/"))
+}
@@ -0,0 +1,244 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/stones-hub/taurus-pro-http/pkg/httpx"
+)
+
+// PrivateProxyRanges 回环地址和私有网段，代理与服务部署在同一内网时可以直接使用
+var PrivateProxyRanges = []string{
+	"127.0.0.0/8", "10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", // IPv4
+	"::1/128", "fc00::/7", // IPv6
+}
+
+// ProxyConfig 可信代理配置
+type ProxyConfig struct {
+	// TrustedProxies 可信代理的 IP 或 CIDR，如 "10.0.0.0/8"、"192.168.1.10"、"::1"
+	// 只有直接连接的对端在列表中时才会读取转发头，否则转发头可能是客户端伪造的
+	TrustedProxies []string
+	// ClientIPHeaders 读取客户端 IP 的请求头，按顺序使用第一个存在的
+	// 支持 Forwarded（RFC 7239）、X-Forwarded-For 和 X-Real-IP 等单值请求头，默认为这三个
+	ClientIPHeaders []string
+}
+
+// NewProxyMiddleware 创建可信代理中间件，解析真实的客户端 IP、协议和 Host 写入请求上下文
+// 处理器和其他中间件通过 httpx.ClientIP(r)、httpx.Scheme(r)、httpx.Host(r) 获取
+// 客户端 IP 从转发链的右侧开始查找，跳过可信代理，第一个不可信的地址即为客户端
+// 协议和 Host 取 X-Forwarded-Proto / X-Forwarded-Host（或 Forwarded 的 proto / host）中最后一个值，即离服务最近的代理设置的值
+// 需要放在使用客户端 IP 的中间件（访问日志、限流、IP 黑白名单等）之前
+func NewProxyMiddleware(config ProxyConfig) (func(http.Handler) http.Handler, error) {
+	trusted, err := parsePrefixes(config.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trusted proxies: %w", err)
+	}
+	headers := config.ClientIPHeaders
+	if len(headers) == 0 {
+		headers = []string{"Forwarded", "X-Forwarded-For", "X-Real-IP"}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			info := resolveRemoteInfo(r, trusted, headers)
+			next.ServeHTTP(w, r.WithContext(httpx.WithRemoteInfo(r.Context(), info)))
+		})
+	}, nil
+}
+
+// resolveRemoteInfo 根据转发头解析客户端信息
+func resolveRemoteInfo(r *http.Request, trusted prefixSet, headers []string) httpx.RemoteInfo {
+	info := httpx.RemoteInfo{ClientIP: r.RemoteAddr, Scheme: "http", Host: r.Host}
+	if r.TLS != nil {
+		info.Scheme = "https"
+	}
+	peer, ok := parseIP(r.RemoteAddr)
+	if !ok {
+		return info
+	}
+	info.ClientIP = peer.String()
+	if !trusted.contains(peer) {
+		return info
+	}
+
+	for _, name := range headers {
+		var chain []string
+		if strings.EqualFold(name, "Forwarded") {
+			for _, element := range forwardedElements(r.Header) {
+				if v, ok := element["for"]; ok {
+					chain = append(chain, v)
+				}
+			}
+		} else {
+			chain = splitHeaderList(r.Header.Values(name))
+		}
+		if len(chain) == 0 {
+			continue
+		}
+		// 从右向左查找第一个不可信的地址，全部可信时使用最左侧的地址
+		for i := len(chain) - 1; i >= 0; i-- {
+			ip, ok := parseIP(chain[i])
+			if !ok {
+				break
+			}
+			info.ClientIP = ip.String()
+			if !trusted.contains(ip) {
+				break
+			}
+		}
+		break
+	}
+
+	scheme, host := forwardedProtoHost(r.Header)
+	if scheme == "" {
+		scheme = lastHeaderValue(r.Header.Values("X-Forwarded-Proto"))
+	}
+	if host == "" {
+		host = lastHeaderValue(r.Header.Values("X-Forwarded-Host"))
+	}
+	if scheme = strings.ToLower(scheme); scheme == "http" || scheme == "https" {
+		info.Scheme = scheme
+	}
+	if validForwardedHost(host) {
+		info.Host = host
+	}
+	return info
+}
+
+// forwardedElements 解析 Forwarded 请求头，每个元素为 key=value 对，key 为小写
+// 如 Forwarded: for=192.0.2.60;proto=https;host=example.com, for="[2001:db8::1]:4711"
+func forwardedElements(header http.Header) []map[string]string {
+	var elements []map[string]string
+	for _, item := range splitHeaderList(header.Values("Forwarded")) {
+		element := make(map[string]string)
+		for _, pair := range strings.Split(item, ";") {
+			key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok {
+				continue
+			}
+			element[strings.ToLower(strings.TrimSpace(key))] = strings.Trim(strings.TrimSpace(value), `"`)
+		}
+		elements = append(elements, element)
+	}
+	return elements
+}
+
+// forwardedProtoHost 从 Forwarded 请求头中取最后一个 proto 和 host
+func forwardedProtoHost(header http.Header) (proto, host string) {
+	for _, element := range forwardedElements(header) {
+		if v, ok := element["proto"]; ok {
+			proto = v
+		}
+		if v, ok := element["host"]; ok {
+			host = v
+		}
+	}
+	return proto, host
+}
+
+// splitHeaderList 将多个请求头值按逗号拆分为列表
+func splitHeaderList(values []string) []string {
+	var items []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
+
+// lastHeaderValue 取逗号分隔列表中的最后一个值
+func lastHeaderValue(values []string) string {
+	items := splitHeaderList(values)
+	if len(items) == 0 {
+		return ""
+	}
+	return items[len(items)-1]
+}
+
+// validForwardedHost 转发的 Host 只能包含主机名和端口，避免被用于构造恶意链接
+func validForwardedHost(host string) bool {
+	if host == "" || len(host) > 255 || strings.ContainsAny(host, "/\\@?# \t") {
+		return false
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return host != ""
+}
+
+// parseIP 解析 IP，兼容 "ip:port"、"[ipv6]:port" 和 "[ipv6]" 格式，IPv4 映射的 IPv6 地址转换为 IPv4
+func parseIP(s string) (netip.Addr, bool) {
+	s = strings.TrimSpace(s)
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+	ip, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return ip.Unmap(), true
+}
+
+// prefixSet IP 网段集合
+type prefixSet []netip.Prefix
+
+// parsePrefixes 解析 IP 或 CIDR 列表，单个 IP 视为 /32 或 /128
+func parsePrefixes(items []string) (prefixSet, error) {
+	set := make(prefixSet, 0, len(items))
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if strings.Contains(item, "/") {
+			prefix, err := netip.ParsePrefix(item)
+			if err != nil {
+				return nil, fmt.Errorf("invalid CIDR %q: %w", item, err)
+			}
+			set = append(set, prefix.Masked())
+			continue
+		}
+		ip, err := netip.ParseAddr(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IP %q: %w", item, err)
+		}
+		ip = ip.Unmap()
+		set = append(set, netip.PrefixFrom(ip, ip.BitLen()))
+	}
+	return set, nil
+}
+
+// contains 判断 IP 是否在集合中
+func (s prefixSet) contains(ip netip.Addr) bool {
+	for _, prefix := range s {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,307 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package middleware
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/stones-hub/taurus-pro-http/pkg/httpx"
+)
+
+// RateLimitAlgorithm 限流算法
+type RateLimitAlgorithm string
+
+const (
+	RateLimitTokenBucket   RateLimitAlgorithm = "token_bucket"   // 令牌桶：允许一定突发，平均速率受限
+	RateLimitSlidingWindow RateLimitAlgorithm = "sliding_window" // 滑动窗口：严格限制任意窗口内的请求数
+)
+
+// RateLimitPolicy 限流策略
+type RateLimitPolicy struct {
+	// Limit 每个窗口允许的请求数
+	Limit int
+	// Window 窗口大小，如 time.Minute 表示每分钟 Limit 次
+	Window time.Duration
+	// Burst 令牌桶容量（允许的最大突发），<=0 时等于 Limit，仅令牌桶算法有效
+	Burst int
+	// Algorithm 限流算法，默认令牌桶
+	Algorithm RateLimitAlgorithm
+}
+
+// burst 令牌桶容量
+func (p RateLimitPolicy) burst() int {
+	if p.Burst > 0 {
+		return p.Burst
+	}
+	return p.Limit
+}
+
+// ratePerSecond 令牌桶每秒补充的令牌数
+func (p RateLimitPolicy) ratePerSecond() float64 {
+	return float64(p.Limit) / p.Window.Seconds()
+}
+
+// Validate 校验策略，可用于在加载配置时提前发现错误
+func (p RateLimitPolicy) Validate() error {
+	if p.Limit <= 0 {
+		return fmt.Errorf("limit must be positive")
+	}
+	if p.Window <= 0 {
+		return fmt.Errorf("window must be positive")
+	}
+	switch p.Algorithm {
+	case "", RateLimitTokenBucket, RateLimitSlidingWindow:
+	default:
+		return fmt.Errorf("unknown algorithm %q", p.Algorithm)
+	}
+	return nil
+}
+
+// RateLimitResult 一次限流检查的结果
+type RateLimitResult struct {
+	Allowed    bool          // 是否允许
+	Limit      int           // 配额上限
+	Remaining  int           // 剩余配额
+	Reset      time.Duration // 配额完全恢复（或窗口结束）还需要的时间
+	RetryAfter time.Duration // 被拒绝时建议的重试等待时间
+}
+
+// RateLimitKeyFunc 从请求中提取限流 key，返回 false 表示不限流（如未登录用户不按用户限流）
+type RateLimitKeyFunc func(r *http.Request) (string, bool)
+
+// KeyByIP 按客户端 IP 限流，部署在代理之后时需要先挂载 NewProxyMiddleware
+func KeyByIP() RateLimitKeyFunc {
+	return func(r *http.Request) (string, bool) {
+		return "ip:" + httpx.ClientIP(r), true
+	}
+}
+
+// KeyByHeader 按请求头限流，如 API Key；请求头不存在时不限流
+func KeyByHeader(header string) RateLimitKeyFunc {
+	return func(r *http.Request) (string, bool) {
+		value := r.Header.Get(header)
+		if value == "" {
+			return "", false
+		}
+		return "header:" + header + ":" + value, true
+	}
+}
+
+// KeyByAPIKey 按 API Key 请求头限流，header 为空时默认 X-Api-Key
+func KeyByAPIKey(header string) RateLimitKeyFunc {
+	if header == "" {
+		header = "X-Api-Key"
+	}
+	return KeyByHeader(header)
+}
+
+// KeyByUser 按认证用户限流，userFunc 返回用户标识，返回空字符串时不限流
+func KeyByUser(userFunc func(r *http.Request) string) RateLimitKeyFunc {
+	return func(r *http.Request) (string, bool) {
+		user := userFunc(r)
+		if user == "" {
+			return "", false
+		}
+		return "user:" + user, true
+	}
+}
+
+// KeyByRoute 按路由模式限流，同一路由的所有请求共享配额
+func KeyByRoute() RateLimitKeyFunc {
+	return func(r *http.Request) (string, bool) {
+		return "route:" + r.Method + " " + httpx.RoutePattern(r), true
+	}
+}
+
+// KeyFirst 依次尝试多个 key 函数，使用第一个有效的 key
+// 示例: KeyFirst(KeyByUser(fn), KeyByIP()) 登录用户按用户限流，未登录按 IP 限流
+func KeyFirst(funcs ...RateLimitKeyFunc) RateLimitKeyFunc {
+	return func(r *http.Request) (string, bool) {
+		for _, fn := range funcs {
+			if key, ok := fn(r); ok {
+				return key, true
+			}
+		}
+		return "", false
+	}
+}
+
+// RateLimitConfig 限流配置
+type RateLimitConfig struct {
+	// Store 计数存储，默认内存存储
+	Store RateLimitStore
+	// Policy 默认策略
+	Policy RateLimitPolicy
+	// RoutePolicies 按路由模式覆盖策略，key 为路由模式（与 httpx.RoutePattern 返回值一致），如 "/api/login"
+	// 命中路由策略时，计数按路由隔离
+	RoutePolicies map[string]RateLimitPolicy
+	// KeyFunc 限流 key，默认按客户端 IP
+	KeyFunc RateLimitKeyFunc
+	// FailClosed 存储出错时是否拒绝请求，默认放行（避免 Redis 故障导致服务不可用）
+	FailClosed bool
+	// DisableHeaders 不输出 RateLimit-* 响应头
+	DisableHeaders bool
+	// OnLimited 请求被限流时的回调，可用于记录日志或指标
+	OnLimited func(r *http.Request, key string, result RateLimitResult)
+}
+
+// rateLimitSettings 可热更新的限流策略
+type rateLimitSettings struct {
+	policy        RateLimitPolicy
+	routePolicies map[string]RateLimitPolicy
+}
+
+// RateLimiter 限流器，策略可以通过 Update 热更新
+type RateLimiter struct {
+	settings       atomic.Pointer[rateLimitSettings]
+	store          RateLimitStore
+	ownedStore     *MemoryRateLimitStore
+	keyFunc        RateLimitKeyFunc
+	failClosed     bool
+	disableHeaders bool
+	onLimited      func(r *http.Request, key string, result RateLimitResult)
+}
+
+// NewRateLimiter 创建限流器，配置无效时返回错误
+// 未设置 Store 时创建内存存储，不再使用时需要调用 Close 停止其清理协程
+func NewRateLimiter(config RateLimitConfig) (*RateLimiter, error) {
+	if config.KeyFunc == nil {
+		config.KeyFunc = KeyByIP()
+	}
+	l := &RateLimiter{
+		store:          config.Store,
+		keyFunc:        config.KeyFunc,
+		failClosed:     config.FailClosed,
+		disableHeaders: config.DisableHeaders,
+		onLimited:      config.OnLimited,
+	}
+	if err := l.Update(config.Policy, config.RoutePolicies); err != nil {
+		return nil, err
+	}
+	if l.store == nil {
+		l.ownedStore = NewMemoryRateLimitStore(0)
+		l.store = l.ownedStore
+	}
+	return l, nil
+}
+
+// Close 关闭限流器自己创建的内存存储，调用方传入的 Store 由调用方负责关闭
+func (l *RateLimiter) Close() {
+	if l.ownedStore != nil {
+		l.ownedStore.Close()
+	}
+}
+
+// Update 更新限流策略，对之后的请求生效，可用于配置热加载；策略无效时返回错误并保留原策略
+func (l *RateLimiter) Update(policy RateLimitPolicy, routePolicies map[string]RateLimitPolicy) error {
+	if err := policy.Validate(); err != nil {
+		return fmt.Errorf("invalid rate limit policy: %w", err)
+	}
+	routes := make(map[string]RateLimitPolicy, len(routePolicies))
+	for route, routePolicy := range routePolicies {
+		if err := routePolicy.Validate(); err != nil {
+			return fmt.Errorf("invalid rate limit policy for route %s: %w", route, err)
+		}
+		routes[route] = routePolicy
+	}
+	l.settings.Store(&rateLimitSettings{policy: policy, routePolicies: routes})
+	return nil
+}
+
+// NewRateLimitMiddleware 创建限流中间件，配置无效时返回错误
+// 响应头遵循 IETF RateLimit header fields 草案: RateLimit-Limit / RateLimit-Remaining / RateLimit-Reset / RateLimit-Policy
+// 被限流时返回 429 和 Retry-After
+// 未设置 Store 时内存存储随进程存在，需要释放时使用 NewRateLimiter 并调用 Close
+func NewRateLimitMiddleware(config RateLimitConfig) (func(http.Handler) http.Handler, error) {
+	l, err := NewRateLimiter(config)
+	if err != nil {
+		return nil, err
+	}
+	return l.Middleware(), nil
+}
+
+// Middleware 返回限流中间件
+func (l *RateLimiter) Middleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key, ok := l.keyFunc(r)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			settings := l.settings.Load()
+			policy := settings.policy
+			if routePolicy, found := settings.routePolicies[httpx.RoutePattern(r)]; found {
+				policy = routePolicy
+				key = httpx.RoutePattern(r) + "|" + key
+			}
+
+			result, err := l.store.Allow(r.Context(), key, policy)
+			if err != nil {
+				log.Printf("[RateLimit] store error, key: %s, error: %v", key, err)
+				if l.failClosed {
+					httpx.SendResponseWithStatus(w, http.StatusServiceUnavailable, "rate limiter unavailable", nil)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if !l.disableHeaders {
+				setRateLimitHeaders(w, policy, result)
+			}
+
+			if !result.Allowed {
+				if l.onLimited != nil {
+					l.onLimited(r, key, result)
+				}
+				httpx.SendResponseWithStatus(w, http.StatusTooManyRequests, "rate limit exceeded", map[string]string{
+					"Retry-After": strconv.Itoa(ceilSeconds(result.RetryAfter)),
+				})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// setRateLimitHeaders 设置 RateLimit-* 响应头
+func setRateLimitHeaders(w http.ResponseWriter, policy RateLimitPolicy, result RateLimitResult) {
+	h := w.Header()
+	h.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+	h.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+	h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", policy.Limit, ceilSeconds(policy.Window)))
+}
+
+// ceilSeconds 向上取整为秒
+func ceilSeconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,362 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RateLimitStore 限流计数存储
+// 实现需要保证并发安全，Allow 在一次调用内完成"检查并消费"
+type RateLimitStore interface {
+	Allow(ctx context.Context, key string, policy RateLimitPolicy) (RateLimitResult, error)
+}
+
+// ------------------------------------------------------------ 内存存储 ------------------------------------------------------------
+
+// MemoryRateLimitStore 基于内存的限流存储，适合单机部署
+type MemoryRateLimitStore struct {
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+	windows map[string]*slidingWindow
+	stop    chan struct{}
+	once    sync.Once
+	now     func() time.Time
+}
+
+// tokenBucket 令牌桶状态
+type tokenBucket struct {
+	tokens  float64
+	last    time.Time
+	expires time.Time
+}
+
+// slidingWindow 滑动窗口计数状态
+type slidingWindow struct {
+	index   int64 // 当前窗口序号
+	current int64 // 当前窗口计数
+	prev    int64 // 上一个窗口计数
+	expires time.Time
+}
+
+// NewMemoryRateLimitStore 创建内存限流存储
+// cleanupInterval 为过期数据清理周期，<=0 时默认 1 分钟
+func NewMemoryRateLimitStore(cleanupInterval time.Duration) *MemoryRateLimitStore {
+	if cleanupInterval <= 0 {
+		cleanupInterval = time.Minute
+	}
+	s := &MemoryRateLimitStore{
+		buckets: make(map[string]*tokenBucket),
+		windows: make(map[string]*slidingWindow),
+		stop:    make(chan struct{}),
+		now:     time.Now,
+	}
+	go s.cleanup(cleanupInterval)
+	return s
+}
+
+// Allow 检查并消费一次配额
+func (s *MemoryRateLimitStore) Allow(_ context.Context, key string, policy RateLimitPolicy) (RateLimitResult, error) {
+	now := s.now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if policy.Algorithm == RateLimitSlidingWindow {
+		return s.allowSlidingWindow(key, policy, now), nil
+	}
+	return s.allowTokenBucket(key, policy, now), nil
+}
+
+// allowTokenBucket 令牌桶算法
+func (s *MemoryRateLimitStore) allowTokenBucket(key string, policy RateLimitPolicy, now time.Time) RateLimitResult {
+	capacity := float64(policy.burst())
+	rate := policy.ratePerSecond()
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: capacity, last: now}
+		s.buckets[key] = b
+	}
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	// 桶回满之后状态与新建时一致，可以安全清理
+	b.expires = now.Add(secondsToDuration((capacity - b.tokens) / rate))
+	return tokenBucketResult(policy, allowed, b.tokens)
+}
+
+// allowSlidingWindow 滑动窗口计数算法
+func (s *MemoryRateLimitStore) allowSlidingWindow(key string, policy RateLimitPolicy, now time.Time) RateLimitResult {
+	index, elapsed := windowPosition(now, policy.Window)
+
+	w, ok := s.windows[key]
+	if !ok {
+		w = &slidingWindow{index: index}
+		s.windows[key] = w
+	}
+	switch {
+	case w.index == index:
+	case w.index == index-1:
+		w.prev, w.current, w.index = w.current, 0, index
+	default:
+		w.prev, w.current, w.index = 0, 0, index
+	}
+
+	allowed := slidingWindowAllowed(policy, w.prev, w.current, elapsed)
+	if allowed {
+		w.current++
+	}
+	w.expires = now.Add(2 * policy.Window)
+	return slidingWindowResult(policy, allowed, w.prev, w.current, elapsed)
+}
+
+// cleanup 周期性清理过期数据，避免内存无限增长
+func (s *MemoryRateLimitStore) cleanup(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case now := <-ticker.C:
+			s.mu.Lock()
+			for key, b := range s.buckets {
+				if now.After(b.expires) {
+					delete(s.buckets, key)
+				}
+			}
+			for key, w := range s.windows {
+				if now.After(w.expires) {
+					delete(s.windows, key)
+				}
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// Close 停止清理协程
+func (s *MemoryRateLimitStore) Close() {
+	s.once.Do(func() { close(s.stop) })
+}
+
+// ------------------------------------------------------------ Redis 存储 ------------------------------------------------------------
+
+// tokenBucketScript 令牌桶 Lua 脚本，保证"读取-计算-写回"的原子性
+// KEYS[1]: 桶 key
+// ARGV: 每毫秒补充令牌数, 桶容量, 当前时间(毫秒), 过期时间(毫秒)
+// 返回: {是否允许, 剩余令牌数(字符串，避免 Lua 数字被截断为整数)}
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local capacity = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local ttl = tonumber(ARGV[4])
+
+local data = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(data[1])
+local ts = tonumber(data[2])
+if tokens == nil or ts == nil then
+	tokens = capacity
+	ts = now
+end
+
+local elapsed = math.max(0, now - ts)
+tokens = math.min(capacity, tokens + elapsed * rate)
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now))
+redis.call('PEXPIRE', KEYS[1], ttl)
+return {allowed, tostring(tokens)}
+`)
+
+// slidingWindowScript 滑动窗口 Lua 脚本
+// KEYS[1]: 当前窗口 key, KEYS[2]: 上一个窗口 key
+// ARGV: 限制次数, 上一个窗口权重, 过期时间(毫秒)
+// 返回: {是否允许, 当前窗口计数, 上一个窗口计数}
+var slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local weight = tonumber(ARGV[2])
+local ttl = tonumber(ARGV[3])
+
+local current = tonumber(redis.call('GET', KEYS[1]) or '0')
+local prev = tonumber(redis.call('GET', KEYS[2]) or '0')
+
+if prev * weight + current + 1 > limit then
+	return {0, current, prev}
+end
+
+current = redis.call('INCR', KEYS[1])
+redis.call('PEXPIRE', KEYS[1], ttl)
+return {1, current, prev}
+`)
+
+// RedisRateLimitStore 基于 Redis 的限流存储，适合集群部署
+// 所有计算在 Lua 脚本中完成，多实例共享同一份计数
+type RedisRateLimitStore struct {
+	client redis.Scripter
+	prefix string
+	now    func() time.Time
+}
+
+// NewRedisRateLimitStore 创建 Redis 限流存储
+// client 可以是 *redis.Client、*redis.ClusterClient 等；prefix 为 key 前缀，默认 "ratelimit:"
+// 注意：集群模式下滑动窗口的两个 key 需要在同一个 slot，key 中已使用 {} hash tag
+func NewRedisRateLimitStore(client redis.Scripter, prefix string) *RedisRateLimitStore {
+	if prefix == "" {
+		prefix = "ratelimit:"
+	}
+	return &RedisRateLimitStore{client: client, prefix: prefix, now: time.Now}
+}
+
+// Allow 检查并消费一次配额
+func (s *RedisRateLimitStore) Allow(ctx context.Context, key string, policy RateLimitPolicy) (RateLimitResult, error) {
+	if policy.Algorithm == RateLimitSlidingWindow {
+		return s.allowSlidingWindow(ctx, key, policy)
+	}
+	return s.allowTokenBucket(ctx, key, policy)
+}
+
+// allowTokenBucket 令牌桶算法
+func (s *RedisRateLimitStore) allowTokenBucket(ctx context.Context, key string, policy RateLimitPolicy) (RateLimitResult, error) {
+	capacity := float64(policy.burst())
+	rate := policy.ratePerSecond()
+	// 桶从空到满所需时间，之后 key 自动过期
+	ttl := secondsToDuration(capacity/rate) + time.Second
+
+	res, err := tokenBucketScript.Run(ctx, s.client, []string{s.prefix + "tb:" + key},
+		strconv.FormatFloat(rate/1000, 'f', -1, 64),
+		strconv.FormatFloat(capacity, 'f', -1, 64),
+		s.now().UnixMilli(),
+		ttl.Milliseconds(),
+	).Slice()
+	if err != nil {
+		return RateLimitResult{}, fmt.Errorf("redis rate limit: %w", err)
+	}
+	if len(res) != 2 {
+		return RateLimitResult{}, fmt.Errorf("redis rate limit: unexpected script result %v", res)
+	}
+	allowed, _ := res[0].(int64)
+	tokensStr, _ := res[1].(string)
+	tokens, err := strconv.ParseFloat(tokensStr, 64)
+	if err != nil {
+		return RateLimitResult{}, fmt.Errorf("redis rate limit: invalid tokens %q: %w", tokensStr, err)
+	}
+	return tokenBucketResult(policy, allowed == 1, tokens), nil
+}
+
+// allowSlidingWindow 滑动窗口算法
+func (s *RedisRateLimitStore) allowSlidingWindow(ctx context.Context, key string, policy RateLimitPolicy) (RateLimitResult, error) {
+	index, elapsed := windowPosition(s.now(), policy.Window)
+	weight := 1 - float64(elapsed)/float64(policy.Window)
+	base := s.prefix + "sw:{" + key + "}:"
+
+	res, err := slidingWindowScript.Run(ctx, s.client,
+		[]string{base + strconv.FormatInt(index, 10), base + strconv.FormatInt(index-1, 10)},
+		policy.Limit,
+		strconv.FormatFloat(weight, 'f', -1, 64),
+		(2 * policy.Window).Milliseconds(),
+	).Int64Slice()
+	if err != nil {
+		return RateLimitResult{}, fmt.Errorf("redis rate limit: %w", err)
+	}
+	if len(res) != 3 {
+		return RateLimitResult{}, fmt.Errorf("redis rate limit: unexpected script result %v", res)
+	}
+	return slidingWindowResult(policy, res[0] == 1, res[2], res[1], elapsed), nil
+}
+
+// ------------------------------------------------------------ 公共计算 ------------------------------------------------------------
+
+// windowPosition 计算当前时间所在的窗口序号和窗口内已过去的时间
+func windowPosition(now time.Time, window time.Duration) (int64, time.Duration) {
+	ns := now.UnixNano()
+	return ns / int64(window), time.Duration(ns % int64(window))
+}
+
+// slidingWindowAllowed 按加权计数判断是否允许: prev * (1 - elapsed/window) + current < limit
+func slidingWindowAllowed(policy RateLimitPolicy, prev, current int64, elapsed time.Duration) bool {
+	weight := 1 - float64(elapsed)/float64(policy.Window)
+	return float64(prev)*weight+float64(current)+1 <= float64(policy.Limit)
+}
+
+// slidingWindowResult 计算滑动窗口的结果
+func slidingWindowResult(policy RateLimitPolicy, allowed bool, prev, current int64, elapsed time.Duration) RateLimitResult {
+	weight := 1 - float64(elapsed)/float64(policy.Window)
+	count := float64(prev)*weight + float64(current)
+	result := RateLimitResult{
+		Allowed:   allowed,
+		Limit:     policy.Limit,
+		Remaining: max(0, int(math.Floor(float64(policy.Limit)-count))),
+		Reset:     policy.Window - elapsed,
+	}
+	if allowed {
+		return result
+	}
+
+	// 计算需要等待多久加权计数才能降到 limit-1 以下
+	switch {
+	case current >= int64(policy.Limit) || prev == 0:
+		result.RetryAfter = policy.Window - elapsed
+	default:
+		need := 1 - float64(int64(policy.Limit)-1-current)/float64(prev)
+		wait := time.Duration(need*float64(policy.Window)) - elapsed
+		result.RetryAfter = max(wait, time.Second)
+	}
+	return result
+}
+
+// tokenBucketResult 计算令牌桶的结果
+func tokenBucketResult(policy RateLimitPolicy, allowed bool, tokens float64) RateLimitResult {
+	capacity := float64(policy.burst())
+	rate := policy.ratePerSecond()
+	result := RateLimitResult{
+		Allowed:   allowed,
+		Limit:     policy.burst(),
+		Remaining: int(math.Floor(tokens)),
+		Reset:     secondsToDuration((capacity - tokens) / rate),
+	}
+	if !allowed {
+		result.RetryAfter = secondsToDuration((1 - tokens) / rate)
+	}
+	return result
+}
+
+// secondsToDuration 秒数转换为 time.Duration
+func secondsToDuration(seconds float64) time.Duration {
+	if seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
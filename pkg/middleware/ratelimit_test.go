@@ -0,0 +1,324 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// fakeClock 可手动推进的时钟，起点对齐到整分钟，便于计算滑动窗口的位置
+type fakeClock struct {
+	t time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{t: time.Unix(1_800_000_000, 0)}
+}
+
+func (c *fakeClock) now() time.Time {
+	return c.t
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.t = c.t.Add(d)
+}
+
+// testStoreNames 需要测试的存储
+var testStoreNames = []string{"memory", "redis"}
+
+// newTestStore 创建使用 clock 的内存存储或 Redis 存储（miniredis）
+func newTestStore(t *testing.T, name string, clock *fakeClock) RateLimitStore {
+	t.Helper()
+	if name == "memory" {
+		store := NewMemoryRateLimitStore(0)
+		store.now = clock.now
+		t.Cleanup(store.Close)
+		return store
+	}
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	store := NewRedisRateLimitStore(client, "")
+	store.now = clock.now
+	return store
+}
+
+// step 一次 Allow 调用的期望结果
+type step struct {
+	advance    time.Duration
+	allowed    bool
+	remaining  int
+	reset      time.Duration
+	retryAfter time.Duration
+}
+
+func runSteps(t *testing.T, store RateLimitStore, clock *fakeClock, policy RateLimitPolicy, steps []step) {
+	t.Helper()
+	for i, s := range steps {
+		clock.advance(s.advance)
+		result, err := store.Allow(context.Background(), "k", policy)
+		if err != nil {
+			t.Fatalf("step %d: Allow: %v", i, err)
+		}
+		if result.Allowed != s.allowed || result.Remaining != s.remaining || result.Reset != s.reset || result.RetryAfter != s.retryAfter {
+			t.Errorf("step %d: got allowed=%v remaining=%d reset=%s retry_after=%s, want allowed=%v remaining=%d reset=%s retry_after=%s",
+				i, result.Allowed, result.Remaining, result.Reset, result.RetryAfter,
+				s.allowed, s.remaining, s.reset, s.retryAfter)
+		}
+	}
+}
+
+func TestRateLimitTokenBucket(t *testing.T) {
+	// 每秒补充 1 个令牌，桶容量 3
+	policy := RateLimitPolicy{Limit: 10, Window: 10 * time.Second, Burst: 3}
+	steps := []step{
+		{allowed: true, remaining: 2, reset: time.Second},
+		{allowed: true, remaining: 1, reset: 2 * time.Second},
+		{allowed: true, remaining: 0, reset: 3 * time.Second},
+		{allowed: false, remaining: 0, reset: 3 * time.Second, retryAfter: time.Second},
+		{advance: 500 * time.Millisecond, allowed: false, remaining: 0, reset: 2500 * time.Millisecond, retryAfter: 500 * time.Millisecond},
+		{advance: 500 * time.Millisecond, allowed: true, remaining: 0, reset: 3 * time.Second},
+		// 空闲足够久后桶回满，但不会超过容量
+		{advance: time.Minute, allowed: true, remaining: 2, reset: time.Second},
+	}
+	for _, name := range testStoreNames {
+		t.Run(name, func(t *testing.T) {
+			clock := newFakeClock()
+			runSteps(t, newTestStore(t, name, clock), clock, policy, steps)
+		})
+	}
+}
+
+func TestRateLimitSlidingWindow(t *testing.T) {
+	policy := RateLimitPolicy{Limit: 4, Window: time.Minute, Algorithm: RateLimitSlidingWindow}
+	steps := []step{
+		{allowed: true, remaining: 3, reset: time.Minute},
+		{allowed: true, remaining: 2, reset: time.Minute},
+		{allowed: true, remaining: 1, reset: time.Minute},
+		{allowed: true, remaining: 0, reset: time.Minute},
+		// 当前窗口已满，需要等到窗口结束
+		{allowed: false, remaining: 0, reset: time.Minute, retryAfter: time.Minute},
+		{advance: 45 * time.Second, allowed: false, remaining: 0, reset: 15 * time.Second, retryAfter: 15 * time.Second},
+		// 进入下一个窗口 30 秒，上一个窗口的 4 次按 50% 计入，加权计数为 2
+		{advance: 45 * time.Second, allowed: true, remaining: 1, reset: 30 * time.Second},
+		{allowed: true, remaining: 0, reset: 30 * time.Second},
+		// 加权计数 4*(1-elapsed/60)+2 在 elapsed=45s 时降到 3，可以再放行一次
+		{allowed: false, remaining: 0, reset: 30 * time.Second, retryAfter: 15 * time.Second},
+		{advance: 15 * time.Second, allowed: true, remaining: 0, reset: 15 * time.Second},
+	}
+	for _, name := range testStoreNames {
+		t.Run(name, func(t *testing.T) {
+			clock := newFakeClock()
+			runSteps(t, newTestStore(t, name, clock), clock, policy, steps)
+		})
+	}
+}
+
+// errorStore 总是返回错误的存储，模拟 Redis 故障
+type errorStore struct{}
+
+func (errorStore) Allow(context.Context, string, RateLimitPolicy) (RateLimitResult, error) {
+	return RateLimitResult{}, errors.New("connection refused")
+}
+
+func TestRateLimitMiddlewareHeaders(t *testing.T) {
+	for _, name := range testStoreNames {
+		t.Run(name, func(t *testing.T) {
+			store := newTestStore(t, name, newFakeClock())
+			var limited int
+			mw, err := NewRateLimitMiddleware(RateLimitConfig{
+				Store:     store,
+				Policy:    RateLimitPolicy{Limit: 2, Window: time.Minute},
+				OnLimited: func(r *http.Request, key string, result RateLimitResult) { limited++ },
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			handler := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+			want := []struct {
+				status     int
+				remaining  string
+				reset      string
+				retryAfter string
+			}{
+				{http.StatusOK, "1", "30", ""},
+				{http.StatusOK, "0", "60", ""},
+				{http.StatusTooManyRequests, "0", "60", "30"},
+			}
+			for i, w := range want {
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+				h := rec.Header()
+				if rec.Code != w.status {
+					t.Errorf("request %d: status = %d, want %d", i, rec.Code, w.status)
+				}
+				if h.Get("RateLimit-Limit") != "2" || h.Get("RateLimit-Policy") != "2;w=60" {
+					t.Errorf("request %d: RateLimit-Limit = %q, RateLimit-Policy = %q", i, h.Get("RateLimit-Limit"), h.Get("RateLimit-Policy"))
+				}
+				if h.Get("RateLimit-Remaining") != w.remaining || h.Get("RateLimit-Reset") != w.reset {
+					t.Errorf("request %d: RateLimit-Remaining = %q, RateLimit-Reset = %q, want %q, %q",
+						i, h.Get("RateLimit-Remaining"), h.Get("RateLimit-Reset"), w.remaining, w.reset)
+				}
+				if h.Get("Retry-After") != w.retryAfter {
+					t.Errorf("request %d: Retry-After = %q, want %q", i, h.Get("Retry-After"), w.retryAfter)
+				}
+			}
+			if limited != 1 {
+				t.Errorf("OnLimited called %d times, want 1", limited)
+			}
+		})
+	}
+}
+
+func TestRateLimitMiddlewareRoutePolicy(t *testing.T) {
+	mw, err := NewRateLimitMiddleware(RateLimitConfig{
+		Policy:        RateLimitPolicy{Limit: 100, Window: time.Minute},
+		RoutePolicies: map[string]RateLimitPolicy{"/login": {Limit: 1, Window: time.Minute}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/login", mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+	mux.Handle("/api", mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+
+	for i, want := range []struct {
+		path   string
+		status int
+	}{
+		{"/login", http.StatusOK},
+		{"/login", http.StatusTooManyRequests},
+		// 路由策略的计数与默认策略隔离
+		{"/api", http.StatusOK},
+	} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, want.path, nil))
+		if rec.Code != want.status {
+			t.Errorf("request %d %s: status = %d, want %d", i, want.path, rec.Code, want.status)
+		}
+	}
+}
+
+func TestRateLimitMiddlewareStoreError(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { client.Close() })
+	mr.Close()
+
+	stores := map[string]RateLimitStore{
+		"failing store": errorStore{},
+		"redis down":    NewRedisRateLimitStore(client, ""),
+	}
+	for name, store := range stores {
+		for _, failClosed := range []bool{false, true} {
+			called := false
+			mw, err := NewRateLimitMiddleware(RateLimitConfig{
+				Store:      store,
+				Policy:     RateLimitPolicy{Limit: 1, Window: time.Minute},
+				FailClosed: failClosed,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			rec := httptest.NewRecorder()
+			mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+			})).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			wantStatus, wantCalled := http.StatusOK, true
+			if failClosed {
+				wantStatus, wantCalled = http.StatusServiceUnavailable, false
+			}
+			if rec.Code != wantStatus || called != wantCalled {
+				t.Errorf("%s, FailClosed=%v: status = %d, handler called = %v, want %d, %v",
+					name, failClosed, rec.Code, called, wantStatus, wantCalled)
+			}
+		}
+	}
+}
+
+func TestRateLimiterUpdate(t *testing.T) {
+	limiter, err := NewRateLimiter(RateLimitConfig{Policy: RateLimitPolicy{Limit: 1, Window: time.Minute}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := limiter.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	serve := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		return rec
+	}
+
+	serve()
+	if rec := serve(); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", rec.Code)
+	}
+	if err := limiter.Update(RateLimitPolicy{Limit: 0, Window: time.Minute}, nil); err == nil {
+		t.Fatal("Update accepted an invalid policy")
+	}
+	if limit := serve().Header().Get("RateLimit-Limit"); limit != "1" {
+		t.Errorf("RateLimit-Limit after invalid update = %q, want the old limit 1", limit)
+	}
+	if err := limiter.Update(RateLimitPolicy{Limit: 5, Window: time.Minute}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if limit := serve().Header().Get("RateLimit-Limit"); limit != "5" {
+		t.Errorf("RateLimit-Limit after update = %q, want 5", limit)
+	}
+}
+
+func TestRateLimiterCloseStopsOwnedStore(t *testing.T) {
+	limiter, err := NewRateLimiter(RateLimitConfig{Policy: RateLimitPolicy{Limit: 1, Window: time.Minute}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if limiter.ownedStore == nil {
+		t.Fatal("limiter without Store did not create its own memory store")
+	}
+	limiter.Close()
+	limiter.Close()
+	select {
+	case <-limiter.ownedStore.stop:
+	default:
+		t.Error("Close did not stop the memory store cleanup goroutine")
+	}
+
+	// 调用方传入的存储由调用方关闭
+	store := NewMemoryRateLimitStore(0)
+	t.Cleanup(store.Close)
+	limiter, err = NewRateLimiter(RateLimitConfig{Store: store, Policy: RateLimitPolicy{Limit: 1, Window: time.Minute}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	limiter.Close()
+	select {
+	case <-store.stop:
+		t.Error("Close stopped a store passed in by the caller")
+	default:
+	}
+}
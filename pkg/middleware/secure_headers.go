@@ -0,0 +1,387 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stones-hub/taurus-pro-http/pkg/httpx"
+)
+
+// CSPNoncePlaceholder CSP 中的 nonce 占位符，每个请求替换为新的随机 nonce
+// 如 "script-src 'self' 'nonce-{nonce}'"
+const CSPNoncePlaceholder = "{nonce}"
+
+// cspReportEndpoint Reporting-Endpoints 中的端点名称
+const cspReportEndpoint = "csp-endpoint"
+
+// SecureHeadersConfig 安全响应头配置，字段为空表示不设置对应的响应头
+type SecureHeadersConfig struct {
+	// HSTSMaxAge Strict-Transport-Security 的 max-age，0 表示不设置；只在 HTTPS 请求中下发
+	HSTSMaxAge time.Duration
+	// HSTSIncludeSubdomains 是否包含子域名
+	HSTSIncludeSubdomains bool
+	// HSTSPreload 是否加入浏览器预加载列表，要求 max-age 至少 1 年且包含子域名
+	HSTSPreload bool
+
+	// ContentSecurityPolicy CSP 策略，可以使用 CSPNoncePlaceholder 占位符，处理器通过 CSPNonce(r) 获取 nonce
+	ContentSecurityPolicy string
+	// CSPReportOnly 只报告不拦截，使用 Content-Security-Policy-Report-Only 头，用于上线新策略前观察
+	CSPReportOnly bool
+	// CSPReportURI 违规报告地址，通常指向 CSPReportHandler
+	// 同时设置 report-uri（旧浏览器）和 report-to + Reporting-Endpoints（新浏览器）
+	CSPReportURI string
+
+	// ContentTypeNosniff 设置 X-Content-Type-Options: nosniff
+	ContentTypeNosniff bool
+	// FrameOptions X-Frame-Options，DENY 或 SAMEORIGIN
+	FrameOptions string
+	// ReferrerPolicy Referrer-Policy，如 no-referrer、strict-origin-when-cross-origin
+	ReferrerPolicy string
+	// PermissionsPolicy Permissions-Policy，如 "camera=(), microphone=(), geolocation=()"
+	PermissionsPolicy string
+	// CrossOriginOpenerPolicy Cross-Origin-Opener-Policy，如 same-origin
+	CrossOriginOpenerPolicy string
+	// CrossOriginEmbedderPolicy Cross-Origin-Embedder-Policy，如 require-corp
+	CrossOriginEmbedderPolicy string
+	// CrossOriginResourcePolicy Cross-Origin-Resource-Policy，如 same-origin、same-site、cross-origin
+	CrossOriginResourcePolicy string
+
+	// SkipPaths 不设置安全响应头的路径，支持以 * 结尾的前缀匹配
+	SkipPaths []string
+}
+
+// APISecureHeadersConfig 适用于 JSON API 的预设：禁止响应被当作页面渲染或嵌入
+var APISecureHeadersConfig = SecureHeadersConfig{
+	HSTSMaxAge:                365 * 24 * time.Hour,
+	HSTSIncludeSubdomains:     true,
+	ContentSecurityPolicy:     "default-src 'none'; frame-ancestors 'none'",
+	ContentTypeNosniff:        true,
+	FrameOptions:              "DENY",
+	ReferrerPolicy:            "no-referrer",
+	CrossOriginOpenerPolicy:   "same-origin",
+	CrossOriginResourcePolicy: "same-origin",
+}
+
+// HTMLSecureHeadersConfig 适用于 HTML 页面的预设：脚本和样式只允许同源或带 nonce 的内联代码
+var HTMLSecureHeadersConfig = SecureHeadersConfig{
+	HSTSMaxAge:            365 * 24 * time.Hour,
+	HSTSIncludeSubdomains: true,
+	ContentSecurityPolicy: "default-src 'self'; script-src 'self' 'nonce-{nonce}'; style-src 'self' 'nonce-{nonce}'; " +
+		"img-src 'self' data:; object-src 'none'; base-uri 'self'; form-action 'self'; frame-ancestors 'self'",
+	ContentTypeNosniff:        true,
+	FrameOptions:              "SAMEORIGIN",
+	ReferrerPolicy:            "strict-origin-when-cross-origin",
+	PermissionsPolicy:         "camera=(), microphone=(), geolocation=(), payment=()",
+	CrossOriginOpenerPolicy:   "same-origin",
+	CrossOriginResourcePolicy: "same-origin",
+}
+
+// cspNonceKey 请求上下文中保存 CSP nonce 的键
+type cspNonceKey struct{}
+
+// NewSecureHeadersMiddleware 创建安全响应头中间件，配置无效时返回错误
+// 响应头在调用处理器之前设置，处理器可以针对个别响应覆盖
+func NewSecureHeadersMiddleware(config SecureHeadersConfig) (func(http.Handler) http.Handler, error) {
+	if err := validateSecureHeadersConfig(&config); err != nil {
+		return nil, err
+	}
+
+	// 固定不变的响应头只构造一次
+	static := make(http.Header)
+	set := func(name, value string) {
+		if value != "" {
+			static.Set(name, value)
+		}
+	}
+	if config.ContentTypeNosniff {
+		static.Set("X-Content-Type-Options", "nosniff")
+	}
+	set("X-Frame-Options", strings.ToUpper(config.FrameOptions))
+	set("Referrer-Policy", config.ReferrerPolicy)
+	set("Permissions-Policy", config.PermissionsPolicy)
+	set("Cross-Origin-Opener-Policy", config.CrossOriginOpenerPolicy)
+	set("Cross-Origin-Embedder-Policy", config.CrossOriginEmbedderPolicy)
+	set("Cross-Origin-Resource-Policy", config.CrossOriginResourcePolicy)
+	if config.CSPReportURI != "" {
+		static.Set("Reporting-Endpoints", fmt.Sprintf("%s=%q", cspReportEndpoint, config.CSPReportURI))
+	}
+
+	hsts := ""
+	if config.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.FormatInt(int64(config.HSTSMaxAge.Seconds()), 10)
+		if config.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+		if config.HSTSPreload {
+			hsts += "; preload"
+		}
+	}
+
+	csp := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(config.ContentSecurityPolicy), ";"))
+	if csp != "" && config.CSPReportURI != "" {
+		csp += "; report-uri " + config.CSPReportURI + "; report-to " + cspReportEndpoint
+	}
+	cspHeader := "Content-Security-Policy"
+	if config.CSPReportOnly {
+		cspHeader = "Content-Security-Policy-Report-Only"
+	}
+	useNonce := strings.Contains(csp, CSPNoncePlaceholder)
+	skip := newPathMatcher(config.SkipPaths)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if skip.match(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			header := w.Header()
+			for name, values := range static {
+				header.Set(name, values[0])
+			}
+			// 浏览器会忽略 HTTP 响应中的 HSTS，这里也不下发；代理之后需要先挂载 NewProxyMiddleware 才能识别 HTTPS
+			if hsts != "" && httpx.Scheme(r) == "https" {
+				header.Set("Strict-Transport-Security", hsts)
+			}
+			if csp != "" {
+				if useNonce {
+					nonce := newCSPNonce()
+					header.Set(cspHeader, strings.ReplaceAll(csp, CSPNoncePlaceholder, nonce))
+					r = r.WithContext(context.WithValue(r.Context(), cspNonceKey{}, nonce))
+				} else {
+					header.Set(cspHeader, csp)
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}
+
+// validateSecureHeadersConfig 校验安全响应头配置
+func validateSecureHeadersConfig(config *SecureHeadersConfig) error {
+	switch strings.ToUpper(config.FrameOptions) {
+	case "", "DENY", "SAMEORIGIN":
+	default:
+		return fmt.Errorf("invalid X-Frame-Options: %s, must be DENY or SAMEORIGIN", config.FrameOptions)
+	}
+	if config.HSTSMaxAge < 0 {
+		return fmt.Errorf("HSTS max-age must not be negative")
+	}
+	if config.HSTSPreload && (config.HSTSMaxAge < 365*24*time.Hour || !config.HSTSIncludeSubdomains) {
+		return fmt.Errorf("HSTS preload requires max-age of at least 1 year and includeSubDomains")
+	}
+	if config.CSPReportOnly && config.ContentSecurityPolicy == "" {
+		return fmt.Errorf("CSP report-only mode requires ContentSecurityPolicy")
+	}
+	for name, value := range map[string]string{
+		"ContentSecurityPolicy":     config.ContentSecurityPolicy,
+		"CSPReportURI":              config.CSPReportURI,
+		"ReferrerPolicy":            config.ReferrerPolicy,
+		"PermissionsPolicy":         config.PermissionsPolicy,
+		"CrossOriginOpenerPolicy":   config.CrossOriginOpenerPolicy,
+		"CrossOriginEmbedderPolicy": config.CrossOriginEmbedderPolicy,
+		"CrossOriginResourcePolicy": config.CrossOriginResourcePolicy,
+	} {
+		if strings.ContainsAny(value, "\r\n") {
+			return fmt.Errorf("%s must not contain line breaks", name)
+		}
+	}
+	if strings.ContainsAny(config.CSPReportURI, " ;,\"") {
+		return fmt.Errorf("invalid CSPReportURI: %s", config.CSPReportURI)
+	}
+	return nil
+}
+
+// CSPNonce 获取当前请求的 CSP nonce，用于模板中的 <script nonce="..."> 和 <style nonce="...">
+// CSP 中没有使用 CSPNoncePlaceholder 时返回空字符串
+func CSPNonce(r *http.Request) string {
+	nonce, _ := r.Context().Value(cspNonceKey{}).(string)
+	return nonce
+}
+
+// newCSPNonce 生成 128 位随机 nonce
+func newCSPNonce() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic("secure headers: failed to read random bytes: " + err.Error())
+	}
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// ------------------------------------------------------------ CSP 违规报告 ------------------------------------------------------------
+
+// maxCSPReportSize 单次违规报告请求体的最大长度
+const maxCSPReportSize = 64 << 10
+
+// CSPReport CSP 违规报告，兼容 report-uri（application/csp-report）和 Reporting API（application/reports+json）两种格式
+type CSPReport struct {
+	DocumentURL        string `json:"document_url"`
+	Referrer           string `json:"referrer,omitempty"`
+	BlockedURL         string `json:"blocked_url"`
+	EffectiveDirective string `json:"effective_directive"`
+	OriginalPolicy     string `json:"original_policy"`
+	Disposition        string `json:"disposition"`
+	SourceFile         string `json:"source_file,omitempty"`
+	LineNumber         int    `json:"line_number,omitempty"`
+	ColumnNumber       int    `json:"column_number,omitempty"`
+	StatusCode         int    `json:"status_code,omitempty"`
+	Sample             string `json:"sample,omitempty"`
+	UserAgent          string `json:"user_agent,omitempty"`
+}
+
+// legacyCSPReport report-uri 格式: {"csp-report": {...}}
+type legacyCSPReport struct {
+	Report struct {
+		DocumentURI        string `json:"document-uri"`
+		Referrer           string `json:"referrer"`
+		BlockedURI         string `json:"blocked-uri"`
+		ViolatedDirective  string `json:"violated-directive"`
+		EffectiveDirective string `json:"effective-directive"`
+		OriginalPolicy     string `json:"original-policy"`
+		Disposition        string `json:"disposition"`
+		SourceFile         string `json:"source-file"`
+		LineNumber         int    `json:"line-number"`
+		ColumnNumber       int    `json:"column-number"`
+		StatusCode         int    `json:"status-code"`
+		ScriptSample       string `json:"script-sample"`
+	} `json:"csp-report"`
+}
+
+// reportingAPIReport Reporting API 格式: [{"type": "csp-violation", "body": {...}}]
+type reportingAPIReport struct {
+	Type      string `json:"type"`
+	UserAgent string `json:"user_agent"`
+	Body      struct {
+		DocumentURL        string `json:"documentURL"`
+		Referrer           string `json:"referrer"`
+		BlockedURL         string `json:"blockedURL"`
+		EffectiveDirective string `json:"effectiveDirective"`
+		OriginalPolicy     string `json:"originalPolicy"`
+		Disposition        string `json:"disposition"`
+		SourceFile         string `json:"sourceFile"`
+		LineNumber         int    `json:"lineNumber"`
+		ColumnNumber       int    `json:"columnNumber"`
+		StatusCode         int    `json:"statusCode"`
+		Sample             string `json:"sample"`
+	} `json:"body"`
+}
+
+// CSPReportHandler 接收浏览器发送的 CSP 违规报告，注册到 CSPReportURI 对应的路由（POST）
+// onReport 为 nil 时记录日志；报告由浏览器匿名发送，不要挂载认证和 CSRF 中间件
+func CSPReportHandler(onReport func(r *http.Request, report CSPReport)) http.HandlerFunc {
+	if onReport == nil {
+		onReport = func(r *http.Request, report CSPReport) {
+			log.Printf("[CSP] violation, document: %s, directive: %s, blocked: %s, disposition: %s",
+				report.DocumentURL, report.EffectiveDirective, report.BlockedURL, report.Disposition)
+		}
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxCSPReportSize+1))
+		if err != nil || len(body) > maxCSPReportSize {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+		reports, err := parseCSPReports(body, r.UserAgent())
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		for _, report := range reports {
+			onReport(r, report)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// parseCSPReports 根据内容解析两种报告格式，Reporting API 中非 csp-violation 类型的报告会被忽略
+func parseCSPReports(body []byte, userAgent string) ([]CSPReport, error) {
+	body = []byte(strings.TrimSpace(string(body)))
+	if len(body) > 0 && body[0] == '[' {
+		var items []reportingAPIReport
+		if err := json.Unmarshal(body, &items); err != nil {
+			return nil, err
+		}
+		reports := make([]CSPReport, 0, len(items))
+		for _, item := range items {
+			if item.Type != "csp-violation" {
+				continue
+			}
+			b := item.Body
+			ua := item.UserAgent
+			if ua == "" {
+				ua = userAgent
+			}
+			reports = append(reports, CSPReport{
+				DocumentURL:        b.DocumentURL,
+				Referrer:           b.Referrer,
+				BlockedURL:         b.BlockedURL,
+				EffectiveDirective: b.EffectiveDirective,
+				OriginalPolicy:     b.OriginalPolicy,
+				Disposition:        b.Disposition,
+				SourceFile:         b.SourceFile,
+				LineNumber:         b.LineNumber,
+				ColumnNumber:       b.ColumnNumber,
+				StatusCode:         b.StatusCode,
+				Sample:             b.Sample,
+				UserAgent:          ua,
+			})
+		}
+		return reports, nil
+	}
+
+	var legacy legacyCSPReport
+	if err := json.Unmarshal(body, &legacy); err != nil {
+		return nil, err
+	}
+	b := legacy.Report
+	directive := b.EffectiveDirective
+	if directive == "" {
+		directive = b.ViolatedDirective
+	}
+	return []CSPReport{{
+		DocumentURL:        b.DocumentURI,
+		Referrer:           b.Referrer,
+		BlockedURL:         b.BlockedURI,
+		EffectiveDirective: directive,
+		OriginalPolicy:     b.OriginalPolicy,
+		Disposition:        b.Disposition,
+		SourceFile:         b.SourceFile,
+		LineNumber:         b.LineNumber,
+		ColumnNumber:       b.ColumnNumber,
+		StatusCode:         b.StatusCode,
+		Sample:             b.ScriptSample,
+		UserAgent:          userAgent,
+	}}, nil
+}
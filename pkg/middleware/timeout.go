@@ -0,0 +1,245 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/stones-hub/taurus-pro-http/pkg/httpx"
+)
+
+// TimeoutConfig 请求超时配置
+type TimeoutConfig struct {
+	// Timeout 默认超时时间，<=0 表示不限制（仅对 RouteTimeouts 中的路由生效）
+	Timeout time.Duration
+	// RouteTimeouts 按路由模式覆盖超时时间，key 与 httpx.RoutePattern 返回值一致，值 <=0 表示该路由不限制
+	RouteTimeouts map[string]time.Duration
+	// StatusCode 超时返回的 HTTP 状态码，只能是 503 或 504，默认 503
+	StatusCode int
+	// OnTimeout 请求超时回调，可用于记录日志或指标；此时处理器可能仍在运行，回调中只能读取 r
+	OnTimeout func(r *http.Request, timeout time.Duration)
+}
+
+// timeoutSettings 可热更新的超时设置
+type timeoutSettings struct {
+	timeout       time.Duration
+	routeTimeouts map[string]time.Duration
+}
+
+// Timeout 请求超时控制器
+// 为每个请求设置 context deadline，处理器超时后立即返回 JSON 错误响应，处理器之后的写入会被丢弃
+// 处理器需要通过 r.Context() 感知超时并尽快返回（数据库、HTTP 调用等都应传递该 context）
+//
+// 注意:
+//   - 响应在处理器返回前会被缓冲，不适用于 SSE、文件下载等流式响应
+//   - WebSocket 升级请求不受超时控制
+type Timeout struct {
+	settings   atomic.Pointer[timeoutSettings]
+	statusCode int
+	onTimeout  func(r *http.Request, timeout time.Duration)
+}
+
+// NewTimeout 创建请求超时控制器
+func NewTimeout(config TimeoutConfig) (*Timeout, error) {
+	switch config.StatusCode {
+	case 0:
+		config.StatusCode = http.StatusServiceUnavailable
+	case http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+	default:
+		return nil, fmt.Errorf("timeout status code must be 503 or 504, got %d", config.StatusCode)
+	}
+
+	t := &Timeout{statusCode: config.StatusCode, onTimeout: config.OnTimeout}
+	t.Update(config.Timeout, config.RouteTimeouts)
+	return t, nil
+}
+
+// TimeoutMiddleware 创建固定超时时间的中间件，适合直接挂载到单个路由或路由组
+// 示例: router.Router{Path: "/export", Handler: h, Middleware: []router.MiddlewareFunc{middleware.TimeoutMiddleware(30 * time.Second)}}
+func TimeoutMiddleware(timeout time.Duration) func(http.Handler) http.Handler {
+	t, _ := NewTimeout(TimeoutConfig{Timeout: timeout})
+	return t.Middleware()
+}
+
+// Update 更新超时设置，对之后的请求生效，可用于配置热加载
+func (t *Timeout) Update(timeout time.Duration, routeTimeouts map[string]time.Duration) {
+	routes := make(map[string]time.Duration, len(routeTimeouts))
+	for route, d := range routeTimeouts {
+		routes[route] = d
+	}
+	t.settings.Store(&timeoutSettings{timeout: timeout, routeTimeouts: routes})
+}
+
+// Middleware 返回超时中间件
+func (t *Timeout) Middleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := httpx.RoutePattern(r)
+			timeout := t.timeoutFor(route)
+			if timeout <= 0 || isWebSocketUpgrade(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+			method := r.Method
+			r = r.WithContext(ctx)
+
+			tw := &timeoutWriter{w: w, header: make(http.Header)}
+			done := make(chan struct{})
+			panicChan := make(chan any, 1)
+			go func() {
+				defer func() {
+					if p := recover(); p != nil {
+						panicChan <- p
+					}
+				}()
+				// tw 不提供 Unwrap，重新携带请求上下文，供 httpx.SendResponse 读取请求 ID
+				next.ServeHTTP(httpx.WithResponseContext(tw, r.Context()), r)
+				close(done)
+			}()
+
+			select {
+			case p := <-panicChan:
+				// 在当前协程重新抛出，交给 RecoveryMiddleware 处理
+				panic(p)
+			case <-done:
+				tw.flush()
+			case <-ctx.Done():
+				// 处理器协程仍在使用 r，这里不再读取 r 的字段
+				select {
+				case <-done:
+					// 处理器恰好在超时时刻完成
+					tw.flush()
+					return
+				default:
+				}
+				tw.timeout()
+				if ctx.Err() == context.DeadlineExceeded {
+					log.Printf("[Timeout] request timed out, method: %s, route: %s, timeout: %s", method, route, timeout)
+					if t.onTimeout != nil {
+						t.onTimeout(r, timeout)
+					}
+					httpx.SendResponseWithStatus(w, t.statusCode, "request timeout", nil)
+				}
+				// 客户端主动断开时不需要响应
+			}
+		})
+	}
+}
+
+// timeoutFor 获取请求的超时时间
+func (t *Timeout) timeoutFor(route string) time.Duration {
+	settings := t.settings.Load()
+	if d, ok := settings.routeTimeouts[route]; ok {
+		return d
+	}
+	return settings.timeout
+}
+
+// isWebSocketUpgrade 是否为 WebSocket 升级请求
+func isWebSocketUpgrade(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket")
+}
+
+// timeoutWriter 缓冲处理器的响应，超时后丢弃所有写入
+type timeoutWriter struct {
+	w      http.ResponseWriter
+	header http.Header
+	buf    bytes.Buffer
+
+	mu          sync.Mutex
+	status      int
+	wroteHeader bool
+	timedOut    bool
+	flushed     bool
+}
+
+// Header 返回缓冲的响应头，超时前不会影响真实响应
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+// Write 写入缓冲区，超时后返回 http.ErrHandlerTimeout
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.flushed {
+		return 0, http.ErrHandlerTimeout
+	}
+	if !tw.wroteHeader {
+		tw.writeHeaderLocked(http.StatusOK)
+	}
+	return tw.buf.Write(b)
+}
+
+// WriteHeader 记录状态码
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.flushed || tw.wroteHeader {
+		return
+	}
+	tw.writeHeaderLocked(code)
+}
+
+func (tw *timeoutWriter) writeHeaderLocked(code int) {
+	tw.wroteHeader = true
+	tw.status = code
+}
+
+// timeout 标记为已超时，之后的写入都会被丢弃
+func (tw *timeoutWriter) timeout() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	tw.timedOut = true
+}
+
+// flush 将缓冲的响应写入真实的 ResponseWriter
+func (tw *timeoutWriter) flush() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.flushed {
+		return
+	}
+	tw.flushed = true
+
+	dst := tw.w.Header()
+	for k, v := range tw.header {
+		dst[k] = v
+	}
+	if !tw.wroteHeader {
+		tw.status = http.StatusOK
+	}
+	tw.w.WriteHeader(tw.status)
+	if tw.buf.Len() > 0 {
+		if _, err := tw.w.Write(tw.buf.Bytes()); err != nil {
+			log.Printf("[Timeout] write response failed: %v", err)
+		}
+	}
+}
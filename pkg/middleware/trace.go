@@ -0,0 +1,150 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/stones-hub/taurus-pro-http/pkg/httpx"
+)
+
+// maxRequestIDLength 允许透传的请求 ID 最大长度，超长视为非法并重新生成
+const maxRequestIDLength = 128
+
+// maxTraceStateLength W3C 规定 tracestate 最多 512 字节、32 个成员
+const (
+	maxTraceStateLength  = 512
+	maxTraceStateMembers = 32
+)
+
+// TraceConfig 请求 ID 和 trace 上下文配置
+type TraceConfig struct {
+	// RequestIDHeader 请求 ID 头名称，默认 X-Request-ID
+	RequestIDHeader string
+	// IgnoreIncomingRequestID 是否忽略客户端传入的请求 ID，始终重新生成
+	// 服务直接暴露在公网时建议开启，避免客户端伪造
+	IgnoreIncomingRequestID bool
+	// IgnoreIncomingTrace 是否忽略上游传入的 traceparent，始终开启新的 trace
+	IgnoreIncomingTrace bool
+	// RequestIDGenerator 请求 ID 生成函数，默认 uuid v4
+	RequestIDGenerator func() string
+}
+
+// DefaultTraceConfig 默认配置
+var DefaultTraceConfig = TraceConfig{
+	RequestIDHeader: httpx.HeaderRequestID,
+}
+
+// TraceMiddleware 生成或透传请求 ID，解析 W3C traceparent/tracestate
+// 1. 请求 ID 写入上下文和响应头，通过 httpx.GetRequestID 获取，httpx.SendResponse 的响应体中也会包含
+// 2. trace 信息写入上下文，通过 httpx.GetTraceID / httpx.TraceContextFromContext 获取
+// 3. 响应头返回 traceresponse，便于客户端关联链路
+// 4. 调用下游服务时使用 httpx.InjectTraceHeaders 透传
+func TraceMiddleware(config *TraceConfig) func(http.Handler) http.Handler {
+	if config == nil {
+		config = &DefaultTraceConfig
+	}
+	header := config.RequestIDHeader
+	if header == "" {
+		header = httpx.HeaderRequestID
+	}
+	generate := config.RequestIDGenerator
+	if generate == nil {
+		generate = func() string { return uuid.New().String() }
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// 1. 请求 ID
+			requestID := ""
+			if !config.IgnoreIncomingRequestID {
+				requestID = sanitizeRequestID(r.Header.Get(header))
+			}
+			if requestID == "" {
+				requestID = generate()
+			}
+
+			// 2. trace 上下文，上游传入的 span id 作为 parent，本服务生成新的 span id
+			// 外层已有 trace 上下文（如 OpenTelemetry 中间件在外层）时直接使用，保证各处的 trace id 一致
+			tc, ok := httpx.TraceContextFromContext(r.Context())
+			if !ok || tc.TraceID == "" {
+				tc = httpx.TraceContext{}
+				if !config.IgnoreIncomingTrace {
+					if parsed, err := httpx.ParseTraceParent(r.Header.Get(httpx.HeaderTraceParent)); err == nil {
+						tc = parsed
+						tc.TraceState = sanitizeTraceState(r.Header.Values(httpx.HeaderTraceState))
+					}
+				}
+				if tc.TraceID == "" {
+					tc = httpx.TraceContext{TraceID: httpx.NewTraceID(), Flags: 0x01}
+				}
+				tc.SpanID = httpx.NewSpanID()
+			}
+
+			ctx := httpx.WithRequestID(r.Context(), requestID)
+			ctx = httpx.WithTraceContext(ctx, tc)
+
+			w.Header().Set(header, requestID)
+			w.Header().Set(httpx.HeaderTraceResponse, tc.TraceParent())
+
+			next.ServeHTTP(httpx.WithResponseContext(w, ctx), r.WithContext(ctx))
+		})
+	}
+}
+
+// sanitizeRequestID 校验客户端传入的请求 ID，只允许可见 ASCII 字符
+func sanitizeRequestID(id string) string {
+	id = strings.TrimSpace(id)
+	if id == "" || len(id) > maxRequestIDLength {
+		return ""
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return ""
+		}
+	}
+	return id
+}
+
+// sanitizeTraceState 合并多个 tracestate 头，超出规范限制时丢弃
+func sanitizeTraceState(values []string) string {
+	var members []string
+	for _, value := range values {
+		for _, member := range strings.Split(value, ",") {
+			member = strings.TrimSpace(member)
+			if member == "" {
+				continue
+			}
+			if !strings.Contains(member, "=") {
+				return ""
+			}
+			members = append(members, member)
+		}
+	}
+	if len(members) > maxTraceStateMembers {
+		return ""
+	}
+	state := strings.Join(members, ",")
+	if len(state) > maxTraceStateLength {
+		return ""
+	}
+	return state
+}
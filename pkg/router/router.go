@@ -21,6 +21,8 @@ package router
 import (
 	"log"
 	"net/http"
+
+	"github.com/stones-hub/taurus-pro-http/pkg/httpx"
 )
 
 // Router holds the configuration for a route, including its handler and middleware
@@ -28,6 +30,7 @@ type Router struct {
 	Path       string
 	Handler    http.Handler
 	Middleware []MiddlewareFunc
+	Access     *AccessRule // 访问控制规则，需要通过 RouterManager.SetAccessEnforcer 设置执行器
 }
 
 // RouteGroup holds a group of routes with a common prefix and middleware
@@ -35,13 +38,32 @@ type RouteGroup struct {
 	Prefix     string
 	Middleware []MiddlewareFunc
 	Routes     []Router
+	Access     *AccessRule // 组内所有路由的访问控制规则，与路由自身的规则同时生效
+}
+
+// AccessRule 路由访问控制规则
+// 规则在路由和路由组的中间件之后执行，认证中间件需要挂载在 Middleware 中
+type AccessRule struct {
+	Roles       []string `json:"roles,omitempty"`       // 拥有其中任意一个角色即可
+	Permissions []string `json:"permissions,omitempty"` // 必须拥有全部权限
+	Scopes      []string `json:"scopes,omitempty"`      // 必须拥有全部 scope
+	Policies    []string `json:"policies,omitempty"`    // 必须通过全部命名策略，用于资源级检查（如只能访问自己的数据）
+}
+
+// IsEmpty 规则是否为空
+func (a *AccessRule) IsEmpty() bool {
+	return a == nil || len(a.Roles)+len(a.Permissions)+len(a.Scopes)+len(a.Policies) == 0
 }
 
+// AccessEnforcer 根据访问控制规则生成中间件，由授权模块实现（如 auth.Authorizer.Enforce）
+type AccessEnforcer func(rule AccessRule) MiddlewareFunc
+
 // RouterManager manages all routes and route groups
 type RouterManager struct {
 	routes          []Router
 	routeGroups     []RouteGroup
 	registeredPaths map[string]bool // Track registered paths
+	enforcer        AccessEnforcer
 }
 
 // NewRouterManager creates a new RouterManager
@@ -63,6 +85,67 @@ func (rm *RouterManager) AddRouterGroup(group RouteGroup) {
 	rm.routeGroups = append(rm.routeGroups, group)
 }
 
+// RouteInfo 路由信息，用于管理端口展示路由表
+type RouteInfo struct {
+	Path       string       `json:"path"`            // 完整路径，包含路由组前缀
+	Group      string       `json:"group,omitempty"` // 所属路由组的前缀
+	Middleware int          `json:"middleware"`      // 路由和路由组的中间件数量
+	Access     []AccessRule `json:"access,omitempty"`
+}
+
+// Routes 返回已添加的路由和路由组中的路由，按添加顺序排列
+func (rm *RouterManager) Routes() []RouteInfo {
+	routes := make([]RouteInfo, 0, len(rm.routes))
+	for _, route := range rm.routes {
+		info := RouteInfo{Path: route.Path, Middleware: len(route.Middleware)}
+		if !route.Access.IsEmpty() {
+			info.Access = []AccessRule{*route.Access}
+		}
+		routes = append(routes, info)
+	}
+	for _, group := range rm.routeGroups {
+		for _, route := range group.Routes {
+			info := RouteInfo{
+				Path:       group.Prefix + route.Path,
+				Group:      group.Prefix,
+				Middleware: len(group.Middleware) + len(route.Middleware),
+			}
+			for _, rule := range []*AccessRule{group.Access, route.Access} {
+				if !rule.IsEmpty() {
+					info.Access = append(info.Access, *rule)
+				}
+			}
+			routes = append(routes, info)
+		}
+	}
+	return routes
+}
+
+// SetAccessEnforcer 设置访问控制执行器
+// 未设置执行器时，声明了 Access 的路由会拒绝所有请求，避免规则被静默忽略
+func (rm *RouterManager) SetAccessEnforcer(enforcer AccessEnforcer) {
+	rm.enforcer = enforcer
+}
+
+// accessMiddleware 将访问控制规则转换为中间件，规则为空时返回 nil
+func (rm *RouterManager) accessMiddleware(path string, rule *AccessRule) []MiddlewareFunc {
+	if rule.IsEmpty() {
+		return nil
+	}
+	if rm.enforcer == nil {
+		log.Printf("Warning: Path %s declares access rules but no access enforcer is set, all requests will be denied.\n", path)
+		return []MiddlewareFunc{denyAll}
+	}
+	return []MiddlewareFunc{rm.enforcer(*rule)}
+}
+
+// denyAll 拒绝所有请求
+func denyAll(http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		httpx.SendResponseWithStatus(w, http.StatusForbidden, "access denied", nil)
+	})
+}
+
 // LoadRoutes loads all routes and route groups into a ServeMux
 func (rm *RouterManager) LoadRoutes() *http.ServeMux {
 	mux := http.NewServeMux()
@@ -72,18 +155,24 @@ func (rm *RouterManager) LoadRoutes() *http.ServeMux {
 			log.Printf("Warning: Path %s is already registered, skipping.\n", route.Path)
 			continue
 		}
-		handler := ChainMiddleware(route.Handler, route.Middleware...)
+		allMiddleware := append(route.Middleware[:len(route.Middleware):len(route.Middleware)], rm.accessMiddleware(route.Path, route.Access)...)
+		handler := ChainMiddleware(route.Handler, allMiddleware...)
 		mux.Handle(route.Path, handler)
 		rm.registeredPaths[route.Path] = true
 	}
 	// Load route groups
 	for _, group := range rm.routeGroups {
 		for _, route := range group.Routes {
-			// Combine group and route middleware, maintaining order
-			allMiddleware := append(group.Middleware, route.Middleware...)
-			handler := ChainMiddleware(route.Handler, allMiddleware...)
 			// Ensure the path is correctly formatted
 			fullPath := group.Prefix + route.Path
+			// Combine group and route middleware, maintaining order
+			// 访问控制在所有中间件之后执行，先检查组规则再检查路由规则
+			allMiddleware := make([]MiddlewareFunc, 0, len(group.Middleware)+len(route.Middleware)+2)
+			allMiddleware = append(allMiddleware, group.Middleware...)
+			allMiddleware = append(allMiddleware, route.Middleware...)
+			allMiddleware = append(allMiddleware, rm.accessMiddleware(fullPath, group.Access)...)
+			allMiddleware = append(allMiddleware, rm.accessMiddleware(fullPath, route.Access)...)
+			handler := ChainMiddleware(route.Handler, allMiddleware...)
 			if fullPath == "" || rm.registeredPaths[fullPath] {
 				log.Printf("Warning: Path %s is already registered, skipping.\n", fullPath)
 				continue // Skip if the full path is empty or already registered
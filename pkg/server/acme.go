@@ -0,0 +1,328 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// LetsEncryptStagingURL Let's Encrypt 测试环境，调试时使用，避免触发生产环境的频率限制
+const LetsEncryptStagingURL = "https://acme-staging-v02.api.letsencrypt.org/directory"
+
+// CertCache ACME 证书和账户密钥的缓存，与 autocert.Cache 相同
+// 可以使用 DirCertCache、NewMemoryCertCache，或自行实现（如保存到 Redis / 对象存储，供多实例共享）
+type CertCache = autocert.Cache
+
+// DirCertCache 基于目录的证书缓存，重启后复用已签发的证书，生产环境推荐使用
+func DirCertCache(dir string) CertCache {
+	return autocert.DirCache(dir)
+}
+
+// ACMEConfig 自动申请和续期证书的配置（如 Let's Encrypt）
+type ACMEConfig struct {
+	// Domains 允许申请证书的域名，必填；不在列表中的 SNI 会被拒绝，避免被恶意请求耗尽 CA 的频率限制
+	Domains []string
+	// Email 联系邮箱，CA 会发送证书过期等通知
+	Email string
+	// DirectoryURL ACME 服务地址，默认 Let's Encrypt 生产环境（acme.LetsEncryptURL）
+	// 测试时可以指向 LetsEncryptStagingURL 或本地的 Pebble 等测试服务
+	DirectoryURL string
+	// HTTPClient 访问 ACME 服务使用的客户端，连接本地测试服务时可以配置信任其 CA
+	HTTPClient *http.Client
+	// Cache 证书缓存，默认使用 CacheDir 目录
+	Cache CertCache
+	// CacheDir Cache 为空时使用的缓存目录，默认 "./runtime/acme"
+	CacheDir string
+	// RenewBefore 证书到期前多久开始续期，默认 30 天
+	RenewBefore time.Duration
+
+	// HTTPChallengeAddr HTTP-01 验证监听地址，如 ":80"；为空时只使用 TLS-ALPN-01 验证（需要服务监听 443）
+	// 该地址上的其他请求会被重定向到 HTTPS
+	HTTPChallengeAddr string
+
+	// CheckInterval 检查证书状态的周期，默认 12 小时；启动时会立即检查一次以提前申请证书
+	CheckInterval time.Duration
+	// OnRenewalFailure 申请或续期失败时的回调，可用于告警
+	// 以下情况会触发: 申请证书失败；证书剩余有效期已少于 RenewBefore 的 2/3（说明续期一直失败）
+	OnRenewalFailure func(domain string, err error)
+}
+
+// WithACME 启用 ACME 自动证书
+// 示例: WithACME(&ACMEConfig{Domains: []string{"api.example.com"}, Email: "ops@example.com", HTTPChallengeAddr: ":80"})
+func WithACME(config *ACMEConfig) serverOption {
+	return func(s *Server) {
+		s.config.ACME = config
+	}
+}
+
+// acmeManager 封装 autocert.Manager，增加失败回调和 HTTP-01 监听
+type acmeManager struct {
+	config       *ACMEConfig
+	manager      *autocert.Manager
+	httpServer   *http.Server
+	httpListener net.Listener // HTTP-01 验证监听，平滑重启时传给子进程
+
+	mu       sync.Mutex
+	reported map[string]time.Time // 每个域名最近一次失败回调的时间，避免握手失败时频繁回调
+	stop     chan struct{}
+	once     sync.Once
+}
+
+// newACMEManager 根据配置创建 ACME 管理器
+func newACMEManager(config *ACMEConfig) (*acmeManager, error) {
+	if len(config.Domains) == 0 {
+		return nil, errors.New("ACME requires at least one domain")
+	}
+	domains := make([]string, 0, len(config.Domains))
+	for _, domain := range config.Domains {
+		domain = strings.ToLower(strings.TrimSpace(domain))
+		if domain == "" || strings.Contains(domain, "*") {
+			return nil, fmt.Errorf("invalid ACME domain %q, wildcard certificates require DNS-01 which is not supported", domain)
+		}
+		domains = append(domains, domain)
+	}
+
+	cache := config.Cache
+	if cache == nil {
+		dir := config.CacheDir
+		if dir == "" {
+			dir = "./runtime/acme"
+		}
+		cache = autocert.DirCache(dir)
+	}
+	client := &acme.Client{DirectoryURL: config.DirectoryURL, HTTPClient: config.HTTPClient}
+	if client.DirectoryURL == "" {
+		client.DirectoryURL = acme.LetsEncryptURL
+	}
+
+	m := &acmeManager{
+		config: config,
+		manager: &autocert.Manager{
+			Prompt:      autocert.AcceptTOS,
+			Cache:       cache,
+			HostPolicy:  autocert.HostWhitelist(domains...),
+			RenewBefore: config.RenewBefore,
+			Client:      client,
+			Email:       config.Email,
+		},
+		reported: make(map[string]time.Time),
+		stop:     make(chan struct{}),
+	}
+	if config.HTTPChallengeAddr != "" {
+		handler := m.manager.HTTPHandler(nil)
+		m.httpServer = &http.Server{
+			Addr: config.HTTPChallengeAddr,
+			// autocert 用完整的 Host 匹配域名白名单，端口映射或代理转发时 Host 可能带端口，这里去掉端口
+			Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if host, _, err := net.SplitHostPort(r.Host); err == nil {
+					r.Host = host
+				}
+				handler.ServeHTTP(w, r)
+			}),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+	}
+	return m, nil
+}
+
+// GetCertificate 实现 tls.Config.GetCertificate，申请失败时触发回调
+func (m *acmeManager) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cert, err := m.manager.GetCertificate(hello)
+	if err != nil && m.isManaged(hello.ServerName) {
+		m.reportFailure(hello.ServerName, err)
+	}
+	return cert, err
+}
+
+// isManaged 域名是否在配置的列表中
+func (m *acmeManager) isManaged(name string) bool {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	for _, domain := range m.config.Domains {
+		if strings.EqualFold(strings.TrimSpace(domain), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// reportFailure 记录日志并回调，同一域名每分钟最多回调一次
+func (m *acmeManager) reportFailure(domain string, err error) {
+	m.mu.Lock()
+	last := m.reported[domain]
+	if time.Since(last) < time.Minute {
+		m.mu.Unlock()
+		return
+	}
+	m.reported[domain] = time.Now()
+	m.mu.Unlock()
+
+	log.Printf("[ACME] certificate for %s failed: %v", domain, err)
+	if m.config.OnRenewalFailure != nil {
+		m.config.OnRenewalFailure(domain, err)
+	}
+}
+
+// listen 打开 HTTP-01 验证监听，优先使用父进程传入的监听
+// 在 Start 中与其他监听一起同步打开，端口被占用等错误可以立即返回
+func (m *acmeManager) listen() error {
+	if m.httpServer == nil {
+		return nil
+	}
+	ln, err := listen("tcp", m.httpServer.Addr)
+	if err != nil {
+		return fmt.Errorf("ACME http-01 challenge listener %s: %w", m.httpServer.Addr, err)
+	}
+	m.httpListener = ln
+	return nil
+}
+
+// start 启动 HTTP-01 服务和证书检查，需要先调用 listen
+func (m *acmeManager) start(errChan chan error) {
+	if m.httpListener != nil {
+		go func() {
+			log.Printf("[ACME] http-01 challenge server is running on %s \n", m.httpServer.Addr)
+			if err := m.httpServer.Serve(m.httpListener); err != nil && err != http.ErrServerClosed {
+				log.Printf("[ACME] http-01 challenge server failed on %s: %v \n", m.httpServer.Addr, err)
+				ReportError(errChan, err)
+			}
+		}()
+	}
+
+	interval := m.config.CheckInterval
+	if interval <= 0 {
+		interval = 12 * time.Hour
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			m.check()
+			select {
+			case <-m.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// check 检查每个域名的证书，没有证书时立即申请
+// autocert 在后台自动续期但不暴露续期错误，这里通过剩余有效期判断续期是否一直失败
+func (m *acmeManager) check() {
+	renewBefore := m.config.RenewBefore
+	if renewBefore <= 0 {
+		renewBefore = 30 * 24 * time.Hour
+	}
+	for _, domain := range m.config.Domains {
+		select {
+		case <-m.stop:
+			return
+		default:
+		}
+		domain = strings.ToLower(strings.TrimSpace(domain))
+		// 模拟支持 ECDSA 的客户端，与浏览器使用同一个证书，避免额外申请 RSA 证书
+		cert, err := m.manager.GetCertificate(&tls.ClientHelloInfo{
+			ServerName:        domain,
+			SignatureSchemes:  []tls.SignatureScheme{tls.ECDSAWithP256AndSHA256},
+			SupportedCurves:   []tls.CurveID{tls.CurveP256},
+			CipherSuites:      []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256},
+			SupportedVersions: []uint16{tls.VersionTLS13, tls.VersionTLS12},
+		})
+		if err != nil {
+			m.reportFailure(domain, err)
+			continue
+		}
+		leaf := cert.Leaf
+		if leaf == nil && len(cert.Certificate) > 0 {
+			leaf, _ = x509.ParseCertificate(cert.Certificate[0])
+		}
+		if leaf != nil {
+			if remaining := time.Until(leaf.NotAfter); remaining < renewBefore*2/3 {
+				m.reportFailure(domain, fmt.Errorf("certificate expires at %s and has not been renewed", leaf.NotAfter.Format(time.RFC3339)))
+			}
+		}
+	}
+}
+
+// shutdown 停止证书检查和 HTTP-01 监听
+func (m *acmeManager) shutdown(ctx context.Context) error {
+	m.once.Do(func() { close(m.stop) })
+	if m.httpListener != nil {
+		// Serve 未启动时 Shutdown 不会关闭监听
+		defer m.httpListener.Close()
+	}
+	if m.httpServer != nil {
+		return m.httpServer.Shutdown(ctx)
+	}
+	return nil
+}
+
+// ------------------------------------------------------------ 内存缓存 ------------------------------------------------------------
+
+// memoryCertCache 基于内存的证书缓存，重启后需要重新申请证书，只适合测试
+type memoryCertCache struct {
+	mu   sync.RWMutex
+	data map[string][]byte
+}
+
+// NewMemoryCertCache 创建内存证书缓存
+// 注意: 生产环境频繁重启会触发 CA 的频率限制，请使用 DirCertCache 或其他持久化缓存
+func NewMemoryCertCache() CertCache {
+	return &memoryCertCache{data: make(map[string][]byte)}
+}
+
+// Get 实现 autocert.Cache
+func (c *memoryCertCache) Get(_ context.Context, key string) ([]byte, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	data, ok := c.data[key]
+	if !ok {
+		return nil, autocert.ErrCacheMiss
+	}
+	return data, nil
+}
+
+// Put 实现 autocert.Cache
+func (c *memoryCertCache) Put(_ context.Context, key string, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data[key] = append([]byte(nil), data...)
+	return nil
+}
+
+// Delete 实现 autocert.Cache
+func (c *memoryCertCache) Delete(_ context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.data, key)
+	return nil
+}
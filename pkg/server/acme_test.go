@@ -0,0 +1,497 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/acme"
+)
+
+// fakeACME 最小化的 ACME 服务（RFC 8555），只实现 autocert 用到的接口
+// 不校验 JWS 签名；TLS-ALPN-01 验证时连接 resolve 登记的地址，检查返回的验证证书
+type fakeACME struct {
+	*httptest.Server
+	caKey  *ecdsa.PrivateKey
+	caCert *x509.Certificate
+
+	mu       sync.Mutex
+	validity time.Duration     // 签发证书的有效期
+	maxCerts int               // 最多签发的证书数，达到后拒绝新订单，0 表示不限制，<0 表示拒绝所有订单
+	addrs    map[string]string // 域名 -> TLS-ALPN-01 验证时连接的地址
+	authzs   []*fakeAuthz
+	orders   []*fakeOrder
+	issued   int
+}
+
+type fakeAuthz struct {
+	domain string
+	status string
+}
+
+type fakeOrder struct {
+	authz  int
+	status string
+	chain  []byte // PEM 格式的证书链
+}
+
+func newFakeACME(t *testing.T) *fakeACME {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Fake ACME Root"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(365 * 24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, _ := x509.ParseCertificate(der)
+
+	ca := &fakeACME{caKey: key, caCert: caCert, validity: 90 * 24 * time.Hour, addrs: make(map[string]string)}
+	ca.Server = httptest.NewServer(http.HandlerFunc(ca.handle))
+	t.Cleanup(ca.Close)
+	return ca
+}
+
+// resolve 登记域名的验证地址
+func (ca *fakeACME) resolve(domain, addr string) {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+	ca.addrs[domain] = addr
+}
+
+// roots 信任该 CA 的证书池
+func (ca *fakeACME) roots() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.caCert)
+	return pool
+}
+
+// counts 已创建的订单数和已签发的证书数
+func (ca *fakeACME) counts() (orders, issued int) {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+	return len(ca.orders), ca.issued
+}
+
+func (ca *fakeACME) handle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Replay-Nonce", "nonce-"+strconv.FormatInt(time.Now().UnixNano(), 36))
+	path := strings.Trim(r.URL.Path, "/")
+	kind, id, _ := strings.Cut(path, "/")
+	index, _ := strconv.Atoi(id)
+
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+	switch kind {
+	case "":
+		writeJSON(w, http.StatusOK, map[string]string{
+			"newNonce":   ca.URL + "/new-nonce",
+			"newAccount": ca.URL + "/new-account",
+			"newOrder":   ca.URL + "/new-order",
+		})
+	case "new-nonce":
+	case "new-account":
+		w.Header().Set("Location", ca.URL+"/accounts/1")
+		writeJSON(w, http.StatusCreated, map[string]string{"status": acme.StatusValid})
+	case "new-order":
+		var req struct {
+			Identifiers []struct{ Value string }
+		}
+		if err := decodeJWSPayload(r, &req); err != nil || len(req.Identifiers) != 1 {
+			writeProblem(w, http.StatusBadRequest, "malformed", "expected exactly one identifier")
+			return
+		}
+		if ca.maxCerts < 0 || (ca.maxCerts > 0 && ca.issued >= ca.maxCerts) {
+			writeProblem(w, http.StatusForbidden, "rejectedIdentifier", "issuance is disabled")
+			return
+		}
+		ca.authzs = append(ca.authzs, &fakeAuthz{domain: req.Identifiers[0].Value, status: acme.StatusPending})
+		ca.orders = append(ca.orders, &fakeOrder{authz: len(ca.authzs) - 1, status: acme.StatusPending})
+		w.Header().Set("Location", fmt.Sprintf("%s/orders/%d", ca.URL, len(ca.orders)-1))
+		writeJSON(w, http.StatusCreated, ca.orderJSON(len(ca.orders)-1))
+	case "orders":
+		if index >= len(ca.orders) {
+			writeProblem(w, http.StatusNotFound, "malformed", "no such order")
+			return
+		}
+		w.Header().Set("Location", fmt.Sprintf("%s/orders/%d", ca.URL, index))
+		writeJSON(w, http.StatusOK, ca.orderJSON(index))
+	case "authz":
+		if index >= len(ca.authzs) {
+			writeProblem(w, http.StatusNotFound, "malformed", "no such authorization")
+			return
+		}
+		var req struct{ Status string }
+		decodeJWSPayload(r, &req)
+		if req.Status == acme.StatusDeactivated {
+			ca.authzs[index].status = acme.StatusDeactivated
+		}
+		writeJSON(w, http.StatusOK, ca.authzJSON(index))
+	case "challenges":
+		if index >= len(ca.authzs) {
+			writeProblem(w, http.StatusNotFound, "malformed", "no such challenge")
+			return
+		}
+		z := ca.authzs[index]
+		// 验证时服务端会回调 GetCertificate，不能持有锁
+		addr := ca.addrs[z.domain]
+		ca.mu.Unlock()
+		err := verifyTLSALPN(addr, z.domain)
+		ca.mu.Lock()
+		z.status = acme.StatusValid
+		if err != nil {
+			z.status = acme.StatusInvalid
+		}
+		for _, o := range ca.orders {
+			if o.authz == index && o.status == acme.StatusPending {
+				o.status = map[bool]string{true: acme.StatusReady, false: acme.StatusInvalid}[err == nil]
+			}
+		}
+		writeJSON(w, http.StatusOK, ca.authzJSON(index)["challenges"].([]map[string]string)[0])
+	case "finalize":
+		var req struct{ CSR string }
+		if err := decodeJWSPayload(r, &req); err != nil || index >= len(ca.orders) || ca.orders[index].status != acme.StatusReady {
+			writeProblem(w, http.StatusForbidden, "orderNotReady", "order is not ready")
+			return
+		}
+		chain, err := ca.issue(req.CSR)
+		if err != nil {
+			writeProblem(w, http.StatusBadRequest, "badCSR", err.Error())
+			return
+		}
+		ca.orders[index].status, ca.orders[index].chain = acme.StatusValid, chain
+		ca.issued++
+		w.Header().Set("Location", fmt.Sprintf("%s/orders/%d", ca.URL, index))
+		writeJSON(w, http.StatusOK, ca.orderJSON(index))
+	case "certs":
+		w.Header().Set("Content-Type", "application/pem-certificate-chain")
+		w.Write(ca.orders[index].chain)
+	default:
+		writeProblem(w, http.StatusNotFound, "malformed", "unknown resource "+path)
+	}
+}
+
+// orderJSON 订单的 JSON 表示，调用方需持有锁
+func (ca *fakeACME) orderJSON(index int) map[string]any {
+	o := ca.orders[index]
+	v := map[string]any{
+		"status":         o.status,
+		"identifiers":    []map[string]string{{"type": "dns", "value": ca.authzs[o.authz].domain}},
+		"authorizations": []string{fmt.Sprintf("%s/authz/%d", ca.URL, o.authz)},
+		"finalize":       fmt.Sprintf("%s/finalize/%d", ca.URL, index),
+	}
+	if o.status == acme.StatusValid {
+		v["certificate"] = fmt.Sprintf("%s/certs/%d", ca.URL, index)
+	}
+	return v
+}
+
+// authzJSON 授权的 JSON 表示，只提供 tls-alpn-01 验证，调用方需持有锁
+func (ca *fakeACME) authzJSON(index int) map[string]any {
+	z := ca.authzs[index]
+	return map[string]any{
+		"status":     z.status,
+		"identifier": map[string]string{"type": "dns", "value": z.domain},
+		"challenges": []map[string]string{{
+			"type":   "tls-alpn-01",
+			"url":    fmt.Sprintf("%s/challenges/%d", ca.URL, index),
+			"token":  "token-" + strconv.Itoa(index),
+			"status": z.status,
+		}},
+	}
+}
+
+// issue 根据 CSR 签发证书，返回 PEM 格式的证书链，调用方需持有锁
+func (ca *fakeACME) issue(encodedCSR string) ([]byte, error) {
+	der, err := base64.RawURLEncoding.DecodeString(encodedCSR)
+	if err != nil {
+		return nil, err
+	}
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(int64(ca.issued) + 2),
+		Subject:      pkix.Name{CommonName: csr.Subject.CommonName},
+		DNSNames:     csr.DNSNames,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(ca.validity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	leaf, err := x509.CreateCertificate(rand.Reader, template, ca.caCert, csr.PublicKey, ca.caKey)
+	if err != nil {
+		return nil, err
+	}
+	chain := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leaf})
+	return append(chain, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.caCert.Raw})...), nil
+}
+
+// verifyTLSALPN 按 RFC 8737 连接 addr 完成 TLS-ALPN-01 验证
+func verifyTLSALPN(addr, domain string) error {
+	if addr == "" {
+		return fmt.Errorf("no address for %s", domain)
+	}
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: 5 * time.Second}, "tcp", addr, &tls.Config{
+		ServerName:         domain,
+		NextProtos:         []string{acme.ALPNProto},
+		InsecureSkipVerify: true,
+	})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	state := conn.ConnectionState()
+	if state.NegotiatedProtocol != acme.ALPNProto || len(state.PeerCertificates) != 1 {
+		return errors.New("server did not answer the acme-tls/1 challenge")
+	}
+	acmeIdentifier := asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 1, 31}
+	for _, ext := range state.PeerCertificates[0].Extensions {
+		if ext.Id.Equal(acmeIdentifier) {
+			return state.PeerCertificates[0].VerifyHostname(domain)
+		}
+	}
+	return errors.New("challenge certificate has no acmeIdentifier extension")
+}
+
+// decodeJWSPayload 解码 JWS 的 payload，POST-as-GET 请求的 payload 为空
+func decodeJWSPayload(r *http.Request, v any) error {
+	var jws struct{ Payload string }
+	if err := json.NewDecoder(r.Body).Decode(&jws); err != nil {
+		return err
+	}
+	if jws.Payload == "" {
+		return nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(jws.Payload)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeProblem(w http.ResponseWriter, status int, typ, detail string) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"type": "urn:ietf:params:acme:error:" + typ, "detail": detail})
+}
+
+// failureRecorder 记录 OnRenewalFailure 回调
+type failureRecorder struct {
+	mu   sync.Mutex
+	errs map[string][]error
+}
+
+func (f *failureRecorder) record(domain string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.errs == nil {
+		f.errs = make(map[string][]error)
+	}
+	f.errs[domain] = append(f.errs[domain], err)
+}
+
+func (f *failureRecorder) get(domain string) []error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.errs[domain]
+}
+
+const testDomain = "app.example.test"
+
+func TestACMEIssuesAndCachesCertificate(t *testing.T) {
+	ca := newFakeACME(t)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca.resolve(testDomain, ln.Addr().String())
+
+	cache := NewMemoryCertCache()
+	var failures failureRecorder
+	srv := New(Config{
+		Listener: ln,
+		ACME: &ACMEConfig{
+			Domains:          []string{testDomain},
+			DirectoryURL:     ca.URL,
+			Cache:            cache,
+			OnRenewalFailure: failures.record,
+		},
+	})
+	errChan := make(chan error, 4)
+	srv.Start(errChan)
+	defer srv.Shutdown(context.Background())
+
+	// 启动时立即申请证书，TLS-ALPN-01 验证连接的是正在服务的主监听
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		if _, issued := ca.counts(); issued == 1 {
+			break
+		}
+		select {
+		case err := <-errChan:
+			t.Fatalf("server failed: %v", err)
+		default:
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("certificate was not issued, failures: %v", failures.get(testDomain))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	conn, err := tls.Dial("tcp", ln.Addr().String(), &tls.Config{ServerName: testDomain, RootCAs: ca.roots()})
+	if err != nil {
+		t.Fatalf("handshake with the issued certificate failed: %v", err)
+	}
+	conn.Close()
+	if _, err := cache.Get(context.Background(), testDomain); err != nil {
+		t.Errorf("issued certificate is not cached: %v", err)
+	}
+	if errs := failures.get(testDomain); len(errs) != 0 {
+		t.Errorf("unexpected renewal failures: %v", errs)
+	}
+
+	// 新的管理器使用同一个缓存，不再向 CA 申请
+	m, err := newACMEManager(&ACMEConfig{Domains: []string{testDomain}, DirectoryURL: ca.URL, Cache: cache, OnRenewalFailure: failures.record})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.check()
+	if orders, issued := ca.counts(); orders != 1 || issued != 1 {
+		t.Errorf("CA got %d orders and issued %d certificates, want 1 and 1", orders, issued)
+	}
+	if errs := failures.get(testDomain); len(errs) != 0 {
+		t.Errorf("unexpected renewal failures: %v", errs)
+	}
+}
+
+func TestACMEReportsIssuanceFailure(t *testing.T) {
+	ca := newFakeACME(t)
+	ca.maxCerts = -1
+
+	var failures failureRecorder
+	m, err := newACMEManager(&ACMEConfig{
+		Domains:          []string{testDomain},
+		DirectoryURL:     ca.URL,
+		Cache:            NewMemoryCertCache(),
+		OnRenewalFailure: failures.record,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.check()
+	errs := failures.get(testDomain)
+	if len(errs) != 1 {
+		t.Fatalf("OnRenewalFailure called %d times, want 1", len(errs))
+	}
+	var acmeErr *acme.Error
+	if !errors.As(errs[0], &acmeErr) || acmeErr.ProblemType != "urn:ietf:params:acme:error:rejectedIdentifier" {
+		t.Errorf("failure = %v, want the CA rejection", errs[0])
+	}
+
+	// 同一域名一分钟内只回调一次
+	m.check()
+	if n := len(failures.get(testDomain)); n != 1 {
+		t.Errorf("OnRenewalFailure called %d times after a second check, want 1", n)
+	}
+}
+
+func TestACMEReportsExpiringCertificate(t *testing.T) {
+	ca := newFakeACME(t)
+	// 只签发一张 5 天后过期的证书，之后的续期都被拒绝
+	ca.validity, ca.maxCerts = 5*24*time.Hour, 1
+
+	var failures failureRecorder
+	m, err := newACMEManager(&ACMEConfig{
+		Domains:          []string{testDomain},
+		DirectoryURL:     ca.URL,
+		Cache:            NewMemoryCertCache(),
+		RenewBefore:      30 * 24 * time.Hour,
+		OnRenewalFailure: failures.record,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 验证请求由监听在本地的 TLS 服务响应
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{GetCertificate: m.GetCertificate, NextProtos: []string{acme.ALPNProto}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				conn.(*tls.Conn).Handshake()
+				conn.Close()
+			}()
+		}
+	}()
+	ca.resolve(testDomain, ln.Addr().String())
+
+	m.check()
+	if _, issued := ca.counts(); issued != 1 {
+		t.Fatalf("issued %d certificates, want 1", issued)
+	}
+	errs := failures.get(testDomain)
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "has not been renewed") {
+		t.Errorf("failures = %v, want one expiring certificate report", errs)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// drainer 跟踪长连接处理器（WebSocket、SSE、长轮询），在服务关闭时通知它们退出并等待
+// http.Server.Shutdown 不跟踪被 Hijack 的连接（WebSocket），对 SSE 也只会一直等到超时
+type drainer struct {
+	ctx    context.Context // 服务开始关闭时取消
+	cancel context.CancelFunc
+	mu     sync.Mutex
+	active int // 尚未返回的长连接处理器数量
+}
+
+// drainerKey 请求上下文中保存 drainer 的键
+type drainerKey struct{}
+
+func newDrainer() *drainer {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &drainer{ctx: ctx, cancel: cancel}
+}
+
+// wrap 把 drainer 放入请求上下文，供 LongLived 使用
+func (d *drainer) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), drainerKey{}, d)))
+	})
+}
+
+// track 登记一个长连接处理器，返回的 ctx 在服务开始关闭或 parent 结束时取消
+func (d *drainer) track(parent context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(parent)
+	stop := context.AfterFunc(d.ctx, cancel)
+	d.mu.Lock()
+	d.active++
+	d.mu.Unlock()
+
+	var once sync.Once
+	return ctx, func() {
+		once.Do(func() {
+			stop()
+			cancel()
+			d.mu.Lock()
+			d.active--
+			d.mu.Unlock()
+		})
+	}
+}
+
+// notify 通知所有长连接处理器服务正在关闭，之后登记的处理器会立即收到通知
+func (d *drainer) notify() {
+	d.cancel()
+}
+
+// wait 等待所有长连接处理器返回，ctx 到期时返回错误
+func (d *drainer) wait(ctx context.Context) error {
+	// 与 http.Server.Shutdown 一样轮询，处理器数量通常很少
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		d.mu.Lock()
+		active := d.active
+		d.mu.Unlock()
+		if active == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%d long-lived handlers still running: %w", active, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// LongLived 把当前请求登记为长连接（WebSocket、SSE、长轮询等）
+// 返回的 ctx 在服务开始关闭或请求结束时取消，处理器应当在 ctx 取消后尽快结束（如发送 WebSocket 关闭帧、结束 SSE 流）
+// done 必须在处理器返回时调用；Shutdown 会在超时前等待所有登记的处理器返回
+// 请求不是由 Server 处理时（如单元测试），返回请求自身的上下文
+//
+// 示例:
+//
+//	ctx, done := server.LongLived(r)
+//	defer done()
+//	for {
+//		select {
+//		case <-ctx.Done():
+//			return // 服务关闭或客户端断开，结束 SSE 流
+//		case event := <-events:
+//			fmt.Fprintf(w, "data: %s\n\n", event)
+//			http.NewResponseController(w).Flush()
+//		}
+//	}
+func LongLived(r *http.Request) (context.Context, func()) {
+	d, ok := r.Context().Value(drainerKey{}).(*drainer)
+	if !ok {
+		return r.Context(), func() {}
+	}
+	return d.track(r.Context())
+}
+
+// LongLivedHandler 把处理器登记为长连接，服务开始关闭时取消请求上下文
+// 适用于在请求上下文取消后自行结束的流式处理器，如第三方库提供的 SSE 处理器
+// 不要用于普通接口，否则服务关闭时处理中的请求会被取消
+func LongLivedHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, done := LongLived(r)
+		defer done()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
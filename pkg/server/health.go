@@ -0,0 +1,280 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/stones-hub/taurus-pro-http/pkg/httpx"
+	"github.com/stones-hub/taurus-pro-http/pkg/router"
+)
+
+// HealthConfig 健康检查端点配置，零值使用默认值
+//
+// 三个端点的区别:
+//   - /livez: 只执行 Liveness 检查，失败时编排系统（如 Kubernetes）会重启进程；服务关闭期间仍然返回成功
+//   - /readyz: 执行所有检查，服务开始关闭后立即返回失败，负载均衡据此摘除流量
+//   - /healthz: 与 /readyz 相同，兼容只支持一个健康检查地址的负载均衡
+type HealthConfig struct {
+	// LivePath / ReadyPath / HealthPath 端点路径，默认 "/livez"、"/readyz"、"/healthz"，设置为 "-" 表示不注册
+	// 与用户路由（包括路由组中的路由）同名时不注册，以用户路由为准
+	LivePath   string
+	ReadyPath  string
+	HealthPath string
+	// Timeout 单个检查的默认超时时间，默认 5 秒
+	Timeout time.Duration
+	// CacheTTL 检查结果的缓存时间，默认 1 秒；缓存期内的请求直接返回上次结果，避免探针风暴压垮依赖
+	// 缓存过期时同时到达的请求共享同一次检查
+	CacheTTL time.Duration
+	// ShutdownDelay 服务开始关闭、readiness 返回失败后继续正常服务的时间，默认 0
+	// 负载均衡需要几个探测周期才会摘除实例，期间仍可能有新请求到达；该时间计入关闭超时
+	ShutdownDelay time.Duration
+}
+
+// HealthCheck 一个命名的健康检查
+type HealthCheck struct {
+	// Name 检查名称，出现在响应的 checks 中，同名检查会被替换
+	Name string
+	// Check 检查函数，返回 nil 表示健康；应当遵守 ctx 的超时
+	Check func(ctx context.Context) error
+	// Timeout 检查超时时间，默认使用 HealthConfig.Timeout
+	Timeout time.Duration
+	// Liveness 为 true 时同时用于 /livez，只用于进程自身的检查（如死锁检测）
+	// 数据库等外部依赖不要设置，否则依赖故障时所有实例都会被重启
+	Liveness bool
+}
+
+// CheckResult 单个检查的结果
+type CheckResult struct {
+	Status    string  `json:"status"` // "ok" 或 "fail"
+	Error     string  `json:"error,omitempty"`
+	LatencyMs float64 `json:"latency_ms"`
+}
+
+// HealthReport 健康检查结果，作为响应的 data 返回
+type HealthReport struct {
+	Status       string                 `json:"status"` // "ok" 或 "fail"
+	ShuttingDown bool                   `json:"shutting_down,omitempty"`
+	Checks       map[string]CheckResult `json:"checks,omitempty"`
+	CheckedAt    time.Time              `json:"checked_at"`
+}
+
+// WithHealth 注册 /healthz、/readyz、/livez 端点
+// 示例: WithHealth(&HealthConfig{CacheTTL: 2 * time.Second, ShutdownDelay: 5 * time.Second})
+func WithHealth(config *HealthConfig) serverOption {
+	return func(s *Server) {
+		s.config.Health = config
+	}
+}
+
+// health 健康检查注册表和结果缓存
+type health struct {
+	mu     sync.RWMutex
+	checks []HealthCheck
+	live   healthCache
+	ready  healthCache
+}
+
+// healthCache 缓存一类检查的结果，mu 在检查期间持有，并发请求等待同一次检查
+type healthCache struct {
+	mu     sync.Mutex
+	report HealthReport
+	at     time.Time
+}
+
+// AddHealthCheck 注册健康检查，需要配置 Config.Health 才会暴露端点
+// 示例: srv.AddHealthCheck(server.HealthCheck{Name: "db", Check: db.PingContext, Timeout: time.Second})
+func (s *Server) AddHealthCheck(check HealthCheck) {
+	if check.Name == "" || check.Check == nil {
+		log.Printf("Warning: health check without name or function is ignored \n")
+		return
+	}
+	s.health.mu.Lock()
+	defer s.health.mu.Unlock()
+	for i := range s.health.checks {
+		if s.health.checks[i].Name == check.Name {
+			s.health.checks[i] = check
+			return
+		}
+	}
+	s.health.checks = append(s.health.checks, check)
+}
+
+// ShuttingDown 服务是否已经开始关闭
+func (s *Server) ShuttingDown() bool {
+	return s.shuttingDown.Load()
+}
+
+// registerHealthRoutes 注册健康检查端点，同名路径以用户路由（包括路由组中的路由）为准
+func (s *Server) registerHealthRoutes() {
+	config := s.config.Health
+	if config == nil {
+		return
+	}
+	// LoadRoutes 先注册单独的路由再注册路由组，这里提前排除用户已经使用的路径，
+	// 否则健康检查会占用路径，路由组中的同名路由被跳过
+	taken := make(map[string]bool)
+	for _, route := range s.router.Routes() {
+		taken[route.Path] = true
+	}
+	for _, route := range []struct {
+		path     string
+		fallback string
+		liveness bool
+	}{
+		{config.LivePath, "/livez", true},
+		{config.ReadyPath, "/readyz", false},
+		{config.HealthPath, "/healthz", false},
+	} {
+		path := route.path
+		if path == "" {
+			path = route.fallback
+		}
+		if path == "-" {
+			continue
+		}
+		if taken[path] {
+			log.Printf("Health endpoint %s is provided by a user route, skipping \n", path)
+			continue
+		}
+		s.router.AddRouter(router.Router{Path: path, Handler: s.healthHandler(route.liveness)})
+	}
+}
+
+// healthHandler 返回健康检查结果，失败时返回 503
+func (s *Server) healthHandler(liveness bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := s.checkHealth(liveness)
+		status := http.StatusOK
+		if report.Status != "ok" {
+			status = http.StatusServiceUnavailable
+		}
+		httpx.SendResponseWithStatus(w, status, report, map[string]string{"Cache-Control": "no-store"})
+	})
+}
+
+// checkHealth 执行检查，缓存期内返回上次结果；readiness 在服务关闭后直接返回失败
+func (s *Server) checkHealth(liveness bool) HealthReport {
+	if !liveness && s.ShuttingDown() {
+		return HealthReport{Status: "fail", ShuttingDown: true, CheckedAt: time.Now()}
+	}
+	cache := &s.health.ready
+	if liveness {
+		cache = &s.health.live
+	}
+	ttl := s.config.Health.CacheTTL
+	if ttl <= 0 {
+		ttl = time.Second
+	}
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if !cache.at.IsZero() && time.Since(cache.at) < ttl {
+		return cache.report
+	}
+	cache.report = s.runHealthChecks(liveness)
+	cache.at = time.Now()
+	return cache.report
+}
+
+// runHealthChecks 并发执行检查，每个检查有独立的超时
+func (s *Server) runHealthChecks(liveness bool) HealthReport {
+	s.health.mu.RLock()
+	var checks []HealthCheck
+	for _, check := range s.health.checks {
+		if !liveness || check.Liveness {
+			checks = append(checks, check)
+		}
+	}
+	s.health.mu.RUnlock()
+
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			timeout := check.Timeout
+			if timeout <= 0 {
+				timeout = s.config.Health.Timeout
+			}
+			if timeout <= 0 {
+				timeout = 5 * time.Second
+			}
+			start := time.Now()
+			err := runHealthCheck(check, timeout)
+			results[i] = CheckResult{Status: "ok", LatencyMs: float64(time.Since(start).Microseconds()) / 1000}
+			if err != nil {
+				results[i].Status = "fail"
+				results[i].Error = err.Error()
+			}
+		}()
+	}
+	wg.Wait()
+
+	report := HealthReport{Status: "ok", Checks: make(map[string]CheckResult, len(checks)), CheckedAt: time.Now()}
+	for i, check := range checks {
+		report.Checks[check.Name] = results[i]
+		if results[i].Status != "ok" {
+			report.Status = "fail"
+		}
+	}
+	return report
+}
+
+// runHealthCheck 执行一个检查，检查函数不遵守 ctx 时也在超时后返回
+func runHealthCheck(check HealthCheck, timeout time.Duration) (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		done <- check.Check(ctx)
+	}()
+	select {
+	case err = <-done:
+		return err
+	case <-ctx.Done():
+		return errors.New("timeout after " + timeout.String())
+	}
+}
+
+// waitShutdownDelay readiness 返回失败后继续服务 ShutdownDelay，ctx 到期时提前返回
+func (s *Server) waitShutdownDelay(ctx context.Context) {
+	if s.config.Health == nil || s.config.Health.ShutdownDelay <= 0 {
+		return
+	}
+	log.Printf("Server readiness failing, waiting %s for load balancers on %s \n", s.config.Health.ShutdownDelay, s.config.Addr)
+	timer := time.NewTimer(s.config.Health.ShutdownDelay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
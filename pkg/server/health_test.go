@@ -0,0 +1,55 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package server
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stones-hub/taurus-pro-http/pkg/router"
+)
+
+func TestHealthRoutesYieldToUserRoutes(t *testing.T) {
+	srv := New(Config{Health: &HealthConfig{}})
+	user := func(name string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, name)
+		})
+	}
+	srv.AddRouter(router.Router{Path: "/readyz", Handler: user("route")})
+	srv.AddRouterGroup(router.RouteGroup{Prefix: "/", Routes: []router.Router{{Path: "healthz", Handler: user("group")}}})
+
+	srv.registerHealthRoutes()
+	mux := srv.router.LoadRoutes()
+
+	for path, want := range map[string]string{"/readyz": "route", "/healthz": "group"} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if got := rec.Body.String(); got != want {
+			t.Errorf("GET %s = %q, want the user %s handler", path, got, want)
+		}
+	}
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Cache-Control") != "no-store" {
+		t.Errorf("GET /livez = %d, want the health endpoint", rec.Code)
+	}
+}
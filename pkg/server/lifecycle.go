@@ -0,0 +1,310 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Service 由 Lifecycle 统一管理的服务，*Server 和 mcp.MCPServer 都实现了该接口
+type Service interface {
+	// Start 启动服务，不阻塞；启动失败或运行中的错误写入 errChan
+	// 后台协程中的错误使用 ReportError 写入，Lifecycle 在服务停止后不再读取 errChan
+	Start(errChan chan error)
+	// Shutdown 优雅停止服务，ctx 到期时应当尽快返回
+	Shutdown(ctx context.Context) error
+}
+
+// Hook 生命周期钩子，返回错误时记录在 Run 的返回值中
+type Hook func(ctx context.Context) error
+
+// LifecycleConfig 生命周期配置
+type LifecycleConfig struct {
+	// ShutdownTimeout 收到退出信号后等待处理中请求完成的时间（grace period），默认 30 秒
+	// 超时后不再等待，未完成的请求会被中断；等待期间再次收到退出信号时立即退出
+	ShutdownTimeout time.Duration
+	// Signals 触发退出的信号，默认 SIGINT 和 SIGTERM
+	Signals []os.Signal
+	// Restart 平滑重启配置，为 nil 时不处理重启信号，见 Restart
+	Restart *RestartConfig
+}
+
+// hooks 按注册顺序执行的生命周期钩子
+type hooks struct {
+	onStart    []Hook
+	onReady    []Hook
+	onShutdown []Hook
+}
+
+// namedService 带名称的服务，名称用于日志和错误信息
+type namedService struct {
+	name    string
+	service Service
+}
+
+// Lifecycle 管理多个服务（HTTP、MCP、管理端口等）的启动、就绪和退出
+//
+// Run 的执行顺序:
+//  1. 按注册顺序执行 OnStart 钩子（如连接数据库、加载配置），失败时不启动服务
+//  2. 按注册顺序启动所有服务，任何一个启动失败时停止已启动的服务
+//  3. 按注册顺序执行 OnReady 钩子（如注册服务发现），由平滑重启启动时通知父进程
+//  4. 等待 ctx 结束、退出信号、服务运行出错或平滑重启完成
+//  5. 在 ShutdownTimeout 内同时优雅停止所有服务
+//  6. 按注册顺序执行 OnShutdown 钩子（如关闭数据库连接），启动失败时同样会执行
+type Lifecycle struct {
+	hooks
+	config   LifecycleConfig
+	services []namedService
+}
+
+// NewLifecycle 创建生命周期管理器
+func NewLifecycle(config LifecycleConfig) *Lifecycle {
+	return &Lifecycle{config: config}
+}
+
+// Add 添加服务，name 用于日志
+func (l *Lifecycle) Add(name string, service Service) {
+	l.services = append(l.services, namedService{name: name, service: service})
+}
+
+// OnStart 注册启动前执行的钩子
+func (l *Lifecycle) OnStart(hook Hook) {
+	l.onStart = append(l.onStart, hook)
+}
+
+// OnReady 注册所有服务启动后执行的钩子
+func (l *Lifecycle) OnReady(hook Hook) {
+	l.onReady = append(l.onReady, hook)
+}
+
+// OnShutdown 注册所有服务停止后执行的钩子
+func (l *Lifecycle) OnShutdown(hook Hook) {
+	l.onShutdown = append(l.onShutdown, hook)
+}
+
+// Run 启动所有服务并阻塞，直到 ctx 结束、收到退出信号或服务出错
+// 返回启动、运行、停止过程中的所有错误；正常退出（ctx 结束或收到信号）时返回 nil
+func (l *Lifecycle) Run(ctx context.Context) error {
+	var errs []error
+
+	// Start 中的启动错误是同步写入的，使用带缓冲的通道，启动后立即检查；运行中的错误通过 ReportError 写入
+	errChan := make(chan error, 16)
+	drain := func() {
+		for {
+			select {
+			case err := <-errChan:
+				if err != nil {
+					errs = append(errs, err)
+				}
+			default:
+				return
+			}
+		}
+	}
+
+	sigChan := make(chan os.Signal, 2)
+	signals := l.config.Signals
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	signal.Notify(sigChan, signals...)
+	defer signal.Stop(sigChan)
+
+	timeout := l.config.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	started := 0
+	if err := runHooks(ctx, "start", l.onStart); err != nil {
+		errs = append(errs, err)
+	} else {
+		for _, s := range l.services {
+			s.service.Start(errChan)
+			started++
+			if drain(); len(errs) > 0 {
+				log.Printf("[Lifecycle] start %s failed, shutting down \n", s.name)
+				break
+			}
+		}
+	}
+
+	if len(errs) == 0 {
+		if err := runHooks(ctx, "ready", l.onReady); err != nil {
+			errs = append(errs, err)
+		} else {
+			if err := Ready(); err != nil {
+				log.Printf("[Lifecycle] notify parent process failed: %v \n", err)
+			}
+			log.Printf("[Lifecycle] %d services are ready \n", len(l.services))
+			restarted, err := l.wait(ctx, sigChan, errChan)
+			if err != nil {
+				errs = append(errs, err)
+			}
+			if restarted && l.config.Restart.DrainTimeout > 0 {
+				timeout = l.config.Restart.DrainTimeout
+			}
+		}
+	}
+
+	// 在 ShutdownTimeout 内同时停止已启动的服务，期间再次收到信号时立即退出
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	go func() {
+		select {
+		case sig := <-sigChan:
+			log.Printf("[Lifecycle] received %s again, exit immediately \n", sig)
+			cancel()
+		case <-shutdownCtx.Done():
+		}
+	}()
+	shutdownErrs := make([]error, started)
+	var wg sync.WaitGroup
+	for i, s := range l.services[:started] {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.service.Shutdown(shutdownCtx); err != nil {
+				shutdownErrs[i] = fmt.Errorf("shutdown %s: %w", s.name, err)
+			}
+		}()
+	}
+	wg.Wait()
+	errs = append(errs, shutdownErrs...)
+
+	if err := runHooks(shutdownCtx, "shutdown", l.onShutdown); err != nil {
+		errs = append(errs, err)
+	}
+	drain()
+	return errors.Join(errs...)
+}
+
+// ReportError 把服务运行中的错误写入 errChan，通道已满时丢弃并记录日志，不会阻塞
+// 用于 Start 启动的后台协程，服务停止后调用方可能已经不再读取 errChan
+func ReportError(errChan chan error, err error) {
+	select {
+	case errChan <- err:
+	default:
+		log.Printf("[Lifecycle] error dropped, nobody is reading errChan: %v \n", err)
+	}
+}
+
+// wait 阻塞直到需要退出，服务运行出错时返回该错误，平滑重启成功时 restarted 为 true
+func (l *Lifecycle) wait(ctx context.Context, sigChan chan os.Signal, errChan chan error) (restarted bool, err error) {
+	var restartChan chan os.Signal
+	if l.config.Restart != nil {
+		restartSignals := l.config.Restart.Signals
+		if len(restartSignals) == 0 {
+			restartSignals = defaultRestartSignals
+		}
+		if len(restartSignals) > 0 {
+			restartChan = make(chan os.Signal, 1)
+			signal.Notify(restartChan, restartSignals...)
+			defer signal.Stop(restartChan)
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("[Lifecycle] context done, shutting down \n")
+			return false, nil
+		case sig := <-sigChan:
+			log.Printf("[Lifecycle] received %s, shutting down \n", sig)
+			return false, nil
+		case err := <-errChan:
+			if err == nil {
+				continue
+			}
+			log.Printf("[Lifecycle] service failed, shutting down: %v \n", err)
+			return false, err
+		case sig := <-restartChan:
+			log.Printf("[Lifecycle] received %s, restarting \n", sig)
+			if err := Restart(*l.config.Restart, l.servers()...); err != nil {
+				log.Printf("[Lifecycle] restart failed, keep serving: %v \n", err)
+				continue
+			}
+			return true, nil
+		}
+	}
+}
+
+// servers 返回需要在平滑重启时传递监听的服务
+func (l *Lifecycle) servers() []*Server {
+	var servers []*Server
+	for _, s := range l.services {
+		if srv, ok := s.service.(*Server); ok {
+			servers = append(servers, srv)
+		}
+	}
+	return servers
+}
+
+// runHooks 按顺序执行钩子，遇到错误时停止
+func runHooks(ctx context.Context, phase string, hooks []Hook) error {
+	for i, hook := range hooks {
+		if err := hook(ctx); err != nil {
+			return fmt.Errorf("%s hook #%d: %w", phase, i+1, err)
+		}
+	}
+	return nil
+}
+
+// ------------------------------------------------------------ Server ------------------------------------------------------------
+
+// OnStart 注册 Run 启动服务前执行的钩子
+func (s *Server) OnStart(hook Hook) {
+	s.hooks.onStart = append(s.hooks.onStart, hook)
+}
+
+// OnReady 注册 Run 启动服务后执行的钩子
+func (s *Server) OnReady(hook Hook) {
+	s.hooks.onReady = append(s.hooks.onReady, hook)
+}
+
+// OnShutdown 注册 Run 停止服务后执行的钩子
+func (s *Server) OnShutdown(hook Hook) {
+	s.hooks.onShutdown = append(s.hooks.onShutdown, hook)
+}
+
+// Run 启动服务并阻塞，处理 SIGINT / SIGTERM 信号并在 Config.ShutdownTimeout 内优雅退出
+// 配置了 Config.Restart 时同时处理平滑重启信号；需要同时运行多个服务时使用 Lifecycle
+//
+// 示例:
+//
+//	srv.OnShutdown(func(ctx context.Context) error { return db.Close() })
+//	if err := srv.Run(context.Background()); err != nil {
+//		log.Fatal(err)
+//	}
+func (s *Server) Run(ctx context.Context) error {
+	l := NewLifecycle(LifecycleConfig{
+		ShutdownTimeout: s.config.ShutdownTimeout,
+		Restart:         s.config.Restart,
+	})
+	l.hooks = s.hooks
+	l.Add(s.config.Addr, s)
+	return l.Run(ctx)
+}
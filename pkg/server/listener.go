@@ -0,0 +1,302 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/stones-hub/taurus-pro-http/pkg/router"
+)
+
+// ListenerConfig 额外的监听配置，与 Config.Addr 的主监听同时提供服务
+// 典型用法: 公网 TCP 端口 + 内部管理端口 + 供 sidecar 使用的 Unix socket
+type ListenerConfig struct {
+	// Name 监听名称，用于日志，默认为 "network:addr"
+	Name string
+	// Network 网络类型，"tcp"（默认）、"tcp4"、"tcp6" 或 "unix"
+	Network string
+	// Addr TCP 地址（如 "127.0.0.1:9090"）或 Unix socket 路径（如 "/run/app/http.sock"）
+	Addr string
+	// Listener 预先打开的监听，设置后忽略 Network 和 Addr，如 systemd socket activation 传入的监听
+	Listener net.Listener
+	// SocketMode Unix socket 文件权限，默认 0660
+	SocketMode fs.FileMode
+
+	// Routes 该监听只暴露的路由，为空表示全部
+	// 支持精确匹配和前缀匹配（以 * 结尾），如 []string{"/metrics", "/admin/*"}；其他请求返回 404
+	Routes []string
+	// Middleware 只作用于该监听的中间件，在路由中间件之前执行
+	Middleware []router.MiddlewareFunc
+	// PlainText 为 true 时即使服务启用了 HTTPS 也使用明文，如本机 Unix socket 或内部管理端口
+	PlainText bool
+}
+
+// WithListener 主监听使用预先打开的 net.Listener 代替 Addr
+func WithListener(ln net.Listener) serverOption {
+	return func(s *Server) {
+		s.config.Listener = ln
+	}
+}
+
+// WithListeners 添加额外的监听
+// 示例: WithListeners(ListenerConfig{Network: "unix", Addr: "/run/app/http.sock", PlainText: true})
+func WithListeners(configs ...ListenerConfig) serverOption {
+	return func(s *Server) {
+		s.config.Listeners = append(s.config.Listeners, configs...)
+	}
+}
+
+// serverListener 一个已经打开的监听和为它提供服务的 http.Server
+type serverListener struct {
+	name   string
+	ln     net.Listener
+	server *http.Server
+	tls    bool
+	config *ListenerConfig // 额外监听的配置，主监听为 nil
+}
+
+// openListeners 打开主监听和所有额外监听，任何一个失败时关闭已打开的监听
+// 在 Start 中同步调用，端口被占用等错误可以立即返回
+func (s *Server) openListeners() error {
+	primary := s.config.Listener
+	if primary == nil {
+		ln, err := listen("tcp", s.config.Addr)
+		if err != nil {
+			return err
+		}
+		primary = ln
+	}
+	listeners := []*serverListener{{name: s.config.Addr, ln: primary, server: s.Server, tls: s.TLSConfig != nil}}
+	if s.config.Listener != nil {
+		listeners[0].name = listenerName(primary.Addr())
+	}
+
+	for i := range s.config.Listeners {
+		config := &s.config.Listeners[i]
+		l, err := s.openListener(config)
+		if err != nil {
+			for _, opened := range listeners {
+				opened.ln.Close()
+			}
+			return fmt.Errorf("listener %s: %w", listenerConfigName(config), err)
+		}
+		listeners = append(listeners, l)
+	}
+	s.listeners = listeners
+	return nil
+}
+
+// openListener 打开一个额外监听，并创建与主服务参数相同的 http.Server
+// 处理器在所有监听打开后由 bindHandlers 设置
+func (s *Server) openListener(config *ListenerConfig) (*serverListener, error) {
+	ln := config.Listener
+	if ln == nil {
+		network := config.Network
+		if network == "" {
+			network = "tcp"
+		}
+		var err error
+		if network == "unix" {
+			ln, err = listenUnix(config.Addr, config.SocketMode)
+		} else {
+			ln, err = listen(network, config.Addr)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tlsEnabled := s.TLSConfig != nil && !config.PlainText
+	srv := &http.Server{
+		Addr:           ln.Addr().String(),
+		ReadTimeout:    s.ReadTimeout,
+		WriteTimeout:   s.WriteTimeout,
+		IdleTimeout:    s.IdleTimeout,
+		MaxHeaderBytes: s.MaxHeaderBytes,
+		HTTP2:          s.HTTP2,
+		Protocols:      s.Protocols,
+		ErrorLog:       s.ErrorLog,
+	}
+	if tlsEnabled {
+		srv.TLSConfig = s.TLSConfig
+	}
+	return &serverListener{name: listenerConfigName(config), ln: ln, server: srv, tls: tlsEnabled, config: config}, nil
+}
+
+// bindHandlers 为额外监听创建处理器，在 s.Handler 完成所有包装（如 HTTP/3 的 Alt-Svc）之后调用
+func (s *Server) bindHandlers() {
+	for _, l := range s.listeners {
+		if l.config == nil {
+			continue
+		}
+		handler := s.Handler
+		if len(l.config.Routes) > 0 {
+			handler = routeFilter(l.config.Routes, handler)
+		}
+		l.server.Handler = router.ChainMiddleware(handler, l.config.Middleware...)
+	}
+}
+
+// serve 在监听上提供服务，阻塞直到服务关闭
+func (l *serverListener) serve() error {
+	if l.tls {
+		// 证书已经在 TLSConfig 中，不需要再传文件路径
+		return l.server.ServeTLS(l.ln, "", "")
+	}
+	return l.server.Serve(l.ln)
+}
+
+// listenUnix 监听 Unix socket，删除上次进程遗留的 socket 文件并设置权限
+func listenUnix(path string, mode fs.FileMode) (net.Listener, error) {
+	if path == "" {
+		return nil, errors.New("unix socket path is empty")
+	}
+	if file := takeInherited("unix", path); file != nil {
+		defer file.Close()
+		return net.FileListener(file)
+	}
+	// 只删除 socket 文件，避免配置错误时误删普通文件
+	if info, err := os.Lstat(path); err == nil && info.Mode()&fs.ModeSocket != 0 {
+		os.Remove(path)
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if mode == 0 {
+		mode = 0o660
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
+
+// routeFilter 只放行匹配的路径，其他请求返回 404
+func routeFilter(routes []string, next http.Handler) http.Handler {
+	exact := make(map[string]bool)
+	var prefixes []string
+	for _, route := range routes {
+		if prefix, ok := strings.CutSuffix(route, "*"); ok {
+			prefixes = append(prefixes, prefix)
+		} else {
+			exact[route] = true
+		}
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if exact[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(r.URL.Path, prefix) {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	})
+}
+
+// listenerConfigName 监听在日志中的名称
+func listenerConfigName(config *ListenerConfig) string {
+	if config.Name != "" {
+		return config.Name
+	}
+	if config.Listener != nil {
+		return listenerName(config.Listener.Addr())
+	}
+	network := config.Network
+	if network == "" {
+		network = "tcp"
+	}
+	return network + ":" + config.Addr
+}
+
+// listenerName 根据监听地址生成名称
+func listenerName(addr net.Addr) string {
+	if addr.Network() == "unix" {
+		return "unix:" + addr.String()
+	}
+	return addr.String()
+}
+
+// ------------------------------------------------------------ systemd socket activation ------------------------------------------------------------
+
+// listenFdsStart systemd 传递的第一个文件描述符
+const listenFdsStart = 3
+
+// SystemdListeners 返回 systemd socket activation 传入的监听，Name 为 .socket 中的 FileDescriptorName
+// 不是由 systemd 启动（LISTEN_PID 不是当前进程）时返回空列表
+// 读取后会清除 LISTEN_* 环境变量，避免子进程误用
+//
+// 示例:
+//
+//	config := server.Config{Addr: ":8080"}
+//	listeners, err := server.SystemdListeners()
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	if len(listeners) > 0 {
+//		// 不是由 systemd 启动时 listeners 为空，继续使用 Addr
+//		config.Listener, config.Listeners = listeners[0].Listener, listeners[1:]
+//	}
+//	srv := server.New(config)
+func SystemdListeners() ([]ListenerConfig, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count <= 0 {
+		return nil, nil
+	}
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	listeners := make([]ListenerConfig, 0, count)
+	for i := 0; i < count; i++ {
+		name := "LISTEN_FD_" + strconv.Itoa(listenFdsStart+i)
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+		// net.FileListener 会复制文件描述符，原描述符需要关闭
+		file := os.NewFile(uintptr(listenFdsStart+i), name)
+		ln, err := net.FileListener(file)
+		file.Close()
+		if err != nil {
+			for _, opened := range listeners {
+				opened.Listener.Close()
+			}
+			return nil, fmt.Errorf("systemd listener %s: %w", name, err)
+		}
+		listeners = append(listeners, ListenerConfig{Name: name, Listener: ln})
+	}
+	return listeners, nil
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stones-hub/taurus-pro-http/pkg/router"
+)
+
+// selfSignedCert 生成 127.0.0.1 的自签名证书
+func selfSignedCert(t *testing.T) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestExtraListenerAdvertisesHTTP3(t *testing.T) {
+	primary, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	extra, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := New(Config{
+		Listener:  primary,
+		TLS:       &TLSConfig{Certificates: []tls.Certificate{selfSignedCert(t)}},
+		HTTP3:     &HTTP3Config{Addr: "127.0.0.1:0", AdvertisedPort: 8443},
+		Listeners: []ListenerConfig{{Listener: extra, Routes: []string{"/ping"}}},
+	})
+	srv.AddRouter(router.Router{Path: "/ping", Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})})
+	errChan := make(chan error, 4)
+	srv.Start(errChan)
+	defer srv.Shutdown(context.Background())
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	defer client.CloseIdleConnections()
+	for _, ln := range []net.Listener{primary, extra} {
+		resp, err := client.Get("https://" + ln.Addr().String() + "/ping")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("%s: status = %d, want 200", ln.Addr(), resp.StatusCode)
+		}
+		if got, want := resp.Header.Get("Alt-Svc"), `h3=":8443"; ma=2592000`; got != want {
+			t.Errorf("%s: Alt-Svc = %q, want %q", ln.Addr(), got, want)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package server
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+)
+
+// HTTP2Config HTTP/2 调优参数，零值表示使用 Go 的默认值
+// 同时作用于 HTTPS 上的 h2 和明文的 h2c
+type HTTP2Config struct {
+	// MaxConcurrentStreams 每个连接允许客户端同时打开的流数量，默认至少 100
+	// 流式 RPC 客户端长期占用流时需要调大
+	MaxConcurrentStreams int
+	// MaxReadFrameSize 允许读取的最大帧大小，取值 16KiB ~ 16MiB
+	MaxReadFrameSize int
+	// MaxDecoderHeaderTableSize / MaxEncoderHeaderTableSize HPACK 头部压缩表大小上限，需小于 4MiB
+	MaxDecoderHeaderTableSize int
+	MaxEncoderHeaderTableSize int
+	// MaxReceiveBufferPerConnection 连接级流控窗口，取值 64KiB ~ 4MiB
+	MaxReceiveBufferPerConnection int
+	// MaxReceiveBufferPerStream 流级流控窗口，需小于 4MiB
+	MaxReceiveBufferPerStream int
+	// SendPingTimeout 连接空闲多久后发送 PING 检查连接是否存活，0 表示不检查
+	SendPingTimeout time.Duration
+	// PingTimeout 等待 PING 响应的时间，超时后关闭连接，默认 15 秒
+	PingTimeout time.Duration
+	// WriteByteTimeout 有数据待写但一直写不出去时关闭连接的时间，0 表示不限制
+	WriteByteTimeout time.Duration
+}
+
+// HTTP3Config HTTP/3（QUIC）配置，需要同时启用 TLS 或 ACME
+// HTTP/3 监听 UDP 端口，TCP 上的响应会通过 Alt-Svc 头通知客户端可以切换到 HTTP/3
+type HTTP3Config struct {
+	// Addr UDP 监听地址，默认与 Config.Addr 相同
+	Addr string
+	// AdvertisedPort Alt-Svc 中通告的端口，默认使用实际监听的端口
+	// 前面有 UDP 端口转发（如 443 -> 8443）时设置为客户端看到的端口
+	AdvertisedPort int
+	// MaxIncomingStreams 每个连接允许的并发请求数，默认 100
+	MaxIncomingStreams int64
+	// MaxIdleTimeout QUIC 连接空闲超时，默认 30 秒
+	MaxIdleTimeout time.Duration
+	// KeepAlivePeriod 发送保活包的周期，0 表示不发送
+	KeepAlivePeriod time.Duration
+}
+
+// WithH2C 在明文监听上启用 HTTP/2（h2c），用于 TLS 在代理终止、后端使用 HTTP/2 的场景（如 gRPC 流式调用）
+func WithH2C() serverOption {
+	return func(s *Server) {
+		s.config.H2C = true
+	}
+}
+
+// WithHTTP2 设置 HTTP/2 调优参数
+func WithHTTP2(config *HTTP2Config) serverOption {
+	return func(s *Server) {
+		s.config.HTTP2 = config
+	}
+}
+
+// WithHTTP3 启用 HTTP/3 监听
+// 示例: WithTLS(&TLSConfig{...}), WithHTTP3(&HTTP3Config{})
+func WithHTTP3(config *HTTP3Config) serverOption {
+	return func(s *Server) {
+		s.config.HTTP3 = config
+	}
+}
+
+// setupProtocols 根据配置设置 http.Server 支持的协议和 HTTP/2 参数
+func (s *Server) setupProtocols() error {
+	if s.config.HTTP2 != nil {
+		config := s.config.HTTP2
+		if config.MaxReadFrameSize != 0 && (config.MaxReadFrameSize < 16<<10 || config.MaxReadFrameSize > 16<<20) {
+			return fmt.Errorf("HTTP/2 MaxReadFrameSize %d out of range [16KiB, 16MiB]", config.MaxReadFrameSize)
+		}
+		if config.MaxReceiveBufferPerConnection != 0 && (config.MaxReceiveBufferPerConnection < 64<<10 || config.MaxReceiveBufferPerConnection >= 4<<20) {
+			return fmt.Errorf("HTTP/2 MaxReceiveBufferPerConnection %d out of range [64KiB, 4MiB)", config.MaxReceiveBufferPerConnection)
+		}
+		for name, size := range map[string]int{
+			"MaxDecoderHeaderTableSize": config.MaxDecoderHeaderTableSize,
+			"MaxEncoderHeaderTableSize": config.MaxEncoderHeaderTableSize,
+			"MaxReceiveBufferPerStream": config.MaxReceiveBufferPerStream,
+		} {
+			if size < 0 || size >= 4<<20 {
+				return fmt.Errorf("HTTP/2 %s %d out of range [0, 4MiB)", name, size)
+			}
+		}
+		s.HTTP2 = &http.HTTP2Config{
+			MaxConcurrentStreams:          config.MaxConcurrentStreams,
+			MaxReadFrameSize:              config.MaxReadFrameSize,
+			MaxDecoderHeaderTableSize:     config.MaxDecoderHeaderTableSize,
+			MaxEncoderHeaderTableSize:     config.MaxEncoderHeaderTableSize,
+			MaxReceiveBufferPerConnection: config.MaxReceiveBufferPerConnection,
+			MaxReceiveBufferPerStream:     config.MaxReceiveBufferPerStream,
+			SendPingTimeout:               config.SendPingTimeout,
+			PingTimeout:                   config.PingTimeout,
+			WriteByteTimeout:              config.WriteByteTimeout,
+		}
+	}
+
+	if s.config.H2C {
+		// 只支持 prior knowledge 方式（客户端直接发送 HTTP/2 连接前言），不支持 "Upgrade: h2c"
+		protocols := new(http.Protocols)
+		protocols.SetHTTP1(true)
+		protocols.SetHTTP2(true)
+		protocols.SetUnencryptedHTTP2(true)
+		s.Protocols = protocols
+	}
+	return nil
+}
+
+// startHTTP3 启动 HTTP/3 监听，并在 TCP 响应中添加 Alt-Svc 头
+// 需要在 setupTLS 之后调用，UDP 端口在返回前已经打开
+func (s *Server) startHTTP3(errChan chan error) error {
+	config := s.config.HTTP3
+	if config == nil {
+		return nil
+	}
+	if s.TLSConfig == nil {
+		return errors.New("HTTP/3 requires TLS or ACME to be configured")
+	}
+	addr := config.Addr
+	if addr == "" {
+		addr = s.config.Addr
+	}
+	conn, err := listenPacket("udp", addr)
+	if err != nil {
+		return err
+	}
+
+	s.http3 = &http3.Server{
+		Addr:           addr,
+		Port:           config.AdvertisedPort,
+		TLSConfig:      s.TLSConfig,
+		Handler:        s.Handler,
+		MaxHeaderBytes: s.config.MaxHeaderBytes,
+		IdleTimeout:    s.config.IdleTimeout,
+		QUICConfig: &quic.Config{
+			MaxIncomingStreams: config.MaxIncomingStreams,
+			MaxIdleTimeout:     config.MaxIdleTimeout,
+			KeepAlivePeriod:    config.KeepAlivePeriod,
+		},
+	}
+	s.http3Conn = conn
+	h3 := s.http3
+	go func() {
+		log.Printf("Server is running on %s (http3) \n", conn.LocalAddr())
+		if err := h3.Serve(conn); err != nil && err != http.ErrServerClosed && !errors.Is(err, quic.ErrServerClosed) {
+			log.Printf("HTTP/3 server start failed on %s: %v \n", addr, err)
+			ReportError(errChan, err)
+		}
+		conn.Close()
+	}()
+
+	next := s.Handler
+	s.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Serve 尚未注册监听时没有可通告的端口，忽略错误
+		_ = h3.SetQUICHeaders(w.Header())
+		next.ServeHTTP(w, r)
+	})
+	return nil
+}
@@ -0,0 +1,456 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// 平滑重启（零停机发布）
+//
+// 流程:
+//  1. 父进程收到 SIGHUP / SIGUSR2 后，把所有监听的文件描述符传给重新执行的子进程（新的二进制）
+//  2. 子进程启动时直接使用继承的监听，不需要重新绑定端口，启动完成后调用 Ready 通知父进程
+//  3. 父进程收到就绪通知后停止接受新连接，等待处理中的请求完成后退出
+//
+// 子进程启动失败或超时未就绪时，父进程继续提供服务，不影响线上流量
+
+const (
+	// envRestartListeners 继承的监听列表，每行一个 "network:addr"，顺序与文件描述符一致
+	envRestartListeners = "TAURUS_RESTART_LISTENERS"
+	// envRestartReadyFD 子进程就绪时写入的管道
+	envRestartReadyFD = "TAURUS_RESTART_READY_FD"
+)
+
+// RestartConfig 平滑重启配置
+type RestartConfig struct {
+	// Signals 触发重启的信号，默认 SIGHUP 和 SIGUSR2（Windows 不支持平滑重启）
+	Signals []os.Signal
+	// ReadyTimeout 等待子进程就绪的时间，默认 1 分钟，超时后杀死子进程并继续提供服务
+	ReadyTimeout time.Duration
+	// DrainTimeout 子进程就绪后，父进程等待处理中请求完成的时间，默认 30 秒
+	DrainTimeout time.Duration
+	// Executable 子进程执行的程序，默认当前程序路径（发布时替换的新二进制）
+	Executable string
+	// Args 子进程的参数（不含程序名），默认与当前进程相同
+	Args []string
+}
+
+// inheritedFile 从父进程继承的监听文件描述符
+type inheritedFile struct {
+	network string
+	addr    string
+	file    *os.File
+	used    bool
+}
+
+var (
+	inheritOnce sync.Once
+	inheritMu   sync.Mutex
+	inherited   []*inheritedFile
+	readyFile   *os.File
+	restarting  atomic.Bool
+)
+
+// loadInherited 读取父进程传入的监听和就绪管道，只执行一次
+// 读取后清除环境变量，避免再次重启时传给下一代子进程
+func loadInherited() {
+	inheritOnce.Do(func() {
+		names := os.Getenv(envRestartListeners)
+		readyFD := os.Getenv(envRestartReadyFD)
+		os.Unsetenv(envRestartListeners)
+		os.Unsetenv(envRestartReadyFD)
+		if readyFD == "" {
+			return
+		}
+		if fd, err := strconv.Atoi(readyFD); err == nil {
+			readyFile = os.NewFile(uintptr(fd), "restart-ready")
+		}
+		if names == "" {
+			return
+		}
+		for i, name := range strings.Split(names, "\n") {
+			network, addr, _ := strings.Cut(name, ":")
+			inherited = append(inherited, &inheritedFile{
+				network: network,
+				addr:    addr,
+				file:    os.NewFile(uintptr(listenFdsStart+i), name),
+			})
+		}
+		log.Printf("[Restart] inherited %d listeners from parent process %d \n", len(inherited), os.Getppid())
+	})
+}
+
+// IsRestartChild 当前进程是否由平滑重启启动
+func IsRestartChild() bool {
+	loadInherited()
+	return readyFile != nil
+}
+
+// Ready 通知父进程子进程已经启动完成，父进程收到后开始退出
+// 在所有服务 Start 完成后调用；不是由平滑重启启动时不做任何事
+// 同时关闭没有被使用的继承监听（如新版本去掉了某个端口）
+func Ready() error {
+	loadInherited()
+	inheritMu.Lock()
+	defer inheritMu.Unlock()
+	for _, f := range inherited {
+		if !f.used {
+			log.Printf("[Restart] inherited listener %s:%s is not used, closing \n", f.network, f.addr)
+			f.file.Close()
+			f.used = true
+		}
+	}
+	if readyFile == nil {
+		return nil
+	}
+	_, err := readyFile.Write([]byte{1})
+	readyFile.Close()
+	readyFile = nil
+	return err
+}
+
+// takeInherited 查找与 network/addr 匹配的继承监听，找到时标记为已使用并返回文件
+func takeInherited(network, addr string) *os.File {
+	loadInherited()
+	inheritMu.Lock()
+	defer inheritMu.Unlock()
+	for _, f := range inherited {
+		if !f.used && sameAddr(network, addr, f.network, f.addr) {
+			f.used = true
+			return f.file
+		}
+	}
+	return nil
+}
+
+// sameAddr 配置的地址是否与继承监听的实际地址相同
+// 继承监听记录的是实际地址（如 "[::]:8080"），配置可能是 ":8080"，因此按解析后的 IP 和端口比较
+func sameAddr(network, addr, inheritedNetwork, inheritedAddr string) bool {
+	if network == "unix" || inheritedNetwork == "unix" {
+		return network == inheritedNetwork && addr == inheritedAddr
+	}
+	// tcp / tcp4 / tcp6 视为同一类，udp 同理
+	if strings.TrimRight(network, "46") != strings.TrimRight(inheritedNetwork, "46") {
+		return false
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	inheritedHost, inheritedPort, err := net.SplitHostPort(inheritedAddr)
+	if err != nil || port != inheritedPort {
+		return false
+	}
+	ip, inheritedIP := net.ParseIP(host), net.ParseIP(inheritedHost)
+	if host == "" || (ip != nil && ip.IsUnspecified()) {
+		return inheritedIP != nil && inheritedIP.IsUnspecified()
+	}
+	if ip == nil {
+		// 主机名（如 localhost），解析后比较
+		ips, err := net.LookupIP(host)
+		if err != nil {
+			return false
+		}
+		for _, candidate := range ips {
+			if candidate.Equal(inheritedIP) {
+				return true
+			}
+		}
+		return false
+	}
+	return ip.Equal(inheritedIP)
+}
+
+// listen 打开 TCP 监听，优先使用父进程传入的监听
+func listen(network, addr string) (net.Listener, error) {
+	if file := takeInherited(network, addr); file != nil {
+		defer file.Close()
+		return net.FileListener(file)
+	}
+	return net.Listen(network, addr)
+}
+
+// listenPacket 打开 UDP 监听（HTTP/3），优先使用父进程传入的监听
+func listenPacket(network, addr string) (net.PacketConn, error) {
+	if file := takeInherited(network, addr); file != nil {
+		defer file.Close()
+		return net.FilePacketConn(file)
+	}
+	return net.ListenPacket(network, addr)
+}
+
+// fileConn 可以导出文件描述符的监听，*net.TCPListener、*net.UnixListener、*net.UDPConn 都实现了该接口
+type fileConn interface {
+	File() (*os.File, error)
+}
+
+// restartFiles 导出服务的所有监听（包括 HTTP/3 和 ACME HTTP-01 验证监听），返回的文件由调用方关闭
+func (s *Server) restartFiles() (names []string, files []*os.File, err error) {
+	add := func(addr net.Addr, conn any) error {
+		fc, ok := conn.(fileConn)
+		if !ok {
+			return fmt.Errorf("listener %s does not support file descriptor passing", addr)
+		}
+		file, err := fc.File()
+		if err != nil {
+			return err
+		}
+		names = append(names, addr.Network()+":"+addr.String())
+		files = append(files, file)
+		return nil
+	}
+	for _, l := range s.listeners {
+		if err = add(l.ln.Addr(), l.ln); err != nil {
+			break
+		}
+	}
+	if err == nil && s.http3Conn != nil {
+		err = add(s.http3Conn.LocalAddr(), s.http3Conn)
+	}
+	if err == nil && s.acme != nil && s.acme.httpListener != nil {
+		err = add(s.acme.httpListener.Addr(), s.acme.httpListener)
+	}
+	if err != nil {
+		for _, file := range files {
+			file.Close()
+		}
+		return nil, nil, err
+	}
+	return names, files, nil
+}
+
+// rawFD 返回文件描述符，传给子进程时使用
+// 不能使用 (*os.File).Fd: 它会把描述符切换为阻塞模式，导出的文件与监听共享该标志，
+// 父进程的 Accept 会阻塞在系统调用中，Shutdown 关闭监听时一直等待
+func rawFD(file *os.File) (uintptr, error) {
+	conn, err := file.SyscallConn()
+	if err != nil {
+		return 0, err
+	}
+	var fd uintptr
+	if err := conn.Control(func(s uintptr) { fd = s }); err != nil {
+		return 0, err
+	}
+	return fd, nil
+}
+
+// keepUnixSockets 关闭 Unix socket 监听时不删除 socket 文件，重启成功后子进程还在使用
+func (s *Server) keepUnixSockets() {
+	for _, l := range s.listeners {
+		if ul, ok := l.ln.(*net.UnixListener); ok {
+			ul.SetUnlinkOnClose(false)
+		}
+	}
+}
+
+// Restart 启动新进程并把所有服务的监听传给它，等待新进程调用 Ready 后返回
+// 返回 nil 后调用方应当 Shutdown 所有服务并退出；返回错误时当前进程继续提供服务
+func Restart(config RestartConfig, servers ...*Server) error {
+	if !restarting.CompareAndSwap(false, true) {
+		return errors.New("restart is already in progress")
+	}
+	succeeded := false
+	defer func() {
+		if !succeeded {
+			restarting.Store(false)
+		}
+	}()
+
+	var names []string
+	var files []*os.File
+	defer func() {
+		for _, file := range files {
+			file.Close()
+		}
+	}()
+	for _, s := range servers {
+		n, f, err := s.restartFiles()
+		if err != nil {
+			return err
+		}
+		names = append(names, n...)
+		files = append(files, f...)
+	}
+
+	readyReader, readyWriter, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer readyReader.Close()
+
+	executable := config.Executable
+	if executable == "" {
+		if executable, err = os.Executable(); err != nil {
+			readyWriter.Close()
+			return err
+		}
+	}
+	args := config.Args
+	if args == nil {
+		args = os.Args[1:]
+	}
+
+	if path, err := exec.LookPath(executable); err == nil {
+		executable = path
+	}
+	// 标准输入输出 + 监听 + 就绪管道，子进程中依次为 0、1、2、3...
+	childFiles := append(append([]*os.File{os.Stdin, os.Stdout, os.Stderr}, files...), readyWriter)
+	fds := make([]uintptr, 0, len(childFiles))
+	for _, file := range childFiles {
+		fd, err := rawFD(file)
+		if err != nil {
+			readyWriter.Close()
+			return err
+		}
+		fds = append(fds, fd)
+	}
+	env := append(os.Environ(),
+		envRestartListeners+"="+strings.Join(names, "\n"),
+		envRestartReadyFD+"="+strconv.Itoa(listenFdsStart+len(files)),
+	)
+	pid, _, err := syscall.StartProcess(executable, append([]string{executable}, args...), &syscall.ProcAttr{Env: env, Files: fds})
+	runtime.KeepAlive(childFiles)
+	readyWriter.Close()
+	if err != nil {
+		return err
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	log.Printf("[Restart] started new process %d with %d listeners \n", pid, len(files))
+
+	exited := make(chan error, 1)
+	go func() {
+		state, err := process.Wait()
+		if err == nil {
+			err = errors.New(state.String())
+		}
+		exited <- err
+	}()
+	ready := make(chan error, 1)
+	go func() {
+		// 子进程写入就绪标记，或退出时管道关闭（读到 EOF）
+		buf := make([]byte, 1)
+		n, err := readyReader.Read(buf)
+		if n == 1 {
+			ready <- nil
+			return
+		}
+		ready <- fmt.Errorf("new process exited before ready: %v", err)
+	}()
+
+	timeout := config.ReadyTimeout
+	if timeout <= 0 {
+		timeout = time.Minute
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-ready:
+		if err != nil {
+			process.Kill()
+			return err
+		}
+	case err := <-exited:
+		return fmt.Errorf("new process exited before ready: %v", err)
+	case <-timer.C:
+		process.Kill()
+		return fmt.Errorf("new process was not ready within %s", timeout)
+	}
+	succeeded = true
+	for _, s := range servers {
+		s.keepUnixSockets()
+	}
+	log.Printf("[Restart] new process %d is ready \n", pid)
+	return nil
+}
+
+// HandleRestart 监听重启信号，收到信号后调用 Restart，成功后优雅关闭所有服务并返回 nil
+// 重启失败时记录日志并继续等待下一次信号；ctx 结束时返回 ctx.Err()
+//
+// 示例:
+//
+//	srv.Start(errChan)
+//	server.Ready()
+//	if err := server.HandleRestart(ctx, server.RestartConfig{}, srv); err == nil {
+//		return // 新进程已接管，退出
+//	}
+func HandleRestart(ctx context.Context, config RestartConfig, servers ...*Server) error {
+	signals := config.Signals
+	if len(signals) == 0 {
+		signals = defaultRestartSignals
+	}
+	if len(signals) == 0 {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, signals...)
+	defer signal.Stop(sigChan)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case sig := <-sigChan:
+			log.Printf("[Restart] received %s, restarting \n", sig)
+			if err := Restart(config, servers...); err != nil {
+				log.Printf("[Restart] restart failed, keep serving: %v \n", err)
+				continue
+			}
+			return shutdownAll(config.DrainTimeout, servers...)
+		}
+	}
+}
+
+// shutdownAll 在 timeout 内同时优雅关闭所有服务，返回所有错误
+func shutdownAll(timeout time.Duration, servers ...*Server) error {
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	errs := make([]error, len(servers))
+	var wg sync.WaitGroup
+	for i, s := range servers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = s.Shutdown(ctx)
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
@@ -0,0 +1,216 @@
+//go:build unix
+
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package server
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stones-hub/taurus-pro-http/pkg/router"
+)
+
+// 子进程模式，由 Restart 重新执行测试程序时通过环境变量传入
+const (
+	envRestartHelper     = "GO_WANT_RESTART_HELPER" // "serve" 正常接管监听，"fail" 就绪前退出
+	envRestartHelperAddr = "GO_RESTART_HELPER_ADDR" // TCP 监听地址
+	envRestartHelperSock = "GO_RESTART_HELPER_SOCK" // Unix socket 路径
+)
+
+// TestRestartHelperProcess 不是真正的测试，作为 Restart 启动的子进程运行
+func TestRestartHelperProcess(t *testing.T) {
+	mode := os.Getenv(envRestartHelper)
+	if mode == "" {
+		return
+	}
+	// 不输出测试框架的 PASS，避免混入父进程的测试输出
+	defer os.Exit(0)
+	if !IsRestartChild() {
+		os.Exit(2)
+	}
+	if mode == "fail" {
+		os.Exit(1)
+	}
+
+	exit := make(chan struct{})
+	srv := New(Config{
+		Addr:      os.Getenv(envRestartHelperAddr),
+		Listeners: []ListenerConfig{{Network: "unix", Addr: os.Getenv(envRestartHelperSock)}},
+	})
+	srv.AddRouter(router.Router{Path: "/who", Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "child")
+	})})
+	srv.AddRouter(router.Router{Path: "/exit", Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(exit)
+	})})
+	errChan := make(chan error, 4)
+	srv.Start(errChan)
+	if err := Ready(); err != nil {
+		os.Exit(3)
+	}
+	select {
+	case <-exit:
+	case <-errChan:
+		os.Exit(4)
+	case <-time.After(30 * time.Second):
+	}
+	srv.Shutdown(context.Background())
+}
+
+// startRestartParent 启动父进程的服务，监听 TCP 端口和 Unix socket
+func startRestartParent(t *testing.T) (srv *Server, addr, sock string) {
+	t.Helper()
+	sock = filepath.Join(t.TempDir(), "http.sock")
+	srv = New(Config{
+		Addr:      "127.0.0.1:0",
+		Listeners: []ListenerConfig{{Network: "unix", Addr: sock}},
+	})
+	srv.AddRouter(router.Router{Path: "/who", Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "parent")
+	})})
+	errChan := make(chan error, 4)
+	srv.Start(errChan)
+	select {
+	case err := <-errChan:
+		t.Fatal(err)
+	default:
+	}
+	return srv, srv.listeners[0].ln.Addr().String(), sock
+}
+
+// whoAmI 通过 TCP 或 Unix socket 请求 /who，每次都建立新连接
+func whoAmI(t *testing.T, network, addr string) string {
+	t.Helper()
+	client := &http.Client{Timeout: 5 * time.Second, Transport: &http.Transport{
+		DisableKeepAlives: true,
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, addr)
+		},
+	}}
+	resp, err := client.Get("http://restart.test/who")
+	if err != nil {
+		t.Fatalf("GET /who over %s: %v", network, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return string(body)
+}
+
+// restartHelperConfig 以 mode 重新执行当前测试程序
+func restartHelperConfig(t *testing.T, mode, addr, sock string) RestartConfig {
+	t.Setenv(envRestartHelper, mode)
+	t.Setenv(envRestartHelperAddr, addr)
+	t.Setenv(envRestartHelperSock, sock)
+	return RestartConfig{
+		Executable:   os.Args[0],
+		Args:         []string{"-test.run=^TestRestartHelperProcess$"},
+		ReadyTimeout: 10 * time.Second,
+	}
+}
+
+func TestRestartHandsOverListeners(t *testing.T) {
+	if os.Getenv(envRestartHelper) != "" {
+		t.Skip("running as the restart helper process")
+	}
+	srv, addr, sock := startRestartParent(t)
+	if got := whoAmI(t, "tcp", addr); got != "parent" {
+		t.Fatalf("before restart: got %q, want parent", got)
+	}
+
+	// Restart 在子进程通过就绪管道通知后才返回
+	if err := Restart(restartHelperConfig(t, "serve", addr, sock), srv); err != nil {
+		t.Fatalf("Restart: %v", err)
+	}
+	if err := srv.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	restarting.Store(false)
+
+	// 父进程关闭后，同一个 TCP 端口和 Unix socket 由子进程继续提供服务
+	for _, target := range [][2]string{{"tcp", addr}, {"unix", sock}} {
+		if got := whoAmI(t, target[0], target[1]); got != "child" {
+			t.Errorf("after restart over %s: got %q, want child", target[0], got)
+		}
+	}
+	client := &http.Client{Timeout: 5 * time.Second}
+	if resp, err := client.Get("http://" + addr + "/exit"); err == nil {
+		resp.Body.Close()
+	}
+}
+
+func TestRestartChildExitsBeforeReady(t *testing.T) {
+	if os.Getenv(envRestartHelper) != "" {
+		t.Skip("running as the restart helper process")
+	}
+	srv, addr, sock := startRestartParent(t)
+	defer srv.Shutdown(context.Background())
+
+	err := Restart(restartHelperConfig(t, "fail", addr, sock), srv)
+	if err == nil || !strings.Contains(err.Error(), "exited before ready") {
+		t.Fatalf("Restart = %v, want an exited before ready error", err)
+	}
+	// 重启失败时父进程继续提供服务，且可以再次重启
+	if got := whoAmI(t, "tcp", addr); got != "parent" {
+		t.Errorf("after failed restart: got %q, want parent", got)
+	}
+	if restarting.Load() {
+		t.Error("restart is still marked in progress after a failure")
+	}
+}
+
+func TestRestartFilesIncludeACMEChallengeListener(t *testing.T) {
+	ca := newFakeACME(t)
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca.resolve(testDomain, ln.Addr().String())
+	srv := New(Config{
+		Listener: ln,
+		ACME: &ACMEConfig{
+			Domains:           []string{testDomain},
+			DirectoryURL:      ca.URL,
+			Cache:             NewMemoryCertCache(),
+			HTTPChallengeAddr: "127.0.0.1:0",
+		},
+	})
+	errChan := make(chan error, 4)
+	srv.Start(errChan)
+	defer srv.Shutdown(context.Background())
+
+	names, files, err := srv.restartFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		file.Close()
+	}
+	want := "tcp:" + srv.acme.httpListener.Addr().String()
+	if len(names) != 2 || names[1] != want {
+		t.Errorf("restart listeners = %v, want the primary listener and %s", names, want)
+	}
+}
@@ -21,9 +21,12 @@ package server
 import (
 	"context"
 	"log"
+	"net"
 	"net/http"
+	"sync/atomic"
 	"time"
 
+	"github.com/quic-go/quic-go/http3"
 	"github.com/stones-hub/taurus-pro-http/pkg/router"
 )
 
@@ -77,6 +80,44 @@ type Config struct {
 	//   - 需要大Cookie: 2-4MB
 	//   - 安全要求高: 512KB-1MB
 	MaxHeaderBytes int
+
+	// TLS HTTPS 配置，为 nil 时使用 HTTP
+	// 作用: 由服务器直接终止 TLS，支持证书热加载和客户端证书校验（mTLS）
+	// 配置建议: 前面有负责 TLS 的负载均衡时不需要配置
+	TLS *TLSConfig
+
+	// ACME 自动申请和续期证书（如 Let's Encrypt），设置后证书由 ACME 提供，TLS 中的证书配置被忽略
+	// 作用: 小规模边缘部署无需手动管理证书，TLS 中的版本、加密套件和 mTLS 配置仍然生效
+	ACME *ACMEConfig
+
+	// H2C 在明文（非 TLS）监听上启用 HTTP/2
+	// 作用: TLS 在代理终止、代理到后端使用 HTTP/2 时（如 gRPC 流式调用）需要开启
+	// 注意: 只支持客户端直接发送 HTTP/2 连接前言（prior knowledge），不支持 "Upgrade: h2c" 升级
+	H2C bool
+
+	// HTTP2 HTTP/2 调优参数（最大并发流、帧大小、流控窗口等），为 nil 时使用 Go 的默认值
+	HTTP2 *HTTP2Config
+
+	// HTTP3 在 UDP 上同时提供 HTTP/3（QUIC），为 nil 时不启用；需要配置 TLS 或 ACME
+	HTTP3 *HTTP3Config
+
+	// Listener 主监听使用预先打开的监听代替 Addr，如 systemd socket activation（见 SystemdListeners）
+	Listener net.Listener
+
+	// Listeners 额外的监听（TCP 或 Unix socket），与主监听共享路由和超时配置
+	// 每个监听可以只暴露部分路由、挂载独立的中间件，如内部管理端口只暴露 /metrics
+	Listeners []ListenerConfig
+
+	// ShutdownTimeout Run 收到退出信号后等待处理中请求完成的时间（grace period）
+	// 配置建议: 略大于最慢接口的耗时，并小于部署平台的强制终止时间（如 Kubernetes 的 terminationGracePeriodSeconds）
+	ShutdownTimeout time.Duration
+
+	// Restart 平滑重启配置，设置后 Run 会处理 SIGHUP / SIGUSR2 信号，见 Restart
+	Restart *RestartConfig
+
+	// Health 健康检查端点（/healthz、/readyz、/livez）配置，为 nil 时不注册
+	// 检查通过 AddHealthCheck 注册；服务开始关闭后 /readyz 立即返回失败
+	Health *HealthConfig
 }
 
 // DefaultConfig 默认配置
@@ -88,11 +129,12 @@ type Config struct {
 //
 // 适用场景: 高并发API服务、Web应用、微服务等
 var DefaultConfig = Config{
-	Addr:           ":8080",           // 服务器监听地址，默认监听所有网卡的8080端口
-	ReadTimeout:    30 * time.Second,  // 读取超时时间：从连接建立到读取完整个HTTP请求的最大时间
-	WriteTimeout:   30 * time.Second,  // 写入超时时间：从开始写入HTTP响应到完成的最大时间
-	IdleTimeout:    180 * time.Second, // 空闲超时时间：HTTP keepalive连接的最大空闲时间，影响连接复用效率
-	MaxHeaderBytes: 1 << 20,           // 最大请求头大小：限制HTTP请求头的最大字节数，防止恶意大请求头攻击
+	Addr:            ":8080",           // 服务器监听地址，默认监听所有网卡的8080端口
+	ReadTimeout:     30 * time.Second,  // 读取超时时间：从连接建立到读取完整个HTTP请求的最大时间
+	WriteTimeout:    30 * time.Second,  // 写入超时时间：从开始写入HTTP响应到完成的最大时间
+	IdleTimeout:     180 * time.Second, // 空闲超时时间：HTTP keepalive连接的最大空闲时间，影响连接复用效率
+	MaxHeaderBytes:  1 << 20,           // 最大请求头大小：限制HTTP请求头的最大字节数，防止恶意大请求头攻击
+	ShutdownTimeout: 30 * time.Second,  // 优雅退出时间：Run 收到退出信号后等待处理中请求完成的最大时间
 }
 
 // serverOption 服务器配置选项函数类型
@@ -151,6 +193,13 @@ func WithIdleTimeout(idleTimeout time.Duration) serverOption {
 	}
 }
 
+// WithShutdownTimeout 设置 Run 优雅退出的等待时间
+func WithShutdownTimeout(shutdownTimeout time.Duration) serverOption {
+	return func(s *Server) {
+		s.config.ShutdownTimeout = shutdownTimeout
+	}
+}
+
 // WithMaxHeaderBytes 设置最大请求头大小
 // 参数: maxHeaderBytes - 请求头最大字节数，建议使用 1 << 20 (1MB)
 // 安全考虑: 防止恶意大请求头攻击
@@ -165,8 +214,19 @@ func WithMaxHeaderBytes(maxHeaderBytes int) serverOption {
 // Server HTTP server
 type Server struct {
 	*http.Server
-	config Config
-	router *router.RouterManager
+	config    Config
+	router    *router.RouterManager
+	startTime time.Time         // 服务启动时间，Start 调用时设置
+	certs     *certReloader     // 从文件加载的 TLS 证书，未启用 HTTPS 或使用内存证书时为 nil
+	acme      *acmeManager      // ACME 证书管理，未启用时为 nil
+	http3     *http3.Server     // HTTP/3 服务，未启用时为 nil
+	http3Conn net.PacketConn    // HTTP/3 的 UDP 监听，平滑重启时传给子进程
+	listeners []*serverListener // 已打开的监听，第一个为主监听
+	hooks     hooks             // Run 使用的生命周期钩子
+	drain     *drainer          // 长连接处理器，Start 调用时创建
+	health    health            // 健康检查注册表和结果缓存
+
+	shuttingDown atomic.Bool // Shutdown 已经开始，readiness 返回失败
 }
 
 // NewServer create a new server instance
@@ -210,6 +270,9 @@ func New(config Config) *Server {
 	if config.MaxHeaderBytes == 0 {
 		config.MaxHeaderBytes = DefaultConfig.MaxHeaderBytes
 	}
+	if config.ShutdownTimeout == 0 {
+		config.ShutdownTimeout = DefaultConfig.ShutdownTimeout
+	}
 
 	// 创建服务器实例
 	srv := &Server{
@@ -237,33 +300,149 @@ func (s *Server) AddRouterGroup(group router.RouteGroup) {
 	s.router.AddRouterGroup(group)
 }
 
+// SetAccessEnforcer set the enforcer for router access rules, e.g. auth.Authorizer.Enforce
+func (s *Server) SetAccessEnforcer(enforcer router.AccessEnforcer) {
+	s.router.SetAccessEnforcer(enforcer)
+}
+
+// Routes 返回已注册的路由，用于展示路由表
+func (s *Server) Routes() []router.RouteInfo {
+	return s.router.Routes()
+}
+
 // Get Server config
 func (s *Server) GetConfig() Config {
 	return s.config
 }
 
+// StartTime 返回服务启动时间，未启动时为零值
+func (s *Server) StartTime() time.Time {
+	return s.startTime
+}
+
+// reportStartError 在后台协程中写入启动失败的错误
+// Start 在调用方的协程中执行，调用方要等 Start 返回后才会读取 errChan，直接写入无缓冲通道会死锁
+func reportStartError(errChan chan error, err error) {
+	go func() { errChan <- err }()
+}
+
 // Start start server
+// 启动失败和运行中的错误都写入 errChan，Start 本身不会阻塞，errChan 可以是无缓冲通道
 func (s *Server) Start(errChan chan error) {
 	// load all routes
-	s.Handler = s.router.LoadRoutes()
+	s.registerHealthRoutes()
+	s.drain = newDrainer()
+	s.Handler = s.drain.wrap(s.router.LoadRoutes())
+	s.startTime = time.Now()
+
+	if err := s.setupProtocols(); err != nil {
+		log.Printf("Server protocol config invalid: %v \n", err)
+		reportStartError(errChan, err)
+		return
+	}
+	if err := s.setupTLS(); err != nil {
+		log.Printf("Server TLS config invalid: %v \n", err)
+		reportStartError(errChan, err)
+		return
+	}
+	if err := s.openListeners(); err != nil {
+		log.Printf("Server listen failed on %s: %v \n", s.config.Addr, err)
+		reportStartError(errChan, err)
+		return
+	}
+	if s.acme != nil {
+		if err := s.acme.listen(); err != nil {
+			log.Printf("Server listen failed: %v \n", err)
+			s.closeListeners()
+			reportStartError(errChan, err)
+			return
+		}
+	}
+	if err := s.startHTTP3(errChan); err != nil {
+		log.Printf("Server HTTP/3 start failed: %v \n", err)
+		s.closeListeners()
+		reportStartError(errChan, err)
+		return
+	}
+	s.bindHandlers()
 
 	// start server
-	go func() {
-		log.Printf("Server is running on %s \n", s.config.Addr)
-		// when server startup failed, write error to errChan.
-		// But http.ErrServerClosed is not an error,,because it is expected when the server is closed.
-		// ListenAndServe is a blocking call
-		if err := s.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Printf("Server start failed on %s \n", s.config.Addr)
-			errChan <- err
-		}
-	}()
+	for _, l := range s.listeners {
+		go func(l *serverListener) {
+			scheme := "http"
+			if l.tls {
+				scheme = "https"
+			} else if s.config.H2C {
+				scheme = "http, h2c"
+			}
+			log.Printf("Server is running on %s (%s) \n", l.name, scheme)
+			// when server startup failed, write error to errChan.
+			// But http.ErrServerClosed is not an error,,because it is expected when the server is closed.
+			// Serve is a blocking call
+			if err := l.serve(); err != nil && err != http.ErrServerClosed {
+				log.Printf("Server start failed on %s \n", l.name)
+				ReportError(errChan, err)
+			}
+		}(l)
+	}
+	if s.acme != nil {
+		// 监听已经开始服务，启动时立即申请证书的 TLS-ALPN-01 验证可以连接到本服务
+		s.acme.start(errChan)
+	}
+}
+
+// closeListeners 关闭已打开但还没有开始服务的监听，Start 失败时调用
+func (s *Server) closeListeners() {
+	for _, l := range s.listeners {
+		l.ln.Close()
+	}
+	if s.acme != nil && s.acme.httpListener != nil {
+		s.acme.httpListener.Close()
+	}
 }
 
 // Shutdown shutdown server
+// 顺序: readiness 返回失败并等待 HealthConfig.ShutdownDelay -> 通知 LongLived 登记的长连接处理器退出
+// -> 关闭监听，在 ctx 到期前等待处理中的请求和长连接处理器结束
 func (s *Server) Shutdown(ctx context.Context) error {
 	log.Printf("Server is shutting down on %s \n", s.config.Addr)
-	return s.Server.Shutdown(ctx)
+	s.shuttingDown.Store(true)
+	s.waitShutdownDelay(ctx)
+	if s.drain != nil {
+		s.drain.notify()
+	}
+	if s.certs != nil {
+		s.certs.Close()
+	}
+	if s.acme != nil {
+		if err := s.acme.shutdown(ctx); err != nil {
+			log.Printf("ACME challenge server shutdown failed: %v \n", err)
+		}
+	}
+	if s.http3 != nil {
+		if err := s.http3.Shutdown(ctx); err != nil {
+			log.Printf("HTTP/3 server shutdown failed: %v \n", err)
+		}
+	}
+	// 主监听由 s.Server 负责，其余监听各自关闭
+	for _, l := range s.listeners {
+		if l.server != s.Server {
+			if err := l.server.Shutdown(ctx); err != nil {
+				log.Printf("Server shutdown failed on %s: %v \n", l.name, err)
+			}
+		}
+	}
+	err := s.Server.Shutdown(ctx)
+	// 被 Hijack 的连接（WebSocket）不在 http.Server 的跟踪范围内，需要单独等待
+	if s.drain != nil {
+		if drainErr := s.drain.wait(ctx); drainErr != nil {
+			log.Printf("Server shutdown failed on %s: %v \n", s.config.Addr, drainErr)
+			if err == nil {
+				err = drainErr
+			}
+		}
+	}
+	return err
 }
 
 /*
@@ -277,7 +456,7 @@ func (s *Server) Shutdown(ctx context.Context) error {
 2. Go 运行时优化：
    - 设置 GOMAXPROCS 为 CPU 核心数
    - 调整 GC 参数：GOGC=100
-   - 使用 pprof 分析性能瓶颈
+   - 使用 pprof 分析性能瓶颈（通过 pkg/admin 的管理端口暴露）
 
 3. 应用层面优化：
    - 使用连接池管理数据库连接
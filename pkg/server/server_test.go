@@ -0,0 +1,55 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package server
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestStartReportsSetupErrorOnUnbufferedChannel(t *testing.T) {
+	busy, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer busy.Close()
+
+	srv := New(Config{Addr: busy.Addr().String()})
+	errChan := make(chan error)
+	started := make(chan struct{})
+	go func() {
+		srv.Start(errChan)
+		close(started)
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Start blocked writing the setup error to an unbuffered errChan")
+	}
+	select {
+	case err := <-errChan:
+		if err == nil {
+			t.Error("received a nil error")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("setup error was not reported")
+	}
+}
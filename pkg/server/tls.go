@@ -0,0 +1,380 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/crypto/acme"
+)
+
+// TLSConfig HTTPS 配置
+type TLSConfig struct {
+	// CertFile / KeyFile 证书和私钥文件（PEM 格式），证书文件可以包含中间证书
+	// 文件变化后自动重新加载，无需重启服务
+	CertFile string
+	KeyFile  string
+	// Certificates 内存中的证书，设置后忽略 CertFile / KeyFile
+	// 多个证书时按 SNI 自动选择
+	Certificates []tls.Certificate
+	// ReloadInterval 检查证书文件是否变化的周期，默认 1 分钟，<0 表示不自动重新加载
+	ReloadInterval time.Duration
+
+	// MinVersion 最低 TLS 版本，默认 tls.VersionTLS12
+	MinVersion uint16
+	// CipherSuites TLS 1.2 及以下使用的加密套件，为空使用 Go 的默认安全套件；TLS 1.3 的套件不可配置
+	// 不允许使用 tls.InsecureCipherSuites() 中的套件
+	CipherSuites []uint16
+	// CurvePreferences 密钥交换曲线，为空使用 Go 的默认值
+	CurvePreferences []tls.CurveID
+
+	// ClientAuth 客户端证书校验方式（mTLS），默认不要求客户端证书
+	// tls.RequireAndVerifyClientCert: 必须提供并通过校验；tls.VerifyClientCertIfGiven: 提供时校验
+	ClientAuth tls.ClientAuthType
+	// ClientCAFile 校验客户端证书的 CA 文件（PEM 格式，可以包含多个证书）
+	ClientCAFile string
+	// ClientCAs 校验客户端证书的 CA，与 ClientCAFile 合并使用
+	ClientCAs *x509.CertPool
+}
+
+// WithTLS 启用 HTTPS
+// 示例: WithTLS(&TLSConfig{CertFile: "server.crt", KeyFile: "server.key"})
+func WithTLS(config *TLSConfig) serverOption {
+	return func(s *Server) {
+		s.config.TLS = config
+	}
+}
+
+// ParseTLSVersion 解析 TLS 版本，支持 "1.0"、"1.1"、"1.2"、"1.3"，用于从配置文件读取
+func ParseTLSVersion(version string) (uint16, error) {
+	switch strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(version)), "TLS") {
+	case "1.0", "10":
+		return tls.VersionTLS10, nil
+	case "1.1", "11":
+		return tls.VersionTLS11, nil
+	case "1.2", "12":
+		return tls.VersionTLS12, nil
+	case "1.3", "13":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unknown TLS version %q", version)
+	}
+}
+
+// ParseCipherSuites 按名称解析加密套件，如 "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"，用于从配置文件读取
+func ParseCipherSuites(names []string) ([]uint16, error) {
+	suites := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		suites[suite.Name] = suite.ID
+	}
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		id, ok := suites[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown or insecure cipher suite %q", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// buildTLSConfig 根据配置创建 tls.Config，证书来自文件时返回证书加载器
+// getCertificate 不为 nil 时（如 ACME）使用它提供证书，忽略配置中的证书
+func buildTLSConfig(config *TLSConfig, getCertificate func(*tls.ClientHelloInfo) (*tls.Certificate, error)) (*tls.Config, *certReloader, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:       config.MinVersion,
+		CurvePreferences: config.CurvePreferences,
+		ClientAuth:       config.ClientAuth,
+	}
+	if tlsConfig.MinVersion == 0 {
+		tlsConfig.MinVersion = tls.VersionTLS12
+	}
+	if tlsConfig.MinVersion < tls.VersionTLS10 || tlsConfig.MinVersion > tls.VersionTLS13 {
+		return nil, nil, fmt.Errorf("invalid TLS min version 0x%04x", tlsConfig.MinVersion)
+	}
+
+	if len(config.CipherSuites) > 0 {
+		insecure := make(map[uint16]string)
+		for _, suite := range tls.InsecureCipherSuites() {
+			insecure[suite.ID] = suite.Name
+		}
+		for _, id := range config.CipherSuites {
+			if name, ok := insecure[id]; ok {
+				return nil, nil, fmt.Errorf("insecure cipher suite %s is not allowed", name)
+			}
+		}
+		tlsConfig.CipherSuites = config.CipherSuites
+	}
+
+	if config.ClientAuth != tls.NoClientCert {
+		pool := config.ClientCAs
+		if config.ClientCAFile != "" {
+			pem, err := os.ReadFile(config.ClientCAFile)
+			if err != nil {
+				return nil, nil, fmt.Errorf("read client CA file: %w", err)
+			}
+			if pool == nil {
+				pool = x509.NewCertPool()
+			} else {
+				pool = pool.Clone()
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, nil, fmt.Errorf("no certificates found in client CA file %s", config.ClientCAFile)
+			}
+		}
+		if pool == nil && config.ClientAuth >= tls.VerifyClientCertIfGiven {
+			return nil, nil, fmt.Errorf("client certificate verification requires ClientCAFile or ClientCAs")
+		}
+		tlsConfig.ClientCAs = pool
+	}
+
+	switch {
+	case getCertificate != nil:
+		tlsConfig.GetCertificate = getCertificate
+		return tlsConfig, nil, nil
+	case len(config.Certificates) > 0:
+		tlsConfig.Certificates = config.Certificates
+		return tlsConfig, nil, nil
+	case config.CertFile != "" && config.KeyFile != "":
+		reloader, err := newCertReloader(config.CertFile, config.KeyFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		tlsConfig.GetCertificate = reloader.GetCertificate
+		return tlsConfig, reloader, nil
+	default:
+		return nil, nil, fmt.Errorf("TLS requires Certificates or both CertFile and KeyFile")
+	}
+}
+
+// ------------------------------------------------------------ 证书热加载 ------------------------------------------------------------
+
+// certReloader 从文件加载证书，文件变化后重新加载
+// 使用轮询检查文件修改时间和大小，兼容 Kubernetes Secret 挂载（通过符号链接原子替换）等场景
+type certReloader struct {
+	certFile string
+	keyFile  string
+	cert     atomic.Pointer[tls.Certificate]
+
+	mu    sync.Mutex
+	stamp string
+	stop  chan struct{}
+	once  sync.Once
+}
+
+// newCertReloader 创建证书加载器并立即加载证书
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	c := &certReloader{certFile: certFile, keyFile: keyFile, stop: make(chan struct{})}
+	if _, err := c.reload(true); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// GetCertificate 实现 tls.Config.GetCertificate
+func (c *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return c.cert.Load(), nil
+}
+
+// reload 文件变化（或 force）时重新加载证书，返回是否加载了新证书
+// 加载失败时保留原有证书
+func (c *certReloader) reload(force bool) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stamp, err := fileStamp(c.certFile, c.keyFile)
+	if err != nil {
+		return false, err
+	}
+	if !force && stamp == c.stamp {
+		return false, nil
+	}
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return false, fmt.Errorf("load TLS certificate: %w", err)
+	}
+	if cert.Leaf == nil && len(cert.Certificate) > 0 {
+		cert.Leaf, _ = x509.ParseCertificate(cert.Certificate[0])
+	}
+	c.cert.Store(&cert)
+	c.stamp = stamp
+	return true, nil
+}
+
+// watch 定期检查证书文件
+func (c *certReloader) watch(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			reloaded, err := c.reload(false)
+			if err != nil {
+				log.Printf("[TLS] reload certificate failed, keep using the old one: %v", err)
+			} else if reloaded {
+				log.Printf("[TLS] certificate reloaded from %s", c.certFile)
+			}
+		}
+	}
+}
+
+// Close 停止检查
+func (c *certReloader) Close() {
+	c.once.Do(func() { close(c.stop) })
+}
+
+// fileStamp 文件修改时间和大小，用于判断文件是否变化
+func fileStamp(files ...string) (string, error) {
+	var b strings.Builder
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return "", fmt.Errorf("stat %s: %w", file, err)
+		}
+		fmt.Fprintf(&b, "%d:%d;", info.ModTime().UnixNano(), info.Size())
+	}
+	return b.String(), nil
+}
+
+// ReloadCertificates 立即从文件重新加载证书，如收到 SIGHUP 时调用
+// 证书来自内存或未启用 HTTPS 时返回错误
+func (s *Server) ReloadCertificates() error {
+	if s.certs == nil {
+		return errors.New("server has no file based TLS certificate")
+	}
+	_, err := s.certs.reload(true)
+	return err
+}
+
+// setupTLS 根据 TLS / ACME 配置初始化 s.TLSConfig，未启用 HTTPS 时不做任何事
+// ACME 的证书检查在监听开始服务后由 Start 启动，否则 TLS-ALPN-01 验证连接不上
+func (s *Server) setupTLS() error {
+	if s.config.TLS == nil && s.config.ACME == nil {
+		return nil
+	}
+	config := s.config.TLS
+	if config == nil {
+		config = &TLSConfig{}
+	}
+
+	var getCertificate func(*tls.ClientHelloInfo) (*tls.Certificate, error)
+	if s.config.ACME != nil {
+		m, err := newACMEManager(s.config.ACME)
+		if err != nil {
+			return err
+		}
+		s.acme = m
+		getCertificate = m.GetCertificate
+	}
+
+	tlsConfig, certs, err := buildTLSConfig(config, getCertificate)
+	if err != nil {
+		return err
+	}
+	if s.acme != nil {
+		// TLS-ALPN-01 验证通过 ALPN 协议 acme-tls/1 完成
+		tlsConfig.NextProtos = []string{"h2", "http/1.1", acme.ALPNProto}
+	}
+	s.TLSConfig = tlsConfig
+	s.certs = certs
+	s.Handler = clientIdentityHandler(s.Handler)
+	if certs != nil && config.ReloadInterval >= 0 {
+		interval := config.ReloadInterval
+		if interval == 0 {
+			interval = time.Minute
+		}
+		go certs.watch(interval)
+	}
+	return nil
+}
+
+// ------------------------------------------------------------ 客户端证书身份 ------------------------------------------------------------
+
+// ClientIdentity 通过校验的客户端证书（mTLS）身份
+type ClientIdentity struct {
+	Subject        string   // 证书主题，如 "CN=order-service,O=Example"
+	CommonName     string   // 主题中的 CN
+	DNSNames       []string // SAN 中的 DNS 名称
+	EmailAddresses []string // SAN 中的邮箱
+	URIs           []string // SAN 中的 URI，如 SPIFFE ID "spiffe://example.org/ns/default/sa/order"
+	SerialNumber   string   // 十六进制序列号
+	Fingerprint    string   // 证书 DER 的 SHA-256 指纹（十六进制）
+	NotAfter       time.Time
+	Certificate    *x509.Certificate
+}
+
+// clientIdentityKey 请求上下文中保存客户端身份的键
+type clientIdentityKey struct{}
+
+// ClientIdentityFromContext 从上下文获取客户端证书身份
+func ClientIdentityFromContext(ctx context.Context) (*ClientIdentity, bool) {
+	id, ok := ctx.Value(clientIdentityKey{}).(*ClientIdentity)
+	return id, ok && id != nil
+}
+
+// GetClientIdentity 获取请求的客户端证书身份，只有证书通过 CA 校验时才存在
+func GetClientIdentity(r *http.Request) (*ClientIdentity, bool) {
+	return ClientIdentityFromContext(r.Context())
+}
+
+// newClientIdentity 从证书提取身份信息
+func newClientIdentity(cert *x509.Certificate) *ClientIdentity {
+	fingerprint := sha256.Sum256(cert.Raw)
+	id := &ClientIdentity{
+		Subject:        cert.Subject.String(),
+		CommonName:     cert.Subject.CommonName,
+		DNSNames:       cert.DNSNames,
+		EmailAddresses: cert.EmailAddresses,
+		SerialNumber:   cert.SerialNumber.Text(16),
+		Fingerprint:    hex.EncodeToString(fingerprint[:]),
+		NotAfter:       cert.NotAfter,
+		Certificate:    cert,
+	}
+	for _, uri := range cert.URIs {
+		id.URIs = append(id.URIs, uri.String())
+	}
+	return id
+}
+
+// clientIdentityHandler 将通过校验的客户端证书身份写入请求上下文
+// 只使用 VerifiedChains，未经校验的证书（tls.RequestClientCert 等）不会被当作身份
+func clientIdentityHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
+			id := newClientIdentity(r.TLS.VerifiedChains[0][0])
+			r = r.WithContext(context.WithValue(r.Context(), clientIdentityKey{}, id))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package session
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+var errInvalidCookie = errors.New("invalid session cookie")
+
+// codec Cookie 值的签名和加密
+// 签名: base64url(payload).base64url(HMAC-SHA256(signKey, name|payload))
+// 加密: base64url(nonce|AES-256-GCM(encKey, payload, aad=name))
+// 签名密钥和加密密钥由配置的 secret 派生，互不相同
+type codec struct {
+	name string
+	keys []codecKey
+}
+
+type codecKey struct {
+	sign []byte
+	aead cipher.AEAD
+}
+
+func newCodec(name string, secrets [][]byte) *codec {
+	c := &codec{name: name}
+	for _, secret := range secrets {
+		block, _ := aes.NewCipher(deriveKey(secret, "taurus-session-encrypt"))
+		aead, _ := cipher.NewGCM(block)
+		c.keys = append(c.keys, codecKey{sign: deriveKey(secret, "taurus-session-sign"), aead: aead})
+	}
+	return c
+}
+
+// deriveKey 从 secret 派生 32 字节密钥
+func deriveKey(secret []byte, purpose string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
+
+// encode 使用第一个密钥签名或加密
+func (c *codec) encode(payload []byte, encrypt bool) string {
+	key := c.keys[0]
+	if encrypt {
+		nonce := make([]byte, key.aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			panic("session: failed to read random bytes: " + err.Error())
+		}
+		return base64.RawURLEncoding.EncodeToString(key.aead.Seal(nonce, nonce, payload, []byte(c.name)))
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(c.sign(key.sign, encoded))
+}
+
+// decode 依次尝试所有密钥验证签名或解密
+func (c *codec) decode(value string, encrypted bool) ([]byte, error) {
+	if encrypted {
+		data, err := base64.RawURLEncoding.DecodeString(value)
+		if err != nil {
+			return nil, errInvalidCookie
+		}
+		for _, key := range c.keys {
+			size := key.aead.NonceSize()
+			if len(data) < size {
+				return nil, errInvalidCookie
+			}
+			if payload, err := key.aead.Open(nil, data[:size], data[size:], []byte(c.name)); err == nil {
+				return payload, nil
+			}
+		}
+		return nil, errInvalidCookie
+	}
+
+	encoded, signature, ok := strings.Cut(value, ".")
+	if !ok {
+		return nil, errInvalidCookie
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil {
+		return nil, errInvalidCookie
+	}
+	for _, key := range c.keys {
+		if hmac.Equal(sig, c.sign(key.sign, encoded)) {
+			return base64.RawURLEncoding.DecodeString(encoded)
+		}
+	}
+	return nil, errInvalidCookie
+}
+
+// sign 计算签名，Cookie 名称参与签名，防止不同 Cookie 之间互相替换
+func (c *codec) sign(key []byte, encoded string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(c.name))
+	mac.Write([]byte{'|'})
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
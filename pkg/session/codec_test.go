@@ -0,0 +1,108 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package session
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+var (
+	oldSecret = bytes.Repeat([]byte("o"), 32)
+	newSecret = bytes.Repeat([]byte("n"), 32)
+)
+
+func TestCodecRoundTrip(t *testing.T) {
+	payload := []byte(`{"user":"alice"}`)
+	for _, encrypt := range []bool{false, true} {
+		c := newCodec("sid", [][]byte{newSecret})
+		value := c.encode(payload, encrypt)
+
+		got, err := c.decode(value, encrypt)
+		if err != nil || !bytes.Equal(got, payload) {
+			t.Errorf("encrypt=%v: decode = %q, %v", encrypt, got, err)
+		}
+		// 加密后的 Cookie 不包含明文，签名的 Cookie 只做编码
+		if encrypted := !strings.Contains(value, "."); encrypted != encrypt {
+			t.Errorf("encrypt=%v: unexpected cookie format %q", encrypt, value)
+		}
+		// 不同 Cookie 名称之间不能互相替换
+		if _, err := newCodec("other", [][]byte{newSecret}).decode(value, encrypt); !errors.Is(err, errInvalidCookie) {
+			t.Errorf("encrypt=%v: cookie accepted under another name: %v", encrypt, err)
+		}
+	}
+}
+
+func TestCodecRejectsTamperedCookie(t *testing.T) {
+	c := newCodec("sid", [][]byte{newSecret})
+	for _, encrypt := range []bool{false, true} {
+		value := c.encode([]byte(`{"admin":false}`), encrypt)
+		tampered := []string{
+			"",
+			"not-base64!",
+			value[:len(value)-2],
+			flipChar(value, 0),
+			flipChar(value, len(value)/2),
+		}
+		if !encrypt {
+			// 替换数据部分但保留签名
+			_, sig, _ := strings.Cut(value, ".")
+			tampered = append(tampered, base64.RawURLEncoding.EncodeToString([]byte(`{"admin":true}`))+"."+sig)
+		}
+		for _, v := range tampered {
+			if _, err := c.decode(v, encrypt); !errors.Is(err, errInvalidCookie) {
+				t.Errorf("encrypt=%v: decode(%q) err = %v, want errInvalidCookie", encrypt, v, err)
+			}
+		}
+	}
+}
+
+func TestCodecSecretRotation(t *testing.T) {
+	payload := []byte("payload")
+	for _, encrypt := range []bool{false, true} {
+		issued := newCodec("sid", [][]byte{oldSecret}).encode(payload, encrypt)
+
+		// 新密钥在前，旧密钥仍可验证已下发的 Cookie
+		rotated := newCodec("sid", [][]byte{newSecret, oldSecret})
+		if got, err := rotated.decode(issued, encrypt); err != nil || !bytes.Equal(got, payload) {
+			t.Errorf("encrypt=%v: rotated codec decode = %q, %v", encrypt, got, err)
+		}
+		// 新下发的 Cookie 使用新密钥
+		if _, err := newCodec("sid", [][]byte{newSecret}).decode(rotated.encode(payload, encrypt), encrypt); err != nil {
+			t.Errorf("encrypt=%v: cookie from rotated codec not signed with the new secret: %v", encrypt, err)
+		}
+		// 移除旧密钥后旧 Cookie 失效
+		if _, err := newCodec("sid", [][]byte{newSecret}).decode(issued, encrypt); !errors.Is(err, errInvalidCookie) {
+			t.Errorf("encrypt=%v: cookie accepted after the old secret was removed: %v", encrypt, err)
+		}
+	}
+}
+
+// flipChar 替换 s 中第 i 个字符，结果仍是合法的 base64url 字符
+// 不要替换最后一个字符，其低位可能只是填充，修改后解码结果不变
+func flipChar(s string, i int) string {
+	c := byte('A')
+	if s[i] == 'A' {
+		c = 'B'
+	}
+	return s[:i] + string(c) + s[i+1:]
+}
@@ -0,0 +1,291 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package session
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// maxCookieSize 单个 Cookie 的最大长度，超过后浏览器会丢弃
+const maxCookieSize = 4096
+
+// touchInterval 会话未修改时，距离上次访问超过该时间才重新保存，以延长空闲过期时间
+const touchInterval = time.Minute
+
+// Config 会话配置
+type Config struct {
+	// Store 服务端存储，为 nil 时会话数据保存在 Cookie 中（Cookie 会话，最大约 4KB）
+	Store Store
+	// Secrets 签名/加密密钥，必填，每个至少 32 字节
+	// 第一个用于签名和加密，其余的只用于验证，轮换密钥时把新密钥放在最前面
+	Secrets [][]byte
+	// Encrypt Cookie 会话是否加密，默认只签名（客户端可以读取但无法篡改）
+	// 使用服务端存储时 Cookie 中只有会话 ID，总是只签名
+	Encrypt bool
+
+	// CookieName Cookie 名称，默认 "taurus_session"
+	CookieName string
+	// CookiePath Cookie 路径，默认 "/"
+	CookiePath string
+	// CookieDomain Cookie 域名，默认为当前域名
+	CookieDomain string
+	// SameSite 默认 http.SameSiteLaxMode
+	SameSite http.SameSite
+	// AllowInsecureCookie 不设置 Secure 属性，仅用于本地 HTTP 开发环境
+	AllowInsecureCookie bool
+
+	// IdleTimeout 空闲过期时间，超过该时间没有访问则会话失效，默认 30 分钟
+	IdleTimeout time.Duration
+	// AbsoluteTimeout 绝对过期时间，从创建（或 RenewID）开始计算，默认 24 小时
+	AbsoluteTimeout time.Duration
+}
+
+// Manager 会话管理器
+type Manager struct {
+	config Config
+	codec  *codec
+}
+
+// New 创建会话管理器
+func New(config Config) (*Manager, error) {
+	if len(config.Secrets) == 0 {
+		return nil, fmt.Errorf("session secrets are required")
+	}
+	for _, secret := range config.Secrets {
+		if len(secret) < 32 {
+			return nil, fmt.Errorf("session secret must be at least 32 bytes")
+		}
+	}
+	if config.CookieName == "" {
+		config.CookieName = "taurus_session"
+	}
+	if config.CookiePath == "" {
+		config.CookiePath = "/"
+	}
+	if config.SameSite == 0 {
+		config.SameSite = http.SameSiteLaxMode
+	}
+	if config.IdleTimeout <= 0 {
+		config.IdleTimeout = 30 * time.Minute
+	}
+	if config.AbsoluteTimeout <= 0 {
+		config.AbsoluteTimeout = 24 * time.Hour
+	}
+	return &Manager{config: config, codec: newCodec(config.CookieName, config.Secrets)}, nil
+}
+
+// Middleware 返回会话中间件，处理器通过 session.Get(r) 获取会话
+// 会话在响应头写出前保存，处理器写响应之后对会话的修改不会生效
+func (m *Manager) Middleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			s, hadCookie := m.load(r)
+			sw := &sessionWriter{ResponseWriter: w}
+			sw.commit = func() { m.save(r.Context(), w, s, hadCookie) }
+
+			next.ServeHTTP(sw, r.WithContext(WithSession(r.Context(), s)))
+			sw.beforeWrite()
+		})
+	}
+}
+
+// load 从 Cookie 中加载会话，Cookie 不存在、无效或会话过期时创建新会话
+func (m *Manager) load(r *http.Request) (*Session, bool) {
+	cookie, err := r.Cookie(m.config.CookieName)
+	if err != nil || cookie.Value == "" {
+		return newSession(), false
+	}
+
+	payload, err := m.codec.decode(cookie.Value, m.config.Encrypt && m.config.Store == nil)
+	if err != nil {
+		return newSession(), true
+	}
+
+	var (
+		id  string
+		rec record
+	)
+	if m.config.Store != nil {
+		id = string(payload)
+		if !validID(id) {
+			return newSession(), true
+		}
+		data, err := m.config.Store.Load(r.Context(), id)
+		if err != nil {
+			if !errors.Is(err, ErrNotFound) {
+				log.Printf("[Session] load session failed: %v", err)
+			}
+			return newSession(), true
+		}
+		payload = data
+	}
+	if err := json.Unmarshal(payload, &rec); err != nil {
+		return newSession(), true
+	}
+	if m.config.Store == nil {
+		id = rec.ID
+	}
+
+	s := fromRecord(id, &rec)
+	now := time.Now()
+	if now.Sub(s.accessAt) > m.config.IdleTimeout || now.Sub(s.createdAt) > m.config.AbsoluteTimeout {
+		if m.config.Store != nil {
+			m.config.Store.Delete(r.Context(), id)
+		}
+		return newSession(), true
+	}
+	return s, true
+}
+
+// save 保存会话并写入 Cookie
+func (m *Manager) save(ctx context.Context, w http.ResponseWriter, s *Session, hadCookie bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.destroyed {
+		if m.config.Store != nil {
+			for _, id := range []string{s.oldID, s.id} {
+				if id != "" {
+					if err := m.config.Store.Delete(ctx, id); err != nil {
+						log.Printf("[Session] delete session failed: %v", err)
+					}
+				}
+			}
+		}
+		if hadCookie {
+			m.setCookie(w, "", -1)
+		}
+		return
+	}
+
+	now := time.Now()
+	if s.isNew && len(s.values) == 0 && len(s.flashes) == 0 {
+		// 没有数据的新会话不下发 Cookie，避免为每个匿名访问创建会话
+		return
+	}
+	if !s.modified && now.Sub(s.accessAt) < touchInterval {
+		return
+	}
+	s.accessAt = now
+
+	ttl := min(m.config.IdleTimeout, s.createdAt.Add(m.config.AbsoluteTimeout).Sub(now))
+	if ttl <= 0 {
+		return
+	}
+	rec := s.toRecord()
+
+	var value string
+	if m.config.Store != nil {
+		if s.oldID != "" {
+			if err := m.config.Store.Delete(ctx, s.oldID); err != nil {
+				log.Printf("[Session] delete old session failed: %v", err)
+			}
+			s.oldID = ""
+		}
+		data, err := json.Marshal(rec)
+		if err != nil {
+			log.Printf("[Session] encode session failed: %v", err)
+			return
+		}
+		if err := m.config.Store.Save(ctx, s.id, data, ttl); err != nil {
+			log.Printf("[Session] save session failed: %v", err)
+			return
+		}
+		value = m.codec.encode([]byte(s.id), false)
+	} else {
+		rec.ID = s.id
+		data, err := json.Marshal(rec)
+		if err != nil {
+			log.Printf("[Session] encode session failed: %v", err)
+			return
+		}
+		value = m.codec.encode(data, m.config.Encrypt)
+		if len(value) > maxCookieSize {
+			log.Printf("[Session] cookie session exceeds %d bytes, use a server-side store instead", maxCookieSize)
+			return
+		}
+	}
+	s.modified = false
+	m.setCookie(w, value, int(ttl.Seconds()))
+}
+
+// setCookie 写入会话 Cookie，maxAge < 0 表示删除
+func (m *Manager) setCookie(w http.ResponseWriter, value string, maxAge int) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     m.config.CookieName,
+		Value:    value,
+		Path:     m.config.CookiePath,
+		Domain:   m.config.CookieDomain,
+		MaxAge:   maxAge,
+		Secure:   !m.config.AllowInsecureCookie,
+		HttpOnly: true,
+		SameSite: m.config.SameSite,
+	})
+}
+
+// sessionWriter 在响应头写出前保存会话
+type sessionWriter struct {
+	http.ResponseWriter
+	commit func()
+	once   sync.Once
+}
+
+func (w *sessionWriter) beforeWrite() {
+	w.once.Do(w.commit)
+}
+
+// WriteHeader 保存会话后写入状态码
+func (w *sessionWriter) WriteHeader(code int) {
+	w.beforeWrite()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write 保存会话后写入响应体
+func (w *sessionWriter) Write(b []byte) (int, error) {
+	w.beforeWrite()
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush 实现 http.Flusher
+func (w *sessionWriter) Flush() {
+	w.beforeWrite()
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack 实现 http.Hijacker，WebSocket 升级前保存会话
+func (w *sessionWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.beforeWrite()
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+// Unwrap 返回原始的 ResponseWriter，供 http.ResponseController 使用
+func (w *sessionWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
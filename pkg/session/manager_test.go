@@ -0,0 +1,248 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+// testManager 创建会话管理器，store 为 nil 时使用 Cookie 会话
+func testManager(t *testing.T, store Store, encrypt bool) *Manager {
+	t.Helper()
+	m, err := New(Config{Store: store, Secrets: [][]byte{newSecret}, Encrypt: encrypt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// testModes 需要测试的会话模式
+func testModes(t *testing.T) map[string]*Manager {
+	t.Helper()
+	store := NewMemoryStore(0)
+	t.Cleanup(store.Close)
+	return map[string]*Manager{
+		"signed cookie":    testManager(t, nil, false),
+		"encrypted cookie": testManager(t, nil, true),
+		"memory store":     testManager(t, store, false),
+	}
+}
+
+// client 带 Cookie 的测试客户端，保存服务端下发的会话 Cookie
+type client struct {
+	t       *testing.T
+	manager *Manager
+	cookie  *http.Cookie
+}
+
+// do 使用当前 Cookie 发送请求，handler 在会话中间件内执行
+func (c *client) do(handler func(s *Session)) *httptest.ResponseRecorder {
+	c.t.Helper()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if c.cookie != nil {
+		r.AddCookie(c.cookie)
+	}
+	rec := httptest.NewRecorder()
+	c.manager.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler(Get(r))
+	})).ServeHTTP(rec, r)
+
+	for _, cookie := range rec.Result().Cookies() {
+		if cookie.Name == c.manager.config.CookieName {
+			if cookie.MaxAge < 0 {
+				c.cookie = nil
+			} else {
+				c.cookie = cookie
+			}
+		}
+	}
+	return rec
+}
+
+func TestManagerRoundTrip(t *testing.T) {
+	for name, m := range testModes(t) {
+		t.Run(name, func(t *testing.T) {
+			c := &client{t: t, manager: m}
+
+			// 没有数据的新会话不下发 Cookie
+			c.do(func(s *Session) {})
+			if c.cookie != nil {
+				t.Fatal("empty session set a cookie")
+			}
+
+			var id string
+			c.do(func(s *Session) {
+				id = s.ID()
+				s.Set("user", "alice")
+				s.Set("count", 1)
+			})
+			if c.cookie == nil || !c.cookie.HttpOnly || !c.cookie.Secure {
+				t.Fatalf("session cookie = %+v, want a Secure HttpOnly cookie", c.cookie)
+			}
+
+			c.do(func(s *Session) {
+				if s.IsNew() || s.ID() != id {
+					t.Errorf("session id = %s (new=%v), want existing %s", s.ID(), s.IsNew(), id)
+				}
+				if s.GetString("user") != "alice" || s.GetInt("count") != 1 {
+					t.Errorf("values = %q, %d", s.GetString("user"), s.GetInt("count"))
+				}
+				s.Destroy()
+			})
+			if c.cookie != nil {
+				t.Error("Destroy did not delete the cookie")
+			}
+		})
+	}
+}
+
+func TestManagerRejectsTamperedCookie(t *testing.T) {
+	for name, m := range testModes(t) {
+		t.Run(name, func(t *testing.T) {
+			c := &client{t: t, manager: m}
+			c.do(func(s *Session) { s.Set("user", "alice") })
+			c.cookie.Value = flipChar(c.cookie.Value, 0)
+
+			c.do(func(s *Session) {
+				if !s.IsNew() || s.Get("user") != nil {
+					t.Errorf("tampered cookie loaded session with user %v", s.Get("user"))
+				}
+			})
+		})
+	}
+}
+
+func TestManagerRenewID(t *testing.T) {
+	for name, m := range testModes(t) {
+		t.Run(name, func(t *testing.T) {
+			c := &client{t: t, manager: m}
+			var oldID, newID string
+			c.do(func(s *Session) {
+				oldID = s.ID()
+				s.Set("cart", "book")
+			})
+			oldCookie := c.cookie
+
+			// 登录后更换 ID
+			c.do(func(s *Session) {
+				s.RenewID()
+				s.Set("user", "alice")
+				newID = s.ID()
+			})
+			if newID == oldID {
+				t.Fatal("RenewID kept the session id")
+			}
+			c.do(func(s *Session) {
+				if s.ID() != newID || s.GetString("cart") != "book" || s.GetString("user") != "alice" {
+					t.Errorf("after RenewID: id = %s, cart = %q, user = %q", s.ID(), s.GetString("cart"), s.GetString("user"))
+				}
+			})
+
+			// 服务端存储中的旧会话被删除，攻击者持有的旧 Cookie 失效
+			if m.config.Store != nil {
+				if _, err := m.config.Store.Load(context.Background(), oldID); !errors.Is(err, ErrNotFound) {
+					t.Errorf("old session still in store: %v", err)
+				}
+				old := &client{t: t, manager: m, cookie: oldCookie}
+				old.do(func(s *Session) {
+					if !s.IsNew() {
+						t.Error("old cookie still loads the session after RenewID")
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestManagerFlashes(t *testing.T) {
+	for name, m := range testModes(t) {
+		t.Run(name, func(t *testing.T) {
+			c := &client{t: t, manager: m}
+			c.do(func(s *Session) { s.AddFlash("saved") })
+
+			c.do(func(s *Session) {
+				if got := s.Flashes(); !slices.Equal(got, []any{"saved"}) {
+					t.Errorf("Flashes = %v, want [saved]", got)
+				}
+				// 同一请求内再次读取为空
+				if got := s.Flashes(); len(got) != 0 {
+					t.Errorf("second Flashes = %v, want none", got)
+				}
+			})
+			c.do(func(s *Session) {
+				if got := s.Flashes(); len(got) != 0 {
+					t.Errorf("Flashes on the next request = %v, want none", got)
+				}
+			})
+		})
+	}
+}
+
+func TestManagerExpiry(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		created  time.Time
+		accessed time.Time
+		expired  bool
+	}{
+		{"active", now.Add(-time.Hour), now.Add(-time.Minute), false},
+		{"idle timeout", now.Add(-time.Hour), now.Add(-31 * time.Minute), true},
+		// 一直活跃的会话在绝对过期时间后也会失效
+		{"absolute timeout", now.Add(-25 * time.Hour), now.Add(-time.Minute), true},
+	}
+	for name, m := range testModes(t) {
+		for _, tt := range tests {
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				id := newID()
+				rec := record{Values: map[string]any{"user": "alice"}, CreatedAt: tt.created.Unix(), AccessAt: tt.accessed.Unix()}
+				c := &client{t: t, manager: m, cookie: &http.Cookie{Name: m.config.CookieName}}
+				if m.config.Store != nil {
+					data, _ := json.Marshal(rec)
+					if err := m.config.Store.Save(context.Background(), id, data, time.Hour); err != nil {
+						t.Fatal(err)
+					}
+					c.cookie.Value = m.codec.encode([]byte(id), false)
+				} else {
+					rec.ID = id
+					data, _ := json.Marshal(rec)
+					c.cookie.Value = m.codec.encode(data, m.config.Encrypt)
+				}
+
+				c.do(func(s *Session) {
+					if loaded := s.ID() == id && s.GetString("user") == "alice"; loaded == tt.expired {
+						t.Errorf("session loaded = %v, want %v", loaded, !tt.expired)
+					}
+				})
+				if tt.expired && m.config.Store != nil {
+					if _, err := m.config.Store.Load(context.Background(), id); !errors.Is(err, ErrNotFound) {
+						t.Errorf("expired session still in store: %v", err)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,258 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+// Package session 提供基于 Cookie 的会话管理
+// 会话数据可以保存在签名/加密的 Cookie 中，也可以保存在服务端存储（内存、文件、Redis）中
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// contextKey 请求上下文中使用的键类型，避免与其他包冲突
+type contextKey int
+
+const sessionKey contextKey = iota
+
+// record 会话的持久化格式
+type record struct {
+	ID        string         `json:"id,omitempty"` // 仅 Cookie 会话使用
+	Values    map[string]any `json:"v,omitempty"`
+	Flashes   []any          `json:"f,omitempty"`
+	CreatedAt int64          `json:"c"`
+	AccessAt  int64          `json:"a"`
+}
+
+// Session 一次请求中的会话
+// 值经过 JSON 序列化保存，读取时数字为 float64、对象为 map[string]any，可以使用 GetString/GetInt 等方法
+type Session struct {
+	mu        sync.Mutex
+	id        string
+	oldID     string // RenewID 之前的 ID，保存时从服务端存储中删除
+	values    map[string]any
+	flashes   []any
+	createdAt time.Time
+	accessAt  time.Time
+	isNew     bool
+	modified  bool
+	destroyed bool
+}
+
+// newSession 创建新会话
+func newSession() *Session {
+	now := time.Now()
+	return &Session{
+		id:        newID(),
+		values:    make(map[string]any),
+		createdAt: now,
+		accessAt:  now,
+		isNew:     true,
+		modified:  true,
+	}
+}
+
+// fromRecord 从持久化数据恢复会话
+func fromRecord(id string, rec *record) *Session {
+	if rec.Values == nil {
+		rec.Values = make(map[string]any)
+	}
+	return &Session{
+		id:        id,
+		values:    rec.Values,
+		flashes:   rec.Flashes,
+		createdAt: time.Unix(rec.CreatedAt, 0),
+		accessAt:  time.Unix(rec.AccessAt, 0),
+	}
+}
+
+// toRecord 转换为持久化数据
+func (s *Session) toRecord() *record {
+	return &record{
+		Values:    s.values,
+		Flashes:   s.flashes,
+		CreatedAt: s.createdAt.Unix(),
+		AccessAt:  s.accessAt.Unix(),
+	}
+}
+
+// ID 会话 ID
+func (s *Session) ID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.id
+}
+
+// IsNew 是否为本次请求新建的会话
+func (s *Session) IsNew() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.isNew
+}
+
+// CreatedAt 会话创建时间
+func (s *Session) CreatedAt() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.createdAt
+}
+
+// Get 获取值
+func (s *Session) Get(key string) any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.values[key]
+}
+
+// GetString 获取字符串值
+func (s *Session) GetString(key string) string {
+	v, _ := s.Get(key).(string)
+	return v
+}
+
+// GetInt 获取整数值
+func (s *Session) GetInt(key string) int {
+	switch v := s.Get(key).(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	default:
+		return 0
+	}
+}
+
+// GetBool 获取布尔值
+func (s *Session) GetBool(key string) bool {
+	v, _ := s.Get(key).(bool)
+	return v
+}
+
+// Set 设置值，值必须可以被 JSON 序列化
+func (s *Session) Set(key string, value any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = value
+	s.modified = true
+}
+
+// Delete 删除值
+func (s *Session) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.values, key)
+	s.modified = true
+}
+
+// Clear 清空所有值
+func (s *Session) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values = make(map[string]any)
+	s.flashes = nil
+	s.modified = true
+}
+
+// AddFlash 添加一次性消息，在下一次调用 Flashes 时读取并清除
+func (s *Session) AddFlash(value any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.flashes = append(s.flashes, value)
+	s.modified = true
+}
+
+// Flashes 读取并清除一次性消息
+func (s *Session) Flashes() []any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	flashes := s.flashes
+	if len(flashes) > 0 {
+		s.flashes = nil
+		s.modified = true
+	}
+	return flashes
+}
+
+// RenewID 更换会话 ID 并保留数据，登录、提权等操作后必须调用，防止会话固定攻击
+// 同时重置会话创建时间，绝对过期时间从现在重新计算
+func (s *Session) RenewID() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.oldID == "" && !s.isNew {
+		s.oldID = s.id
+	}
+	s.id = newID()
+	s.createdAt = time.Now()
+	s.modified = true
+}
+
+// Destroy 销毁会话，如退出登录；响应中会删除 Cookie
+func (s *Session) Destroy() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.destroyed = true
+	s.values = make(map[string]any)
+	s.flashes = nil
+	s.modified = true
+}
+
+// WithSession 将会话写入上下文
+func WithSession(ctx context.Context, s *Session) context.Context {
+	return context.WithValue(ctx, sessionKey, s)
+}
+
+// FromContext 从上下文获取会话
+func FromContext(ctx context.Context) (*Session, bool) {
+	s, ok := ctx.Value(sessionKey).(*Session)
+	return s, ok && s != nil
+}
+
+// Get 获取请求的会话，未挂载会话中间件时返回 nil
+func Get(r *http.Request) *Session {
+	s, _ := FromContext(r.Context())
+	return s
+}
+
+// newID 生成 256 位随机会话 ID
+func newID() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic("session: failed to read random bytes: " + err.Error())
+	}
+	return hex.EncodeToString(b)
+}
+
+// validID 会话 ID 是否合法，避免非法 ID 被用作文件名或 Redis key
+func validID(id string) bool {
+	if len(id) != 64 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
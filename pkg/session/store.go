@@ -0,0 +1,298 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrNotFound 会话不存在或已过期
+var ErrNotFound = errors.New("session not found")
+
+// Store 服务端会话存储
+// 数据为序列化后的会话，ttl 为存储的过期时间，过期的会话由存储自行清理
+type Store interface {
+	Load(ctx context.Context, id string) ([]byte, error)
+	Save(ctx context.Context, id string, data []byte, ttl time.Duration) error
+	Delete(ctx context.Context, id string) error
+}
+
+// ------------------------------------------------------------ 内存存储 ------------------------------------------------------------
+
+// MemoryStore 基于内存的会话存储，适合单机部署和开发环境，重启后会话丢失
+type MemoryStore struct {
+	mu       sync.RWMutex
+	sessions map[string]memoryEntry
+	stop     chan struct{}
+	once     sync.Once
+}
+
+type memoryEntry struct {
+	data    []byte
+	expires time.Time
+}
+
+// NewMemoryStore 创建内存会话存储
+// cleanupInterval 为过期会话清理周期，<=0 时默认 1 分钟
+func NewMemoryStore(cleanupInterval time.Duration) *MemoryStore {
+	if cleanupInterval <= 0 {
+		cleanupInterval = time.Minute
+	}
+	s := &MemoryStore{
+		sessions: make(map[string]memoryEntry),
+		stop:     make(chan struct{}),
+	}
+	go s.cleanup(cleanupInterval)
+	return s
+}
+
+// Load 实现 Store
+func (s *MemoryStore) Load(_ context.Context, id string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	entry, ok := s.sessions[id]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, ErrNotFound
+	}
+	return entry.data, nil
+}
+
+// Save 实现 Store
+func (s *MemoryStore) Save(_ context.Context, id string, data []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[id] = memoryEntry{data: data, expires: time.Now().Add(ttl)}
+	return nil
+}
+
+// Delete 实现 Store
+func (s *MemoryStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, id)
+	return nil
+}
+
+// cleanup 定期清理过期会话
+func (s *MemoryStore) cleanup(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case now := <-ticker.C:
+			s.mu.Lock()
+			for id, entry := range s.sessions {
+				if now.After(entry.expires) {
+					delete(s.sessions, id)
+				}
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// Close 停止清理协程
+func (s *MemoryStore) Close() {
+	s.once.Do(func() { close(s.stop) })
+}
+
+// ------------------------------------------------------------ 文件存储 ------------------------------------------------------------
+
+// FileStore 基于本地文件的会话存储，每个会话一个文件，适合单机部署
+// 文件第一行为过期时间（Unix 秒），之后为会话数据
+type FileStore struct {
+	dir  string
+	stop chan struct{}
+	once sync.Once
+}
+
+// NewFileStore 创建文件会话存储，目录不存在时自动创建
+// cleanupInterval 为过期会话清理周期，<=0 时默认 10 分钟
+func NewFileStore(dir string, cleanupInterval time.Duration) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create session dir: %w", err)
+	}
+	if cleanupInterval <= 0 {
+		cleanupInterval = 10 * time.Minute
+	}
+	s := &FileStore{dir: dir, stop: make(chan struct{})}
+	go s.cleanup(cleanupInterval)
+	return s, nil
+}
+
+// path 会话文件路径，调用方需先通过 validID 校验，避免路径穿越
+func (s *FileStore) path(id string) string {
+	return filepath.Join(s.dir, "sess_"+id)
+}
+
+// Load 实现 Store
+func (s *FileStore) Load(_ context.Context, id string) ([]byte, error) {
+	if !validID(id) {
+		return nil, ErrNotFound
+	}
+	data, err := os.ReadFile(s.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	expires, payload, ok := splitFileEntry(data)
+	if !ok || time.Now().After(expires) {
+		return nil, ErrNotFound
+	}
+	return payload, nil
+}
+
+// Save 实现 Store，先写临时文件再重命名，避免读到写了一半的文件
+func (s *FileStore) Save(_ context.Context, id string, data []byte, ttl time.Duration) error {
+	if !validID(id) {
+		return fmt.Errorf("invalid session id")
+	}
+	tmp, err := os.CreateTemp(s.dir, "tmp_")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	expires := time.Now().Add(ttl).Unix()
+	if _, err := fmt.Fprintf(tmp, "%d\n", expires); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path(id))
+}
+
+// Delete 实现 Store
+func (s *FileStore) Delete(_ context.Context, id string) error {
+	if !validID(id) {
+		return nil
+	}
+	if err := os.Remove(s.path(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// cleanup 定期删除过期的会话文件
+func (s *FileStore) cleanup(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case now := <-ticker.C:
+			files, err := filepath.Glob(filepath.Join(s.dir, "sess_*"))
+			if err != nil {
+				continue
+			}
+			for _, file := range files {
+				data, err := os.ReadFile(file)
+				if err != nil {
+					continue
+				}
+				if expires, _, ok := splitFileEntry(data); !ok || now.After(expires) {
+					os.Remove(file)
+				}
+			}
+		}
+	}
+}
+
+// Close 停止清理协程
+func (s *FileStore) Close() {
+	s.once.Do(func() { close(s.stop) })
+}
+
+// splitFileEntry 解析文件内容: 第一行为过期时间（Unix 秒），之后为会话数据
+func splitFileEntry(data []byte) (time.Time, []byte, bool) {
+	for i, c := range data {
+		if c == '\n' {
+			var unix int64
+			if _, err := fmt.Sscanf(string(data[:i]), "%d", &unix); err != nil {
+				return time.Time{}, nil, false
+			}
+			return time.Unix(unix, 0), data[i+1:], true
+		}
+	}
+	return time.Time{}, nil, false
+}
+
+// ------------------------------------------------------------ Redis 存储 ------------------------------------------------------------
+
+// RedisStore 基于 Redis 的会话存储，适合多实例部署
+type RedisStore struct {
+	client redis.Cmdable
+	prefix string
+}
+
+// NewRedisStore 创建 Redis 会话存储
+// client 可以是 *redis.Client、*redis.ClusterClient 等；prefix 为 key 前缀，默认 "session:"
+func NewRedisStore(client redis.Cmdable, prefix string) *RedisStore {
+	if prefix == "" {
+		prefix = "session:"
+	}
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+// Load 实现 Store
+func (s *RedisStore) Load(ctx context.Context, id string) ([]byte, error) {
+	data, err := s.client.Get(ctx, s.prefix+id).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("redis session store: %w", err)
+	}
+	return data, nil
+}
+
+// Save 实现 Store
+func (s *RedisStore) Save(ctx context.Context, id string, data []byte, ttl time.Duration) error {
+	if err := s.client.Set(ctx, s.prefix+id, data, ttl).Err(); err != nil {
+		return fmt.Errorf("redis session store: %w", err)
+	}
+	return nil
+}
+
+// Delete 实现 Store
+func (s *RedisStore) Delete(ctx context.Context, id string) error {
+	if err := s.client.Del(ctx, s.prefix+id).Err(); err != nil {
+		return fmt.Errorf("redis session store: %w", err)
+	}
+	return nil
+}
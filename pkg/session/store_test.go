@@ -0,0 +1,138 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package session
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// testStore 存储及让已保存的数据过期的方法
+type testStore struct {
+	Store
+	// expire 让 ttl 为 time.Second 的数据过期
+	expire func()
+}
+
+func newTestStores(t *testing.T) map[string]testStore {
+	t.Helper()
+	memory := NewMemoryStore(0)
+	t.Cleanup(memory.Close)
+
+	file, err := NewFileStore(t.TempDir(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(file.Close)
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	// 内存和文件存储按墙上时间过期，文件存储的精度为秒
+	sleep := func() { time.Sleep(2100 * time.Millisecond) }
+	return map[string]testStore{
+		"memory": {Store: memory, expire: sleep},
+		"file":   {Store: file, expire: sleep},
+		"redis":  {Store: NewRedisStore(client, ""), expire: func() { mr.FastForward(2 * time.Second) }},
+	}
+}
+
+func TestStoreRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	for name, store := range newTestStores(t) {
+		t.Run(name, func(t *testing.T) {
+			id := newID()
+			if _, err := store.Load(ctx, id); !errors.Is(err, ErrNotFound) {
+				t.Fatalf("Load before Save: err = %v, want ErrNotFound", err)
+			}
+			if err := store.Save(ctx, id, []byte(`{"v":1}`), time.Minute); err != nil {
+				t.Fatalf("Save: %v", err)
+			}
+			data, err := store.Load(ctx, id)
+			if err != nil || !bytes.Equal(data, []byte(`{"v":1}`)) {
+				t.Fatalf("Load = %q, %v", data, err)
+			}
+			// 覆盖保存
+			if err := store.Save(ctx, id, []byte(`{"v":2}`), time.Minute); err != nil {
+				t.Fatalf("Save: %v", err)
+			}
+			if data, _ := store.Load(ctx, id); !bytes.Equal(data, []byte(`{"v":2}`)) {
+				t.Errorf("Load after overwrite = %q", data)
+			}
+			if err := store.Delete(ctx, id); err != nil {
+				t.Fatalf("Delete: %v", err)
+			}
+			if _, err := store.Load(ctx, id); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Load after Delete: err = %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestStoreExpiry(t *testing.T) {
+	if testing.Short() {
+		t.Skip("waits for wall-clock expiry")
+	}
+	ctx := context.Background()
+	for name, store := range newTestStores(t) {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			id := newID()
+			if err := store.Save(ctx, id, []byte("data"), time.Second); err != nil {
+				t.Fatalf("Save: %v", err)
+			}
+			if _, err := store.Load(ctx, id); err != nil {
+				t.Fatalf("Load before expiry: %v", err)
+			}
+			store.expire()
+			if _, err := store.Load(ctx, id); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Load after expiry: err = %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestFileStoreRejectsInvalidID(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStore(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(store.Close)
+	ctx := context.Background()
+
+	for _, id := range []string{"", "../escape", "sess", newID()[:63] + "/", newID()[:63] + "G"} {
+		if err := store.Save(ctx, id, []byte("data"), time.Minute); err == nil {
+			t.Errorf("Save(%q) succeeded", id)
+		}
+		if _, err := store.Load(ctx, id); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Load(%q): err = %v, want ErrNotFound", id, err)
+		}
+		if err := store.Delete(ctx, id); err != nil {
+			t.Errorf("Delete(%q): %v", id, err)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package telemetry
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/stones-hub/taurus-pro-http/pkg/httpx"
+	"github.com/stones-hub/taurus-pro-http/pkg/httpx/wrapper"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// knownMethods 语义约定中定义的 HTTP 方法，其余方法统一记为 _OTHER，避免基数膨胀
+var knownMethods = map[string]bool{
+	http.MethodConnect: true,
+	http.MethodDelete:  true,
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+	http.MethodPatch:   true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodTrace:   true,
+}
+
+// Middleware 为每个请求创建 server span 并记录 HTTP 指标
+// span 名称为 "METHOD route"，route 取自路由模式（r.Pattern），而不是原始路径，避免基数膨胀
+// 需要作为路由中间件挂载（即在 ServeMux 匹配之后执行），否则无法获取路由模式
+// WebSocket 握手请求的 span 会持续到连接关闭，同时记录会话指标
+func (i *Instrumentation) Middleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if i.filter != nil && !i.filter(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now()
+			route := httpx.RoutePattern(r)
+			method := r.Method
+			if !knownMethods[method] {
+				method = "_OTHER"
+			}
+			websocket := isWebSocketUpgrade(r)
+
+			ctx := i.propagators.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+			spanName := method
+			if route != "" {
+				spanName = method + " " + route
+			}
+			ctx, span := i.tracer.Start(ctx, spanName,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(i.requestAttributes(r, method, route)...),
+			)
+			defer span.End()
+
+			// 与 httpx 的 trace 上下文保持一致，httpx.GetTraceID 和日志中的 trace id 与 OpenTelemetry 相同
+			ctx = syncTraceContext(ctx, span.SpanContext())
+			// TraceMiddleware 在外层时已经写入了 traceresponse，使用同步后的 trace 上下文重写，与日志和响应体中的 trace_id 一致
+			if w.Header().Get(httpx.HeaderTraceResponse) != "" {
+				if tc, ok := httpx.TraceContextFromContext(ctx); ok {
+					w.Header().Set(httpx.HeaderTraceResponse, tc.TraceParent())
+				}
+			}
+
+			metricAttrs := []attribute.KeyValue{
+				semconv.HTTPRequestMethodKey.String(method),
+				semconv.URLScheme(httpx.Scheme(r)),
+			}
+			if route != "" {
+				metricAttrs = append(metricAttrs, semconv.HTTPRoute(route))
+			}
+			activeOpt := metric.WithAttributeSet(attribute.NewSet(metricAttrs...))
+			i.activeRequests.Add(ctx, 1, activeOpt)
+			defer i.activeRequests.Add(context.Background(), -1, activeOpt)
+
+			if websocket {
+				span.SetAttributes(attribute.Bool("websocket", true))
+				i.wsActiveSessions.Add(ctx, 1, metric.WithAttributes(semconv.HTTPRoute(route)))
+				defer func() {
+					i.wsActiveSessions.Add(context.Background(), -1, metric.WithAttributes(semconv.HTTPRoute(route)))
+					i.wsSessionTime.Record(context.Background(), time.Since(start).Seconds(), metric.WithAttributes(semconv.HTTPRoute(route)))
+				}()
+			}
+
+			body := &countingBody{ReadCloser: r.Body}
+			if r.Body != nil && r.Body != http.NoBody {
+				r.Body = body
+			}
+			rec := wrapper.NewStatusRecorder(w)
+
+			next.ServeHTTP(httpx.WithResponseContext(rec, ctx), r.WithContext(ctx))
+
+			status := rec.Status()
+			span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+			if status >= 500 {
+				span.SetStatus(codes.Error, http.StatusText(status))
+				span.SetAttributes(semconv.ErrorTypeKey.String(strconv.Itoa(status)))
+			}
+			if n := body.n.Load(); n > 0 {
+				span.SetAttributes(semconv.HTTPRequestBodySize(int(n)))
+			}
+			span.SetAttributes(semconv.HTTPResponseBodySize(int(rec.BytesWritten())))
+
+			metricAttrs = append(metricAttrs,
+				semconv.HTTPResponseStatusCode(status),
+				semconv.NetworkProtocolVersion(protocolVersion(r)),
+			)
+			if status >= 500 {
+				metricAttrs = append(metricAttrs, semconv.ErrorTypeKey.String(strconv.Itoa(status)))
+			}
+			opt := metric.WithAttributeSet(attribute.NewSet(metricAttrs...))
+			// 请求结束后 r.Context() 可能已取消，使用 Background 保证指标被记录
+			i.requestDuration.Record(context.Background(), time.Since(start).Seconds(), opt)
+			i.requestBodySize.Record(context.Background(), body.n.Load(), opt)
+			i.responseBodySize.Record(context.Background(), rec.BytesWritten(), opt)
+		})
+	}
+}
+
+// requestAttributes span 的请求属性
+func (i *Instrumentation) requestAttributes(r *http.Request, method, route string) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		semconv.HTTPRequestMethodKey.String(method),
+		semconv.URLScheme(httpx.Scheme(r)),
+		semconv.URLPath(r.URL.Path),
+		semconv.NetworkProtocolVersion(protocolVersion(r)),
+	}
+	if method == "_OTHER" {
+		attrs = append(attrs, semconv.HTTPRequestMethodOriginal(r.Method))
+	}
+	if route != "" {
+		attrs = append(attrs, semconv.HTTPRoute(route))
+	}
+	host, port := i.serverName, 0
+	if host == "" {
+		host, port = splitHostPort(httpx.Host(r))
+	}
+	if host != "" {
+		attrs = append(attrs, semconv.ServerAddress(host))
+	}
+	if port > 0 {
+		attrs = append(attrs, semconv.ServerPort(port))
+	}
+	if ua := r.UserAgent(); ua != "" {
+		attrs = append(attrs, semconv.UserAgentOriginal(ua))
+	}
+	if ip := httpx.ClientIP(r); ip != "" {
+		attrs = append(attrs, semconv.ClientAddress(ip))
+	}
+	if peer, _ := splitHostPort(r.RemoteAddr); peer != "" {
+		attrs = append(attrs, semconv.NetworkPeerAddress(peer))
+	}
+	return attrs
+}
+
+// syncTraceContext 将 OpenTelemetry span 上下文写入 httpx 的 trace 上下文
+func syncTraceContext(ctx context.Context, sc trace.SpanContext) context.Context {
+	if !sc.IsValid() {
+		return ctx
+	}
+	tc, _ := httpx.TraceContextFromContext(ctx)
+	if tc.TraceID != sc.TraceID().String() {
+		tc.ParentSpanID = ""
+		if parent := trace.SpanContextFromContext(ctx); parent.IsValid() && parent.SpanID() != sc.SpanID() {
+			tc.ParentSpanID = parent.SpanID().String()
+		}
+	}
+	tc.TraceID = sc.TraceID().String()
+	tc.SpanID = sc.SpanID().String()
+	tc.Flags = byte(sc.TraceFlags())
+	tc.TraceState = sc.TraceState().String()
+	return httpx.WithTraceContext(ctx, tc)
+}
+
+// isWebSocketUpgrade 是否为 WebSocket 握手请求
+func isWebSocketUpgrade(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket") &&
+		strings.Contains(strings.ToLower(r.Header.Get("Connection")), "upgrade")
+}
+
+// protocolVersion 协议版本，如 1.1、2
+func protocolVersion(r *http.Request) string {
+	switch r.ProtoMajor {
+	case 1:
+		return "1." + strconv.Itoa(r.ProtoMinor)
+	default:
+		return strconv.Itoa(r.ProtoMajor)
+	}
+}
+
+// splitHostPort 拆分 host:port，端口不存在时返回 0
+func splitHostPort(hostport string) (string, int) {
+	host, portStr, err := net.SplitHostPort(hostport)
+	if err != nil {
+		return hostport, 0
+	}
+	port, _ := strconv.Atoi(portStr)
+	return host, port
+}
+
+// countingBody 统计请求体读取字节数
+type countingBody struct {
+	io.ReadCloser
+	n atomic.Int64
+}
+
+// Read 读取并计数
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n.Add(int64(n))
+	return n, err
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package telemetry
+
+import (
+	"context"
+	"time"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/ThinkInAIXYZ/go-mcp/server"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ToolMiddleware 为 MCP 工具调用埋点，每次调用创建一个 span 并记录耗时
+// 通过 mcp.MCPServer.Use(inst.ToolMiddleware()) 挂载
+func (i *Instrumentation) ToolMiddleware() server.ToolMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+			start := time.Now()
+			attrs := []attribute.KeyValue{
+				attribute.String("mcp.tool.name", req.Name),
+				attribute.String("rpc.system", "mcp"),
+				attribute.String("rpc.method", "tools/call"),
+			}
+
+			ctx, span := i.tracer.Start(ctx, "mcp.tool "+req.Name,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(attrs...),
+			)
+			defer span.End()
+
+			result, err := next(ctx, req)
+
+			switch {
+			case err != nil:
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				attrs = append(attrs, attribute.String("error.type", "error"))
+			case result != nil && result.IsError:
+				// 工具返回业务错误，调用本身成功
+				span.SetStatus(codes.Error, "tool returned error result")
+				attrs = append(attrs, attribute.String("error.type", "tool_error"))
+			}
+			i.toolDuration.Record(context.Background(), time.Since(start).Seconds(), metric.WithAttributes(attrs...))
+
+			return result, err
+		}
+	}
+}
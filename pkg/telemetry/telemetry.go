@@ -0,0 +1,192 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+// Package telemetry 提供可选的 OpenTelemetry 埋点
+// 只依赖 OpenTelemetry API，不依赖 SDK；未配置 Provider 时使用 otel 全局 Provider（默认为 noop）
+// 测试时可以传入 sdktrace.NewTracerProvider(sdktrace.WithSyncer(tracetest.NewInMemoryExporter()))
+// 和 sdkmetric.NewMeterProvider(sdkmetric.WithReader(sdkmetric.NewManualReader())) 来检查产生的数据
+package telemetry
+
+import (
+	"fmt"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName 埋点库名称，作为 Tracer/Meter 的名称
+const instrumentationName = "github.com/stones-hub/taurus-pro-http/pkg/telemetry"
+
+// instrumentationVersion 埋点库版本
+const instrumentationVersion = "1.0.0"
+
+// Instrumentation OpenTelemetry 埋点
+type Instrumentation struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	propagators    propagation.TextMapPropagator
+	serverName     string
+	filter         func(*http.Request) bool
+
+	tracer trace.Tracer
+	meter  metric.Meter
+
+	// HTTP 指标
+	requestDuration  metric.Float64Histogram
+	activeRequests   metric.Int64UpDownCounter
+	requestBodySize  metric.Int64Histogram
+	responseBodySize metric.Int64Histogram
+
+	// WebSocket 指标
+	wsActiveSessions metric.Int64UpDownCounter
+	wsSessionTime    metric.Float64Histogram
+	wsMessages       metric.Int64Counter
+
+	// MCP 指标
+	toolDuration metric.Float64Histogram
+}
+
+// Option 埋点配置选项
+type Option func(*Instrumentation)
+
+// WithTracerProvider 设置 TracerProvider，默认使用 otel.GetTracerProvider()
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(i *Instrumentation) {
+		i.tracerProvider = tp
+	}
+}
+
+// WithMeterProvider 设置 MeterProvider，默认使用 otel.GetMeterProvider()
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(i *Instrumentation) {
+		i.meterProvider = mp
+	}
+}
+
+// WithPropagators 设置上下文传播器，默认使用 otel.GetTextMapPropagator()
+// 全局传播器未配置时回退为 W3C TraceContext + Baggage
+func WithPropagators(p propagation.TextMapPropagator) Option {
+	return func(i *Instrumentation) {
+		i.propagators = p
+	}
+}
+
+// WithServerName 设置 server.address 属性，默认使用请求的 Host
+func WithServerName(name string) Option {
+	return func(i *Instrumentation) {
+		i.serverName = name
+	}
+}
+
+// WithFilter 设置请求过滤函数，返回 false 的请求不产生 span 和指标（如健康检查）
+func WithFilter(filter func(*http.Request) bool) Option {
+	return func(i *Instrumentation) {
+		i.filter = filter
+	}
+}
+
+// New 创建埋点实例
+func New(options ...Option) (*Instrumentation, error) {
+	i := &Instrumentation{}
+	for _, option := range options {
+		option(i)
+	}
+
+	if i.tracerProvider == nil {
+		i.tracerProvider = otel.GetTracerProvider()
+	}
+	if i.meterProvider == nil {
+		i.meterProvider = otel.GetMeterProvider()
+	}
+	if i.propagators == nil {
+		i.propagators = otel.GetTextMapPropagator()
+		// 全局传播器默认是空实现，无法解析 traceparent
+		if len(i.propagators.Fields()) == 0 {
+			i.propagators = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+		}
+	}
+
+	i.tracer = i.tracerProvider.Tracer(instrumentationName, trace.WithInstrumentationVersion(instrumentationVersion))
+	i.meter = i.meterProvider.Meter(instrumentationName, metric.WithInstrumentationVersion(instrumentationVersion))
+
+	if err := i.createInstruments(); err != nil {
+		return nil, fmt.Errorf("failed to create instruments: %w", err)
+	}
+	return i, nil
+}
+
+// createInstruments 创建指标，名称和单位遵循 OpenTelemetry HTTP 语义约定
+func (i *Instrumentation) createInstruments() error {
+	var err error
+
+	if i.requestDuration, err = i.meter.Float64Histogram("http.server.request.duration",
+		metric.WithUnit("s"),
+		metric.WithDescription("Duration of HTTP server requests."),
+		metric.WithExplicitBucketBoundaries(0.005, 0.01, 0.025, 0.05, 0.075, 0.1, 0.25, 0.5, 0.75, 1, 2.5, 5, 7.5, 10),
+	); err != nil {
+		return err
+	}
+	if i.activeRequests, err = i.meter.Int64UpDownCounter("http.server.active_requests",
+		metric.WithUnit("{request}"),
+		metric.WithDescription("Number of active HTTP server requests."),
+	); err != nil {
+		return err
+	}
+	if i.requestBodySize, err = i.meter.Int64Histogram("http.server.request.body.size",
+		metric.WithUnit("By"),
+		metric.WithDescription("Size of HTTP server request bodies."),
+	); err != nil {
+		return err
+	}
+	if i.responseBodySize, err = i.meter.Int64Histogram("http.server.response.body.size",
+		metric.WithUnit("By"),
+		metric.WithDescription("Size of HTTP server response bodies."),
+	); err != nil {
+		return err
+	}
+
+	if i.wsActiveSessions, err = i.meter.Int64UpDownCounter("websocket.server.active_sessions",
+		metric.WithUnit("{session}"),
+		metric.WithDescription("Number of active websocket sessions."),
+	); err != nil {
+		return err
+	}
+	if i.wsSessionTime, err = i.meter.Float64Histogram("websocket.server.session.duration",
+		metric.WithUnit("s"),
+		metric.WithDescription("Duration of websocket sessions."),
+	); err != nil {
+		return err
+	}
+	if i.wsMessages, err = i.meter.Int64Counter("websocket.server.messages",
+		metric.WithUnit("{message}"),
+		metric.WithDescription("Number of websocket messages handled."),
+	); err != nil {
+		return err
+	}
+
+	if i.toolDuration, err = i.meter.Float64Histogram("mcp.server.tool.duration",
+		metric.WithUnit("s"),
+		metric.WithDescription("Duration of MCP tool calls."),
+	); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,259 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package telemetry_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ThinkInAIXYZ/go-mcp/protocol"
+	"github.com/stones-hub/taurus-pro-http/pkg/httpx"
+	"github.com/stones-hub/taurus-pro-http/pkg/middleware"
+	"github.com/stones-hub/taurus-pro-http/pkg/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// testInstrumentation 使用内存导出器和手动读取器创建埋点
+func testInstrumentation(t *testing.T, options ...telemetry.Option) (*telemetry.Instrumentation, *tracetest.InMemoryExporter, *sdkmetric.ManualReader) {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	reader := sdkmetric.NewManualReader()
+	options = append([]telemetry.Option{
+		telemetry.WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))),
+		telemetry.WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+	}, options...)
+	inst, err := telemetry.New(options...)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return inst, exporter, reader
+}
+
+// collectMetric 读取名称为 name 的指标
+func collectMetric(t *testing.T, reader *sdkmetric.ManualReader, name string) (metricdata.Metrics, bool) {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name == name {
+				return m, true
+			}
+		}
+	}
+	return metricdata.Metrics{}, false
+}
+
+// spanAttr 读取 span 属性
+func spanAttr(span tracetest.SpanStub, key attribute.Key) (attribute.Value, bool) {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestMiddlewareRecordsSpanAndMetrics(t *testing.T) {
+	inst, exporter, reader := testInstrumentation(t)
+
+	mux := http.NewServeMux()
+	mux.Handle("/users/{id}", inst.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("boom"))
+	})))
+
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/42", nil))
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	span := spans[0]
+	if span.Name != "GET /users/{id}" {
+		t.Errorf("span name = %q, want %q", span.Name, "GET /users/{id}")
+	}
+	if span.SpanKind != trace.SpanKindServer {
+		t.Errorf("span kind = %v, want server", span.SpanKind)
+	}
+	if span.Status.Code != codes.Error {
+		t.Errorf("span status = %v, want error", span.Status.Code)
+	}
+	if v, ok := spanAttr(span, "http.route"); !ok || v.AsString() != "/users/{id}" {
+		t.Errorf("http.route = %v, want /users/{id}", v.Emit())
+	}
+	if v, ok := spanAttr(span, "http.response.status_code"); !ok || v.AsInt64() != 500 {
+		t.Errorf("http.response.status_code = %v, want 500", v.Emit())
+	}
+	if v, ok := spanAttr(span, "http.response.body.size"); !ok || v.AsInt64() != 4 {
+		t.Errorf("http.response.body.size = %v, want 4", v.Emit())
+	}
+
+	m, ok := collectMetric(t, reader, "http.server.request.duration")
+	if !ok {
+		t.Fatal("http.server.request.duration not recorded")
+	}
+	hist, ok := m.Data.(metricdata.Histogram[float64])
+	if !ok || len(hist.DataPoints) != 1 {
+		t.Fatalf("unexpected request duration data: %#v", m.Data)
+	}
+	point := hist.DataPoints[0]
+	if point.Count != 1 {
+		t.Errorf("request count = %d, want 1", point.Count)
+	}
+	if v, ok := point.Attributes.Value("http.route"); !ok || v.AsString() != "/users/{id}" {
+		t.Errorf("metric http.route = %v, want /users/{id}", v.Emit())
+	}
+	if v, ok := point.Attributes.Value("error.type"); !ok || v.AsString() != "500" {
+		t.Errorf("metric error.type = %v, want 500", v.Emit())
+	}
+}
+
+func TestMiddlewareContinuesIncomingTrace(t *testing.T) {
+	inst, exporter, _ := testInstrumentation(t)
+
+	var traceID string
+	handler := inst.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceID = httpx.GetTraceID(r)
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	if got := spans[0].SpanContext.TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("span trace id = %s, want the incoming trace id", got)
+	}
+	if got := spans[0].Parent.SpanID().String(); got != "00f067aa0ba902b7" {
+		t.Errorf("parent span id = %s, want 00f067aa0ba902b7", got)
+	}
+	if traceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("httpx.GetTraceID = %s, want the incoming trace id", traceID)
+	}
+}
+
+func TestTraceIDsConsistentWithTraceMiddleware(t *testing.T) {
+	for _, order := range []string{"trace outer", "telemetry outer"} {
+		t.Run(order, func(t *testing.T) {
+			inst, exporter, _ := testInstrumentation(t)
+
+			var handlerTraceID string
+			handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				handlerTraceID = httpx.GetTraceID(r)
+				httpx.SendResponse(w, http.StatusOK, nil, nil)
+			}))
+			if order == "trace outer" {
+				handler = middleware.TraceMiddleware(nil)(inst.Middleware()(handler))
+			} else {
+				handler = inst.Middleware()(middleware.TraceMiddleware(nil)(handler))
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			spans := exporter.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("got %d spans, want 1", len(spans))
+			}
+			spanTraceID := spans[0].SpanContext.TraceID().String()
+
+			header, err := httpx.ParseTraceParent(w.Header().Get(httpx.HeaderTraceResponse))
+			if err != nil {
+				t.Fatalf("invalid traceresponse: %v", err)
+			}
+			var body httpx.Response
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("invalid response body: %v", err)
+			}
+
+			for name, got := range map[string]string{
+				"traceresponse":    header.TraceID,
+				"response body":    body.TraceID,
+				"httpx.GetTraceID": handlerTraceID,
+			} {
+				if got != spanTraceID {
+					t.Errorf("%s trace id = %s, want span trace id %s", name, got, spanTraceID)
+				}
+			}
+		})
+	}
+}
+
+func TestMiddlewareFilter(t *testing.T) {
+	inst, exporter, _ := testInstrumentation(t, telemetry.WithFilter(func(r *http.Request) bool {
+		return r.URL.Path != "/healthz"
+	}))
+	handler := inst.Middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if n := len(exporter.GetSpans()); n != 0 {
+		t.Errorf("filtered request produced %d spans", n)
+	}
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api", nil))
+	if n := len(exporter.GetSpans()); n != 1 {
+		t.Errorf("got %d spans, want 1", n)
+	}
+}
+
+func TestToolMiddleware(t *testing.T) {
+	inst, exporter, reader := testInstrumentation(t)
+	failing := inst.ToolMiddleware()(func(ctx context.Context, req *protocol.CallToolRequest) (*protocol.CallToolResult, error) {
+		return nil, errors.New("tool failed")
+	})
+
+	req := &protocol.CallToolRequest{Name: "search"}
+	if _, err := failing(context.Background(), req); err == nil {
+		t.Fatal("expected the tool error to be returned")
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	if spans[0].Name != "mcp.tool search" || spans[0].Status.Code != codes.Error {
+		t.Errorf("span = %q (%v), want mcp.tool search with error status", spans[0].Name, spans[0].Status.Code)
+	}
+
+	m, ok := collectMetric(t, reader, "mcp.server.tool.duration")
+	if !ok {
+		t.Fatal("mcp.server.tool.duration not recorded")
+	}
+	hist := m.Data.(metricdata.Histogram[float64])
+	if len(hist.DataPoints) != 1 || hist.DataPoints[0].Count != 1 {
+		t.Fatalf("unexpected tool duration data: %#v", hist.DataPoints)
+	}
+	if v, _ := hist.DataPoints[0].Attributes.Value("error.type"); v.AsString() != "error" {
+		t.Errorf("error.type = %q, want error", v.AsString())
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package telemetry
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gorilla/websocket"
+	"github.com/stones-hub/taurus-pro-http/pkg/httpx"
+	"github.com/stones-hub/taurus-pro-http/pkg/wsocket"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// WrapMessageHandler 为 WebSocket 消息处理函数埋点
+// 每条消息创建一个 span，父 span 为握手请求的 server span（由 Middleware 创建），因此整个会话在同一条链路上
+// 示例:
+//
+//	Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+//	    wsocket.HandleWebSocket(w, r, inst.WrapMessageHandler(r, handler))
+//	})
+func (i *Instrumentation) WrapMessageHandler(r *http.Request, handler wsocket.MessageHandler) wsocket.MessageHandler {
+	parent := r.Context()
+	route := httpx.RoutePattern(r)
+	opt := metric.WithAttributeSet(attribute.NewSet(semconv.HTTPRoute(route)))
+
+	return func(conn *websocket.Conn, messageType int, message []byte) error {
+		_, span := i.tracer.Start(parent, "websocket.message",
+			trace.WithSpanKind(trace.SpanKindConsumer),
+			trace.WithAttributes(
+				semconv.HTTPRoute(route),
+				attribute.String("websocket.message.type", messageTypeName(messageType)),
+				attribute.Int("websocket.message.size", len(message)),
+			),
+		)
+		defer span.End()
+
+		i.wsMessages.Add(context.Background(), 1, opt)
+
+		err := handler(conn, messageType, message)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		return err
+	}
+}
+
+// messageTypeName WebSocket 消息类型名称
+func messageTypeName(messageType int) string {
+	switch messageType {
+	case websocket.TextMessage:
+		return "text"
+	case websocket.BinaryMessage:
+		return "binary"
+	case websocket.CloseMessage:
+		return "close"
+	case websocket.PingMessage:
+		return "ping"
+	case websocket.PongMessage:
+		return "pong"
+	default:
+		return "unknown"
+	}
+}
@@ -20,19 +20,24 @@ package wsocket
 
 import (
 	"log"
+	"sync"
 
 	"github.com/gorilla/websocket"
 )
 
 // Room 代表一个聊天室
 type Room struct {
+	mu        sync.RWMutex
 	clients   map[*websocket.Conn]bool
 	broadcast chan []byte
+	done      chan struct{} // 房间关闭时关闭，广播协程退出
 }
 
 // WebSocketHub 管理多个聊天室
 type WebSocketHub struct {
-	rooms map[string]*Room
+	mu     sync.RWMutex
+	rooms  map[string]*Room
+	closed bool
 }
 
 // NewWebSocketHub 创建一个新的 WebSocketHub
@@ -43,52 +48,154 @@ func NewWebSocketHub() *WebSocketHub {
 }
 
 // GetOrCreateRoom 获取或创建一个房间
+// Hub 关闭后创建的房间不会启动广播协程，广播的消息被丢弃
 func (hub *WebSocketHub) GetOrCreateRoom(roomName string) *Room {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
 	room, exists := hub.rooms[roomName]
 	if !exists {
 		room = &Room{
 			clients:   make(map[*websocket.Conn]bool),
 			broadcast: make(chan []byte),
+			done:      make(chan struct{}),
 		}
 		hub.rooms[roomName] = room
-		go room.start()
+		if hub.closed {
+			close(room.done)
+		} else {
+			go room.start()
+		}
 	}
 	return room
 }
 
+// Close 停止所有房间的广播协程，并向房间内的客户端发送 1001 going away 关闭帧
+// 连接由各自的处理器在读循环退出后关闭；通常在服务关闭时调用:
+//
+//	srv.RegisterOnShutdown(hub.Close)
+func (hub *WebSocketHub) Close() {
+	hub.mu.Lock()
+	if hub.closed {
+		hub.mu.Unlock()
+		return
+	}
+	hub.closed = true
+	rooms := make([]*Room, 0, len(hub.rooms))
+	for _, room := range hub.rooms {
+		rooms = append(rooms, room)
+	}
+	hub.mu.Unlock()
+
+	// 发送关闭帧可能阻塞到写超时，不持有锁
+	for _, room := range rooms {
+		room.close()
+	}
+}
+
 // AdminBroadcast 向指定房间广播消息
 func (hub *WebSocketHub) AdminBroadcast(roomName string, message []byte) {
-	if room, exists := hub.rooms[roomName]; exists {
+	hub.mu.RLock()
+	room, exists := hub.rooms[roomName]
+	hub.mu.RUnlock()
+	if exists {
 		room.BroadcastMessage(message)
 	}
 }
 
-// start 启动房间的广播协程
+// RoomCount 返回房间数量
+func (hub *WebSocketHub) RoomCount() int {
+	hub.mu.RLock()
+	defer hub.mu.RUnlock()
+	return len(hub.rooms)
+}
+
+// ClientCount 返回所有房间的连接总数
+func (hub *WebSocketHub) ClientCount() int {
+	hub.mu.RLock()
+	defer hub.mu.RUnlock()
+	total := 0
+	for _, room := range hub.rooms {
+		total += room.ClientCount()
+	}
+	return total
+}
+
+// RoomClientCounts 返回每个房间的连接数
+func (hub *WebSocketHub) RoomClientCounts() map[string]int {
+	hub.mu.RLock()
+	defer hub.mu.RUnlock()
+	counts := make(map[string]int, len(hub.rooms))
+	for name, room := range hub.rooms {
+		counts[name] = room.ClientCount()
+	}
+	return counts
+}
+
+// start 启动房间的广播协程，房间关闭时退出
 func (room *Room) start() {
 	for {
-		message := <-room.broadcast
-		for client := range room.clients {
+		var message []byte
+		select {
+		case message = <-room.broadcast:
+		case <-room.done:
+			return
+		}
+		// 在锁外写入，慢客户端不会阻塞 ClientCount（指标采集）和 Hub.Close
+		for _, client := range room.snapshot() {
 			err := client.WriteMessage(websocket.TextMessage, message)
 			if err != nil {
 				log.Printf("Error broadcasting message to client: %v\n", err)
 				client.Close()
-				delete(room.clients, client)
+				room.RemoveClient(client)
 			}
 		}
 	}
 }
 
+// snapshot 返回当前客户端列表的副本
+func (room *Room) snapshot() []*websocket.Conn {
+	room.mu.RLock()
+	defer room.mu.RUnlock()
+	clients := make([]*websocket.Conn, 0, len(room.clients))
+	for client := range room.clients {
+		clients = append(clients, client)
+	}
+	return clients
+}
+
 // AddClient 添加一个新的 WebSocket 客户端到房间
 func (room *Room) AddClient(conn *websocket.Conn) {
+	room.mu.Lock()
+	defer room.mu.Unlock()
 	room.clients[conn] = true
 }
 
 // RemoveClient 移除一个 WebSocket 客户端从房间
 func (room *Room) RemoveClient(conn *websocket.Conn) {
+	room.mu.Lock()
+	defer room.mu.Unlock()
 	delete(room.clients, conn)
 }
 
-// BroadcastMessage 向房间内的客户端广播消息
+// ClientCount 返回房间内的连接数
+func (room *Room) ClientCount() int {
+	room.mu.RLock()
+	defer room.mu.RUnlock()
+	return len(room.clients)
+}
+
+// BroadcastMessage 向房间内的客户端广播消息，房间关闭后消息被丢弃
 func (room *Room) BroadcastMessage(message []byte) {
-	room.broadcast <- message
+	select {
+	case room.broadcast <- message:
+	case <-room.done:
+	}
+}
+
+// close 停止广播协程并通知房间内的客户端
+func (room *Room) close() {
+	close(room.done)
+	for _, client := range room.snapshot() {
+		closeGoingAway(client)
+	}
 }
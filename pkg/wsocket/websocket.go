@@ -19,14 +19,19 @@
 package wsocket
 
 import (
-	"crypto/md5"
-	"encoding/hex"
+	"context"
+	"errors"
 	"log"
 	"net/http"
 	"runtime/debug"
+	"slices"
+	"time"
 
-	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
+	"github.com/stones-hub/taurus-pro-http/pkg/auth"
+	"github.com/stones-hub/taurus-pro-http/pkg/httpx"
+	"github.com/stones-hub/taurus-pro-http/pkg/middleware"
+	"github.com/stones-hub/taurus-pro-http/pkg/server"
 )
 
 /*
@@ -40,17 +45,54 @@ var upgrader websocket.Upgrader
 // MessageHandler defines a function type for handling messages
 type MessageHandler func(conn *websocket.Conn, messageType int, message []byte) error
 
+// UpgraderConfig WebSocket 升级配置，零值使用 gorilla/websocket 的默认值
+type UpgraderConfig struct {
+	// HandshakeTimeout 握手超时时间，0 表示不限制
+	HandshakeTimeout time.Duration
+	// ReadBufferSize / WriteBufferSize 读写缓冲区大小（字节），默认 4096
+	ReadBufferSize  int
+	WriteBufferSize int
+	// EnableCompression 是否协商 permessage-deflate 压缩
+	EnableCompression bool
+	// AllowedOrigins 允许的 Origin（如 "https://example.com"、"https://*.example.com"），为空或包含 "*" 时允许所有
+	// 没有 Origin 头的请求（非浏览器客户端）总是允许
+	AllowedOrigins []string
+}
+
 // Initialize initializes the WebSocket upgrader
 func Initialize() {
+	InitializeWithConfig(UpgraderConfig{})
+}
+
+// InitializeWithConfig 使用配置初始化 WebSocket upgrader，应在启动服务前调用
+func InitializeWithConfig(config UpgraderConfig) {
 	upgrader = websocket.Upgrader{
-		CheckOrigin: func(r *http.Request) bool {
-			// TODO Allow all origins for simplicity; customize as needed
-			return true
-		},
+		HandshakeTimeout:  config.HandshakeTimeout,
+		ReadBufferSize:    config.ReadBufferSize,
+		WriteBufferSize:   config.WriteBufferSize,
+		EnableCompression: config.EnableCompression,
+		CheckOrigin:       checkOrigin(config.AllowedOrigins),
 	}
 	log.Println("WebSocket upgrader initialized")
 }
 
+// checkOrigin 根据允许的 Origin 列表生成检查函数，格式与 CORS 的 AllowOrigins 相同（支持 https://*.example.com）
+// 列表无效时记录日志并拒绝所有带 Origin 头的请求，避免错误配置放开跨域
+func checkOrigin(origins []string) func(r *http.Request) bool {
+	if len(origins) == 0 || slices.Contains(origins, "*") {
+		return func(r *http.Request) bool { return true }
+	}
+	match, err := middleware.MatchOrigins(origins)
+	if err != nil {
+		log.Printf("[WebSocket] invalid allowed origins, rejecting cross-origin requests: %v\n", err)
+		match = func(string) bool { return false }
+	}
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		return origin == "" || match(origin)
+	}
+}
+
 // HandleWebSocket handles WebSocket connections with a custom message handler
 func HandleWebSocket(w http.ResponseWriter, r *http.Request, handler MessageHandler) {
 	defer func() { // websocket的特殊性，需要在处理函数中解决异常、错误问题， 不能用middleware来解决
@@ -59,9 +101,16 @@ func HandleWebSocket(w http.ResponseWriter, r *http.Request, handler MessageHand
 		}
 	}()
 
-	// 对于websocket来说，每个请求是长连接，放在中间件来处理trace_id 不合适，所以需要手动生成
-	hash := md5.Sum([]byte(uuid.New().String()))
-	traceid := hex.EncodeToString(hash[:])
+	// trace id 由 middleware.TraceMiddleware 在握手请求上生成，整个长连接复用同一个 trace id
+	// 未挂载该中间件时，为本次连接单独生成一个
+	traceid := httpx.GetTraceID(r)
+	if traceid == "" {
+		traceid = httpx.NewTraceID()
+	}
+
+	// 服务关闭时 ctx 取消，Shutdown 会等待读循环退出
+	ctx, done := server.LongLived(r)
+	defer done()
 
 	// Upgrade the HTTP connection to a WebSocket connection
 	conn, err := upgrader.Upgrade(w, r, nil)
@@ -71,6 +120,7 @@ func HandleWebSocket(w http.ResponseWriter, r *http.Request, handler MessageHand
 		return
 	}
 	defer conn.Close()
+	defer closeOnShutdown(ctx, conn)()
 
 	log.Printf("websocket connection established, traceid: %s\n", traceid)
 
@@ -78,7 +128,7 @@ func HandleWebSocket(w http.ResponseWriter, r *http.Request, handler MessageHand
 	for {
 		messageType, message, err := conn.ReadMessage()
 		if err != nil {
-			log.Printf("Error reading message, error: %v\n", err)
+			log.Printf("Error reading message, traceid: %s, error: %v\n", traceid, err)
 			break
 		}
 
@@ -86,7 +136,7 @@ func HandleWebSocket(w http.ResponseWriter, r *http.Request, handler MessageHand
 
 		// Call the custom message handler
 		if err := handler(conn, messageType, message); err != nil {
-			log.Printf("Error handling message, error: %v\n", err)
+			log.Printf("Error handling message, traceid: %s, error: %v\n", traceid, err)
 			break
 		}
 	}
@@ -111,12 +161,15 @@ func HandleWebSocketRoom(w http.ResponseWriter, r *http.Request, handler Message
 	}
 
 	// 检查用户是否有权进入房间
-	if !checkRoomAccess(userid, roomName) {
+	if !checkRoomAccess(r, userid, roomName) {
 		log.Printf("Access denied for user %s to room %s\n", userid, roomName)
 		http.Error(w, "Forbidden", http.StatusForbidden)
 		return
 	}
 
+	ctx, done := server.LongLived(r)
+	defer done()
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Printf("Failed to upgrade connection, error: %v\n", err)
@@ -124,6 +177,7 @@ func HandleWebSocketRoom(w http.ResponseWriter, r *http.Request, handler Message
 		return
 	}
 	defer conn.Close()
+	defer closeOnShutdown(ctx, conn)()
 
 	room := hub.GetOrCreateRoom(roomName)
 	room.AddClient(conn)
@@ -150,17 +204,55 @@ func HandleWebSocketRoom(w http.ResponseWriter, r *http.Request, handler Message
 }
 
 // authenticateUser 验证用户身份
+// 用户身份由挂载在 WebSocket 路由上的认证中间件（如 auth.Authenticate、auth.NewJWTMiddleware）写入请求上下文
+// 浏览器无法为 WebSocket 握手设置 Authorization 头，可以通过查询参数传递 token 或 API Key
 func authenticateUser(r *http.Request) (string, error) {
-	// 在这里实现您的身份验证逻辑
-	// 返回用户ID或错误
-	log.Printf("authenticateUser, request: %v\n", r)
-	return "userid", nil
+	principal := auth.GetPrincipal(r)
+	if principal == nil || principal.ID == "" {
+		return "", errors.New("unauthenticated websocket request")
+	}
+	return principal.ID, nil
+}
+
+// RoomAccessFunc 房间访问检查函数，返回 true 表示有权进入
+type RoomAccessFunc func(r *http.Request, userid, roomName string) bool
+
+// roomAccess 房间访问检查，默认允许所有已认证用户进入
+var roomAccess RoomAccessFunc = func(*http.Request, string, string) bool { return true }
+
+// SetRoomAccessChecker 设置房间访问检查，如根据 auth.GetPrincipal(r) 的角色判断
+// 应在启动服务前调用
+func SetRoomAccessChecker(fn RoomAccessFunc) {
+	if fn != nil {
+		roomAccess = fn
+	}
 }
 
 // checkRoomAccess 检查用户是否有权进入房间
-func checkRoomAccess(userid, roomName string) bool {
-	// 在这里实现您的权限检查逻辑
-	// 返回 true 表示有权进入，false 表示无权进入
-	log.Printf("checkRoomAccess, userid: %s, roomName: %s\n", userid, roomName)
-	return true
+func checkRoomAccess(r *http.Request, userid, roomName string) bool {
+	return roomAccess(r, userid, roomName)
+}
+
+// closeTimeout 发送关闭帧后等待客户端回复的时间，超时后读循环退出
+const closeTimeout = 5 * time.Second
+
+// closeOnShutdown ctx 取消（服务关闭）时向客户端发送 1001 going away 关闭帧，返回的函数用于取消监听
+func closeOnShutdown(ctx context.Context, conn *websocket.Conn) func() {
+	stop := context.AfterFunc(ctx, func() {
+		closeGoingAway(conn)
+	})
+	return func() { stop() }
+}
+
+// closeGoingAway 发送 1001 going away 关闭帧，客户端回复关闭帧后 ReadMessage 返回错误，读循环退出
+// 客户端没有回复时，读超时保证读循环在 closeTimeout 后退出
+// WriteControl 可以与其他读写方法并发调用
+func closeGoingAway(conn *websocket.Conn) {
+	message := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
+	err := conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(time.Second))
+	if err != nil && !errors.Is(err, websocket.ErrCloseSent) {
+		conn.Close()
+		return
+	}
+	conn.NetConn().SetReadDeadline(time.Now().Add(closeTimeout))
 }
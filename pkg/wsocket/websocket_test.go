@@ -0,0 +1,55 @@
+// Copyright (c) 2025 Taurus Team. All rights reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Author: yelei
+// Email: 61647649@qq.com
+// Date: 2026-10-18
+
+package wsocket
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCheckOrigin(t *testing.T) {
+	tests := []struct {
+		name    string
+		origins []string
+		origin  string
+		want    bool
+	}{
+		{"empty list allows all", nil, "https://evil.test", true},
+		{"star allows all", []string{"*"}, "https://evil.test", true},
+		{"no origin header", []string{"https://app.example.com"}, "", true},
+		{"exact match ignores case", []string{"https://app.example.com/"}, "https://APP.example.com", true},
+		{"exact mismatch", []string{"https://app.example.com"}, "https://evil.test", false},
+		{"wildcard subdomain", []string{"https://*.example.com"}, "https://a.example.com", true},
+		{"wildcard excludes apex", []string{"https://*.example.com"}, "https://example.com", false},
+		{"wildcard checks scheme", []string{"https://*.example.com"}, "http://a.example.com", false},
+		{"invalid list rejects", []string{"not an origin"}, "https://app.example.com", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/ws", nil)
+			if tt.origin != "" {
+				r.Header.Set("Origin", tt.origin)
+			}
+			if got := checkOrigin(tt.origins)(r); got != tt.want {
+				t.Errorf("checkOrigin(%q)(%q) = %v, want %v", tt.origins, tt.origin, got, tt.want)
+			}
+		})
+	}
+}